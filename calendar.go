@@ -0,0 +1,14 @@
+package git_nerds
+
+import (
+	analysis2 "github.com/inovacc/git-nerds/internal/analysis"
+)
+
+// Level returns the intensity of a day with the given number of commits,
+// from 0 for none to 4 for more than three quarters of the busiest day's.
+// Quartiles are rounded up: one commit is always level 1, and level 4
+// needs at least four commits on the busiest day.
+func (c *Calendar) Level(commits int) int {
+	data := analysis2.CalendarData{Quartiles: c.Quartiles}
+	return data.Level(commits)
+}
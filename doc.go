@@ -20,6 +20,17 @@
 //	fmt.Printf("Total commits: %d\n", stats.TotalCommits)
 //	fmt.Printf("Total authors: %d\n", stats.TotalAuthors)
 //
+// The path may also point inside a working tree, at a linked worktree or at
+// a bare repository. Operations that need a working tree return
+// ErrBareRepository on bare repositories.
+//
+// Remote repositories are cloned into a cache and fetched again on reuse:
+//
+//	repo, err := nerds.OpenURL("https://github.com/inovacc/git-nerds.git", nil)
+//
+// OpenURLContext bounds the network transfer with a context, and
+// Options.CloneFilter makes a partial clone.
+//
 // # Configuration
 //
 // Configure analysis with Options:
@@ -30,11 +41,38 @@
 //		Branch:        "main",
 //		PathSpec:      []string{":!vendor", ":!node_modules"},
 //		IgnoreAuthors: []string{"bot@.*"},
+//		IgnoreBots:    true,
 //		IncludeMerges: false,
 //	}
 //
 //	repo, err := nerds.Open("/path/to/repo", opts)
 //
+// By default the git CLI is used, falling back to a native go-git
+// implementation when no git binary is on the PATH. Set Options.Backend to
+// BackendExec or BackendGoGit to force one. Line counts from go-git may
+// differ slightly from git's, as its line diff differs.
+//
+// # Streaming Commits
+//
+// Iterate over commits without loading the whole history into memory:
+//
+//	for c, err := range repo.Commits(ctx) {
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		fmt.Println(c.Hash, c.Author, c.Additions, c.Deletions)
+//	}
+//
+// # Cancellation
+//
+// Bind a context to stop long-running git commands, e.g. when an HTTP
+// client disconnects:
+//
+//	stats, err := repo.WithContext(r.Context()).DetailedStats()
+//	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+//		return
+//	}
+//
 // # Author Analytics
 //
 // Get contributor information:
@@ -50,6 +88,58 @@
 //	// Suggest reviewers for a file
 //	reviewers, err := repo.SuggestReviewers("main.go")
 //
+// # File Analytics
+//
+// Find hot spots, the files changed most often:
+//
+//	files, err := repo.TopFiles(10)
+//	for _, f := range files {
+//		fmt.Printf("%s: %d changes by %d authors\n", f.Path, f.Changes, len(f.Authors))
+//	}
+//
+// Attribute the lines surviving today to their authors with git blame:
+//
+//	own, err := repo.Ownership("internal/")
+//	for _, o := range own.Owners {
+//		fmt.Printf("%s: %.1f%% of %d lines\n", o.Name, o.Percent, own.Lines)
+//	}
+//
+// Find the directories that depend on a single person:
+//
+//	bus, err := repo.BusFactor()
+//	for _, path := range bus.AtRisk {
+//		fmt.Println("at risk:", path)
+//	}
+//
+// # Submodules
+//
+// List the submodules with their pinned commit and how often it was bumped:
+//
+//	subs, err := repo.Submodules()
+//	for _, s := range subs {
+//		fmt.Printf("%s @ %s, bumped %d times\n", s.Path, s.Commit[:7], s.Bumps)
+//	}
+//
+// With Options.RecurseSubmodules, DetailedStats also opens every initialized
+// submodule and merges its authors, files and activity into the result.
+// Rows coming from a submodule carry its path in Author.Submodule and
+// File.Submodule. Both need a working tree: on a bare repository with
+// submodules they return ErrBareRepository.
+//
+// # Workspaces
+//
+// Analyze every repository below a directory as a whole:
+//
+//	ws, err := nerds.DiscoverWorkspace("/src/acme", opts)
+//	stats, err := ws.DetailedStats()
+//	for _, c := range stats.Contributors {
+//		fmt.Printf("%s: %d commits in %v\n", c.Name, c.Commits, c.Repositories)
+//	}
+//
+// Repositories are analyzed concurrently. Authors are unified across them
+// by email, and ExportJSON, ExportCSV and ExportMarkdown produce a single
+// report for the workspace.
+//
 // # Temporal Analysis
 //
 // Analyze commit patterns over time:
@@ -79,6 +169,16 @@
 //	}
 //
 //	tree, err := repo.BranchTree()
+//	fmt.Print(tree.Render())
+//
+// # Changelogs
+//
+// Build changelogs from tags and Conventional Commits subjects:
+//
+//	changelogs, err := repo.Changelogs()
+//	for _, c := range changelogs {
+//		fmt.Printf("%s (%d changes)\n", c.Version, len(c.Changes))
+//	}
 //
 // # Export
 //
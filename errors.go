@@ -1,14 +1,24 @@
 package git_nerds
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/inovacc/git-nerds/internal/analysis"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
 
 var (
-	// ErrInvalidPath is returned when the repository path is invalid
-	ErrInvalidPath = errors.New("invalid repository path")
+	// ErrInvalidPath is returned when the repository path is invalid, or
+	// when a path is missing from the analyzed tree
+	ErrInvalidPath = analysis.ErrInvalidPath
 
 	// ErrNotARepository is returned when the path is not a git repository
 	ErrNotARepository = errors.New("not a git repository")
 
+	// ErrBareRepository is returned by operations that need a working tree
+	// when the repository is bare
+	ErrBareRepository = errors.New("bare repository has no working tree")
+
 	// ErrInvalidOptions is returned when options are invalid
 	ErrInvalidOptions = errors.New("invalid options")
 
@@ -25,5 +35,5 @@ var (
 	ErrInvalidBranch = errors.New("invalid branch")
 
 	// ErrParseError is returned when parsing git output fails
-	ErrParseError = errors.New("failed to parse git output")
+	ErrParseError = parse.ErrParseError
 )
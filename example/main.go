@@ -22,7 +22,8 @@ func main() {
 	fmt.Println("\n=== Example 4: Export to JSON ===")
 	exportExample()
 
-	fmt.Println("=== GIT NERDS MODULE OUTPUT ===\n")
+	fmt.Println("=== GIT NERDS MODULE OUTPUT ===")
+	fmt.Println()
 
 	repo, err := gitnerds.Open("..")
 	if err != nil {
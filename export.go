@@ -66,15 +66,7 @@ func (r *Repository) ExportMarkdown() (string, error) {
 	md.WriteString("# Repository Statistics\n\n")
 	md.WriteString(fmt.Sprintf("**Generated:** %s\n\n", time.Now().Format("2006-01-02 15:04:05")))
 
-	// Overall stats
-	md.WriteString("## Overview\n\n")
-	md.WriteString(fmt.Sprintf("- **Total Commits:** %d\n", stats.TotalCommits))
-	md.WriteString(fmt.Sprintf("- **Total Authors:** %d\n", stats.TotalAuthors))
-	md.WriteString(fmt.Sprintf("- **Total Files:** %d\n", stats.TotalFiles))
-	md.WriteString(fmt.Sprintf("- **Lines Added:** %d\n", stats.LinesAdded))
-	md.WriteString(fmt.Sprintf("- **Lines Deleted:** %d\n", stats.LinesDeleted))
-	md.WriteString(fmt.Sprintf("- **Active Days:** %d\n", stats.ActiveDays))
-	md.WriteString("\n")
+	writeOverview(&md, stats)
 
 	// Author statistics
 	md.WriteString("## Contributors\n\n")
@@ -91,26 +83,151 @@ func (r *Repository) ExportMarkdown() (string, error) {
 	}
 	md.WriteString("\n")
 
-	// Top files
-	if len(stats.Files) > 0 {
-		md.WriteString("## Most Modified Files\n\n")
-		md.WriteString("| File | Changes | Additions | Deletions |\n")
-		md.WriteString("|------|---------|-----------|------------|\n")
-		limit := 10
-		if len(stats.Files) < limit {
-			limit = len(stats.Files)
-		}
-		for i := 0; i < limit; i++ {
-			file := stats.Files[i]
-			md.WriteString(fmt.Sprintf("| %s | %d | %d | %d |\n",
-				file.Path,
-				file.Changes,
-				file.Additions,
-				file.Deletions,
-			))
+	writeTopFiles(&md, stats.Files)
+
+	return md.String(), nil
+}
+
+// writeOverview writes the overall statistics section
+func writeOverview(md *strings.Builder, stats *Stats) {
+	md.WriteString("## Overview\n\n")
+	md.WriteString(fmt.Sprintf("- **Total Commits:** %d\n", stats.TotalCommits))
+	md.WriteString(fmt.Sprintf("- **Total Authors:** %d\n", stats.TotalAuthors))
+	md.WriteString(fmt.Sprintf("- **Total Files:** %d\n", stats.TotalFiles))
+	md.WriteString(fmt.Sprintf("- **Lines Added:** %d\n", stats.LinesAdded))
+	md.WriteString(fmt.Sprintf("- **Lines Deleted:** %d\n", stats.LinesDeleted))
+	md.WriteString(fmt.Sprintf("- **Active Days:** %d\n", stats.ActiveDays))
+	if stats.BinaryFiles > 0 {
+		md.WriteString(fmt.Sprintf("- **Binary Files:** %d (%d changes, %+d bytes)\n", stats.BinaryFiles, stats.BinaryChanges, stats.BinaryBytes))
+	}
+	if stats.LFSFiles > 0 {
+		md.WriteString(fmt.Sprintf("- **LFS Files:** %d (%d changes, %+d bytes)\n", stats.LFSFiles, stats.LFSChanges, stats.LFSBytes))
+	}
+	md.WriteString("\n")
+}
+
+// writeTopFiles writes the ten most modified files
+func writeTopFiles(md *strings.Builder, files []File) {
+	if len(files) == 0 {
+		return
+	}
+
+	md.WriteString("## Most Modified Files\n\n")
+	md.WriteString("| File | Changes | Additions | Deletions |\n")
+	md.WriteString("|------|---------|-----------|------------|\n")
+	limit := 10
+	if len(files) < limit {
+		limit = len(files)
+	}
+	for i := 0; i < limit; i++ {
+		file := files[i]
+		md.WriteString(fmt.Sprintf("| %s | %d | %d | %d |\n",
+			file.Path,
+			file.Changes,
+			file.Additions,
+			file.Deletions,
+		))
+	}
+	md.WriteString("\n")
+}
+
+// ExportJSON exports the workspace statistics, total, per repository and
+// per contributor, to JSON format
+func (w *Workspace) ExportJSON() (string, error) {
+	stats, err := w.DetailedStats()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// ExportCSV exports author statistics of every repository to CSV format,
+// one row per author and repository
+func (w *Workspace) ExportCSV() (string, error) {
+	stats, err := w.DetailedStats()
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	writer := csv.NewWriter(&buf)
+
+	writer.Write([]string{"Repository", "Author", "Email", "Commits", "Lines Added", "Lines Deleted", "Files Changed"})
+	for _, repo := range stats.Repositories {
+		for _, author := range repo.Stats.Authors {
+			writer.Write([]string{
+				repo.Name,
+				author.Name,
+				author.Email,
+				fmt.Sprintf("%d", author.Commits),
+				fmt.Sprintf("%d", author.LinesAdded),
+				fmt.Sprintf("%d", author.LinesDeleted),
+				fmt.Sprintf("%d", author.FilesChanged),
+			})
 		}
-		md.WriteString("\n")
 	}
 
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// ExportMarkdown exports the workspace statistics as one Markdown report
+func (w *Workspace) ExportMarkdown() (string, error) {
+	stats, err := w.DetailedStats()
+	if err != nil {
+		return "", err
+	}
+
+	var md strings.Builder
+
+	md.WriteString("# Workspace Statistics\n\n")
+	md.WriteString(fmt.Sprintf("**Generated:** %s\n\n", time.Now().Format("2006-01-02 15:04:05")))
+
+	writeOverview(&md, &stats.Total)
+
+	// Repository breakdown
+	md.WriteString("## Repositories\n\n")
+	md.WriteString("| Repository | Commits | Authors | Files | Lines Added | Lines Deleted | Last Commit |\n")
+	md.WriteString("|------------|---------|---------|-------|-------------|---------------|-------------|\n")
+	for _, repo := range stats.Repositories {
+		md.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %d | %s |\n",
+			repo.Name,
+			repo.Stats.TotalCommits,
+			repo.Stats.TotalAuthors,
+			repo.Stats.TotalFiles,
+			repo.Stats.LinesAdded,
+			repo.Stats.LinesDeleted,
+			repo.Stats.LastCommitAt.Format("2006-01-02"),
+		))
+	}
+	md.WriteString("\n")
+
+	// Contributors across repositories
+	md.WriteString("## Contributors\n\n")
+	md.WriteString("| Author | Commits | Lines Added | Lines Deleted | Repositories |\n")
+	md.WriteString("|--------|---------|-------------|---------------|--------------|\n")
+	for _, c := range stats.Contributors {
+		md.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %s |\n",
+			c.Name,
+			c.Commits,
+			c.LinesAdded,
+			c.LinesDeleted,
+			strings.Join(c.Repositories, ", "),
+		))
+	}
+	md.WriteString("\n")
+
+	writeTopFiles(&md, stats.Total.Files)
+
 	return md.String(), nil
 }
@@ -5,6 +5,7 @@ go 1.25
 require (
 	github.com/fatih/color v1.18.0
 	github.com/go-git/go-git/v5 v5.16.2
+	golang.org/x/sys v0.35.0
 )
 
 require (
@@ -27,6 +28,5 @@ require (
 	github.com/xanzy/ssh-agent v0.3.3 // indirect
 	golang.org/x/crypto v0.41.0 // indirect
 	golang.org/x/net v0.43.0 // indirect
-	golang.org/x/sys v0.35.0 // indirect
 	gopkg.in/warnings.v0 v0.1.2 // indirect
 )
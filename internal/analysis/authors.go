@@ -3,11 +3,9 @@ package analysis
 import (
 	"fmt"
 	"sort"
-	"strings"
 	"time"
 
 	"github.com/inovacc/git-nerds/internal/git"
-	"github.com/inovacc/git-nerds/internal/parse"
 )
 
 // AuthorAnalyzer provides author-related analytics
@@ -24,25 +22,17 @@ func NewAuthorAnalyzer(backend git.Backend, options *git.LogOptions) *AuthorAnal
 	}
 }
 
-// CommitsPerAuthor returns commit counts grouped by author
+// CommitsPerAuthor returns commit counts grouped by author name, using the
+// same unified identities as DetailedAuthorStats
 func (a *AuthorAnalyzer) CommitsPerAuthor() (map[string]int, error) {
-	// Use git shortlog for efficient counting
-	args := git.BuildLogArgs(a.options)
-	args = append([]string{"-s", "-n", "-e"}, args...) // summary, numbered, email
-
-	output, err := a.backend.Shortlog(args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get shortlog: %w", err)
-	}
-
-	stats, err := parse.ParseAuthorStats(output)
-	if err != nil {
-		return nil, err
+	authors := newAuthorAggregator(false).CreditCoAuthors(coAuthorSplit(a.options)).In(a.options.Location)
+	if err := NewEngine(a.backend, a.options).Run(authors); err != nil {
+		return nil, fmt.Errorf("failed to count commits per author: %w", err)
 	}
 
 	result := make(map[string]int)
-	for _, stat := range stats {
-		result[stat.Name] = stat.Count
+	for _, author := range authors.Results() {
+		result[author.Name] += author.Commits
 	}
 
 	return result, nil
@@ -61,98 +51,16 @@ type AuthorDetails struct {
 	ActiveDays   int
 }
 
-// DetailedAuthorStats returns comprehensive statistics for all authors
+// DetailedAuthorStats returns comprehensive statistics for all authors,
+// collected in a single history walk. With LogOptions.CoAuthors the
+// co-authors of a commit are credited along with its author.
 func (a *AuthorAnalyzer) DetailedAuthorStats() ([]AuthorDetails, error) {
-	// Get commits with detailed information
-	format := "%H|%an|%ae|%ad|%s"
-	args := git.BuildLogArgs(a.options)
-	args = append([]string{"--date=iso", "--numstat"}, args...)
-
-	output, err := a.backend.Log(append([]string{"--pretty=format:" + format}, args...)...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get log: %w", err)
-	}
-
-	// Parse commits
-	commits, err := parse.ParseCommitLog(output)
-	if err != nil {
+	authors := NewAuthorAggregator().CreditCoAuthors(coAuthorSplit(a.options)).In(a.options.Location)
+	if err := NewEngine(a.backend, a.options).Run(authors); err != nil {
 		return nil, err
 	}
 
-	// Aggregate by author
-	authorMap := make(map[string]*AuthorDetails)
-
-	for _, commit := range commits {
-		key := commit.Email
-		if _, exists := authorMap[key]; !exists {
-			authorMap[key] = &AuthorDetails{
-				Name:        commit.Author,
-				Email:       commit.Email,
-				FirstCommit: commit.Date,
-				LastCommit:  commit.Date,
-			}
-		}
-
-		author := authorMap[key]
-		author.Commits++
-		author.LinesAdded += commit.Additions
-		author.LinesDeleted += commit.Deletions
-		author.FilesChanged += len(commit.Files)
-
-		if commit.Date.Before(author.FirstCommit) {
-			author.FirstCommit = commit.Date
-		}
-		if commit.Date.After(author.LastCommit) {
-			author.LastCommit = commit.Date
-		}
-	}
-
-	// Calculate active days for each author
-	for email, author := range authorMap {
-		activeDays, err := a.calculateActiveDays(email)
-		if err == nil {
-			author.ActiveDays = activeDays
-		}
-	}
-
-	// Convert map to slice
-	result := make([]AuthorDetails, 0, len(authorMap))
-	for _, author := range authorMap {
-		result = append(result, *author)
-	}
-
-	// Sort by commits descending
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].Commits > result[j].Commits
-	})
-
-	return result, nil
-}
-
-// calculateActiveDays counts unique days an author has committed
-func (a *AuthorAnalyzer) calculateActiveDays(email string) (int, error) {
-	opts := *a.options
-	opts.Author = email
-	opts.Format = "%ad"
-	args := git.BuildLogArgs(&opts)
-	args = append([]string{"--date=short"}, args...)
-
-	output, err := a.backend.Log(args...)
-	if err != nil {
-		return 0, err
-	}
-
-	// Count unique dates
-	dates := make(map[string]bool)
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			dates[line] = true
-		}
-	}
-
-	return len(dates), nil
+	return authors.Results(), nil
 }
 
 // NewContributors returns contributors who joined after a given date
@@ -179,22 +87,15 @@ func (a *AuthorAnalyzer) NewContributors(since time.Time) ([]AuthorDetails, erro
 
 // SuggestReviewers suggests reviewers for a file based on commit history
 func (a *AuthorAnalyzer) SuggestReviewers(file string, limit int) ([]string, error) {
-	// Get commits that modified this file
-	opts := *a.options
-	opts.PathSpec = []string{file}
-	args := git.BuildLogArgs(&opts)
-
-	output, err := a.backend.Log(append([]string{"--pretty=format:%ae"}, args...)...)
+	// Get commits that modified this file, under any of its names
+	history, err := NewFileAnalyzer(a.backend, a.options).History(file)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get log for file: %w", err)
 	}
 
-	authors := parse.ParseAuthors(output)
-
-	// Count occurrences
 	counts := make(map[string]int)
-	for _, author := range authors {
-		counts[author]++
+	for _, revision := range history {
+		counts[revision.Email]++
 	}
 
 	// Sort by count
@@ -207,7 +108,10 @@ func (a *AuthorAnalyzer) SuggestReviewers(file string, limit int) ([]string, err
 		sorted = append(sorted, authorCount{email, count})
 	}
 	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].count > sorted[j].count
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+		return sorted[i].email < sorted[j].email
 	})
 
 	// Return top N
@@ -223,6 +127,17 @@ func (a *AuthorAnalyzer) SuggestReviewers(file string, limit int) ([]string, err
 	return result, nil
 }
 
+// Trailers counts the commits carrying trailers of key, such as
+// Reviewed-by, per identity or value, most frequent first
+func (a *AuthorAnalyzer) Trailers(key string) ([]TrailerCount, error) {
+	trailers := NewTrailerAggregator(key)
+	if err := NewEngine(a.backend, a.options).Run(trailers); err != nil {
+		return nil, fmt.Errorf("failed to count %s trailers: %w", key, err)
+	}
+
+	return trailers.Results(), nil
+}
+
 // TopContributors returns the top N contributors by commit count
 func (a *AuthorAnalyzer) TopContributors(limit int) ([]AuthorDetails, error) {
 	all, err := a.DetailedAuthorStats()
@@ -0,0 +1,225 @@
+package analysis
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+// blobBatch is the number of commits whose binary and LFS changes are
+// sized with a single object lookup
+const blobBatch = 256
+
+// lfsPointerMax is the largest size of a Git LFS pointer file
+const lfsPointerMax = 1024
+
+// lfsPointerVersion is the first line of every Git LFS pointer file
+const lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+
+// blobSizer fills in the sizes of binary changes, which numstat reports
+// without line counts, and of changes to Git LFS pointers, which numstat
+// counts as tiny text files. Pointers are looked for in the paths the
+// top-level .gitattributes assigns filter=lfs.
+type blobSizer struct {
+	backend git.Backend
+	lfs     []lfsPattern
+}
+
+// lfsPattern is a .gitattributes pattern setting or unsetting filter=lfs
+type lfsPattern struct {
+	pattern string
+	lfs     bool
+}
+
+// newBlobSizer creates a sizer reading the .gitattributes of the
+// repository behind backend
+func newBlobSizer(backend git.Backend) *blobSizer {
+	return &blobSizer{
+		backend: backend,
+		lfs:     parseLFSPatterns(rootFile(backend, ".gitattributes")),
+	}
+}
+
+// parseLFSPatterns returns the patterns of a .gitattributes file that set
+// or unset the filter attribute, in file order
+func parseLFSPatterns(data []byte) []lfsPattern {
+	var patterns []lfsPattern
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		for _, attr := range fields[1:] {
+			switch {
+			case attr == "filter=lfs":
+				patterns = append(patterns, lfsPattern{pattern: fields[0], lfs: true})
+			case strings.HasPrefix(attr, "filter=") || attr == "-filter" || attr == "!filter":
+				patterns = append(patterns, lfsPattern{pattern: fields[0]})
+			}
+		}
+	}
+
+	return patterns
+}
+
+// lfsPath reports whether the file at name is stored with Git LFS. Like
+// git, the last matching pattern wins, and patterns without a slash match
+// the file name at any depth.
+func (s *blobSizer) lfsPath(name string) bool {
+	lfs := false
+
+	for _, p := range s.lfs {
+		pattern, target := p.pattern, name
+		if strings.Contains(strings.TrimSuffix(pattern, "/"), "/") {
+			pattern = strings.TrimPrefix(pattern, "/")
+		} else {
+			target = path.Base(name)
+		}
+
+		if git.MatchPathSpec([]string{pattern}, target) {
+			lfs = p.lfs
+		}
+	}
+
+	return lfs
+}
+
+// sized reports whether the size of change is looked up
+func (s *blobSizer) sized(change parse.FileStats) bool {
+	return change.Binary || s.lfsPath(change.File)
+}
+
+// needed reports whether commit has changes to size
+func (s *blobSizer) needed(commit parse.CommitInfo) bool {
+	for _, change := range commit.Changes {
+		if s.sized(change) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve sizes the binary and LFS changes of commits in place. The line
+// counts of LFS pointers are dropped, as they say nothing about the file.
+func (s *blobSizer) resolve(commits []parse.CommitInfo) error {
+	var names []string
+	for _, commit := range commits {
+		for _, change := range commit.Changes {
+			if !s.sized(change) {
+				continue
+			}
+			for _, blob := range []string{change.OldBlob, change.NewBlob} {
+				if blob != "" {
+					names = append(names, blob)
+				}
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	objects, err := s.backend.Objects(names, lfsPointerMax)
+	if err != nil {
+		return fmt.Errorf("failed to read blobs: %w", err)
+	}
+
+	for i := range commits {
+		commit := &commits[i]
+
+		for j := range commit.Changes {
+			change := &commit.Changes[j]
+			if !s.sized(*change) {
+				continue
+			}
+
+			oldSize, oldLFS := blobSize(objects, change.OldBlob)
+			newSize, newLFS := blobSize(objects, change.NewBlob)
+
+			change.Size = newSize
+			change.SizeDelta = newSize - oldSize
+
+			if oldLFS || newLFS {
+				change.LFS = true
+				commit.Additions -= change.Additions
+				commit.Deletions -= change.Deletions
+				change.Additions, change.Deletions = 0, 0
+			}
+		}
+	}
+
+	return nil
+}
+
+// blobSize returns the size of a blob, or of the LFS object it points to
+func blobSize(objects map[string]git.Object, blob string) (int64, bool) {
+	object, ok := objects[blob]
+	if !ok {
+		return 0, false
+	}
+
+	if size, ok := parseLFSPointer(object.Content); ok {
+		return size, true
+	}
+
+	return object.Size, false
+}
+
+// parseLFSPointer returns the object size recorded by a Git LFS pointer
+// file, reporting false when content isn't one
+func parseLFSPointer(content []byte) (int64, bool) {
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(lines) < 3 || lines[0] != lfsPointerVersion {
+		return 0, false
+	}
+
+	oid := false
+	size := int64(-1)
+
+	for _, line := range lines[1:] {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			return 0, false
+		}
+
+		switch key {
+		case "oid":
+			oid = strings.HasPrefix(value, "sha256:")
+		case "size":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || n < 0 {
+				return 0, false
+			}
+			size = n
+		}
+	}
+
+	if !oid || size < 0 {
+		return 0, false
+	}
+
+	return size, true
+}
+
+// rootFile reads a file at the top of the repository, from the working
+// tree or from HEAD when the repository is bare
+func rootFile(backend git.Backend, name string) []byte {
+	data, err := os.ReadFile(filepath.Join(backend.RootPath(), name))
+	if err != nil {
+		output, _ := backend.Show("HEAD:" + name)
+		data = []byte(output)
+	}
+	return data
+}
@@ -0,0 +1,134 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+)
+
+// lfsPointer returns the content of a Git LFS pointer to an object of size
+// bytes
+func lfsPointer(oid string, size int) string {
+	return fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", strings.Repeat(oid, 64), size)
+}
+
+func TestBlobSizes(t *testing.T) {
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	repo.Write(".gitattributes", "*.psd filter=lfs diff=lfs merge=lfs -text\n")
+	repo.Write("logo.png", "\x89PNG\x00"+strings.Repeat("a", 95))
+	repo.Write("art.psd", lfsPointer("a", 5000))
+	repo.Write("main.go", "package main\n")
+	repo.Commit("Alice", "alice@example.com", "initial", day)
+
+	repo.Write("logo.png", "\x89PNG\x00"+strings.Repeat("b", 295))
+	repo.Write("art.psd", lfsPointer("b", 8000))
+	repo.Write("main.go", "package main\n\nfunc main() {}\n")
+	repo.Commit("Bob", "bob@example.com", "update assets", day.AddDate(0, 0, 1))
+
+	repo.Write("logo.png", "\x89PNG\x00"+strings.Repeat("c", 45))
+	repo.Commit("Bob", "bob@example.com", "shrink logo", day.AddDate(0, 0, 2))
+
+	gogit, err := git.NewGoGitBackend(repo.Dir)
+	if err != nil {
+		t.Fatalf("NewGoGitBackend() error = %v", err)
+	}
+
+	tests := map[string]struct {
+		backend git.Backend
+		options *git.LogOptions
+	}{
+		"exec":   {repo.backend(), &git.LogOptions{}},
+		"go-git": {gogit, &git.LogOptions{}},
+		"cached": {repo.backend(), &git.LogOptions{CacheDir: t.TempDir()}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			files, err := NewFileAnalyzer(tt.backend, tt.options).Files()
+			if err != nil {
+				t.Fatalf("Files() error = %v", err)
+			}
+
+			paths := make(map[string]FileDetails)
+			for _, f := range files {
+				paths[f.Path] = f
+			}
+
+			logo := paths["logo.png"]
+			if !logo.Binary || logo.LFS || logo.Size != 50 || logo.SizeDelta != 50 || logo.BinaryChanges != 3 {
+				t.Errorf("logo.png = %+v, want binary, 50 bytes after 3 binary changes", logo)
+			}
+
+			art := paths["art.psd"]
+			if !art.LFS || art.Size != 8000 || art.SizeDelta != 8000 || art.LFSChanges != 2 || art.Additions != 0 || art.Deletions != 0 {
+				t.Errorf("art.psd = %+v, want an 8000 byte LFS object after 2 changes and no lines", art)
+			}
+
+			source := paths["main.go"]
+			if source.Binary || source.LFS || source.Size != 0 || source.Additions != 3 {
+				t.Errorf("main.go = %+v, want a text file with 3 lines added", source)
+			}
+
+			// Pointer lines don't count towards the commit either
+			for commit, err := range Commits(tt.backend, tt.options) {
+				if err != nil {
+					t.Fatalf("Commits() error = %v", err)
+				}
+				if commit.Subject == "update assets" && (commit.Additions != 2 || commit.Deletions != 0) {
+					t.Errorf("update assets = +%d/-%d, want +2/-0", commit.Additions, commit.Deletions)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLFSPointer(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		size    int64
+		ok      bool
+	}{
+		{"pointer", lfsPointer("0", 1234), 1234, true},
+		{"pointer with extension", "version https://git-lfs.github.com/spec/v1\next-0-foo sha256:" + strings.Repeat("1", 64) +
+			"\noid sha256:" + strings.Repeat("2", 64) + "\nsize 7\n", 7, true},
+		{"text", "hello\nworld\n", 0, false},
+		{"missing size", "version https://git-lfs.github.com/spec/v1\noid sha256:abc\n", 0, false},
+		{"invalid size", "version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize big\n", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, ok := parseLFSPointer([]byte(tt.content))
+			if size != tt.size || ok != tt.ok {
+				t.Errorf("parseLFSPointer() = %d, %v, want %d, %v", size, ok, tt.size, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLFSPath(t *testing.T) {
+	sizer := &blobSizer{lfs: parseLFSPatterns([]byte(
+		"# assets\n*.psd filter=lfs diff=lfs merge=lfs -text\n/media/** filter=lfs\nmedia/keep.txt -filter\n*.go text\n",
+	))}
+
+	tests := map[string]bool{
+		"art.psd":        true,
+		"a/b/art.psd":    true,
+		"media/clip.mp4": true,
+		"media/keep.txt": false,
+		"main.go":        false,
+		"art.psd.txt":    false,
+	}
+
+	for name, want := range tests {
+		if got := sizer.lfsPath(name); got != want {
+			t.Errorf("lfsPath(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package analysis
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -34,6 +35,8 @@ type BranchInfo struct {
 	Age         time.Duration
 	IsActive    bool
 	IsCurrent   bool
+
+	tip string // full hash of the tip, for commit graph lookups
 }
 
 // ListBranches returns all branches with basic information
@@ -46,18 +49,51 @@ func (b *BranchAnalyzer) ListBranches() ([]string, error) {
 	return parse.ParseBranches(output)
 }
 
+// branchInfoFormat returns the for-each-ref format read by branchInfo:
+// refname, the tip's date of LogOptions.DateSource, objectname,
+// authorname, full objectname. Fields are separated by NULs, the only
+// byte neither refnames nor author names can contain.
+func (b *BranchAnalyzer) branchInfoFormat() string {
+	date := "authordate"
+	if b.options.DateSource == git.DateCommitter {
+		date = "committerdate"
+	}
+	return "--format=%(refname:short)%00%(" + date + ":iso)%00%(objectname:short)%00%(authorname)%00%(objectname)"
+}
+
 // DetailedBranchInfo returns detailed information for all branches
 func (b *BranchAnalyzer) DetailedBranchInfo() ([]BranchInfo, error) {
-	// Get all branches with their commit info
-	// Format: refname, committerdate, objectname, authorname
-	output, err := b.backend.ForEachRef(
-		"--format=%(refname:short)|%(committerdate:iso)|%(objectname:short)|%(authorname)",
-		"refs/heads/",
-	)
+	output, err := b.backend.ForEachRef(b.branchInfoFormat(), "refs/heads/")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get branch info: %w", err)
 	}
 
+	if strings.TrimSpace(output) == "" {
+		return []BranchInfo{}, nil
+	}
+
+	graph, err := b.commitGraph()
+	if err != nil {
+		return nil, err
+	}
+
+	return b.branchInfo(output, graph)
+}
+
+// commitGraph loads the commit graph of the repository, marking the
+// commits of ignored authors
+func (b *BranchAnalyzer) commitGraph() (*commitGraph, error) {
+	filter, err := newAuthorFilter(b.options)
+	if err != nil {
+		return nil, err
+	}
+
+	return loadCommitGraph(b.backend, filter)
+}
+
+// branchInfo parses the for-each-ref output of DetailedBranchInfo. Commit
+// counts come from one graph walk instead of rev-list per branch.
+func (b *BranchAnalyzer) branchInfo(output string, graph *commitGraph) ([]BranchInfo, error) {
 	lines := strings.Split(strings.TrimSpace(output), "\n")
 	branches := make([]BranchInfo, 0, len(lines))
 
@@ -68,9 +104,9 @@ func (b *BranchAnalyzer) DetailedBranchInfo() ([]BranchInfo, error) {
 			continue
 		}
 
-		parts := strings.Split(line, "|")
-		if len(parts) < 4 {
-			continue
+		parts := strings.Split(line, "\x00")
+		if len(parts) != 5 {
+			return nil, fmt.Errorf("%w: branch record %q", parse.ErrParseError, line)
 		}
 
 		name := parts[0]
@@ -81,11 +117,10 @@ func (b *BranchAnalyzer) DetailedBranchInfo() ([]BranchInfo, error) {
 		// Parse date
 		lastCommit, err := time.Parse("2006-01-02 15:04:05 -0700", dateStr)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("%w: branch %s: invalid date %q", parse.ErrParseError, name, dateStr)
 		}
 
-		// Get commit count for this branch
-		commitCount, _ := b.getCommitCount(name)
+		commitCount := graph.reachable(parts[4])
 
 		// Calculate age
 		age := time.Since(lastCommit)
@@ -102,24 +137,13 @@ func (b *BranchAnalyzer) DetailedBranchInfo() ([]BranchInfo, error) {
 			Age:         age,
 			IsActive:    isActive,
 			IsCurrent:   name == currentBranch,
+			tip:         parts[4],
 		})
 	}
 
 	return branches, nil
 }
 
-// getCommitCount returns the number of commits in a branch
-func (b *BranchAnalyzer) getCommitCount(branch string) (int, error) {
-	output, err := b.backend.RevList("--count", branch)
-	if err != nil {
-		return 0, err
-	}
-
-	var count int
-	fmt.Sscanf(strings.TrimSpace(output), "%d", &count)
-	return count, nil
-}
-
 // BranchesByDate returns branches sorted by last commit date
 func (b *BranchAnalyzer) BranchesByDate() ([]BranchInfo, error) {
 	branches, err := b.DetailedBranchInfo()
@@ -183,21 +207,190 @@ func (b *BranchAnalyzer) StaleBranches(days int) ([]BranchInfo, error) {
 	return stale, nil
 }
 
-// BranchTree generates a simple ASCII tree of branches
-func (b *BranchAnalyzer) BranchTree() (string, error) {
-	// Use git log --graph for visualization
-	output, err := b.backend.Log(
-		"--graph",
-		"--oneline",
-		"--all",
-		"--decorate",
-		"--simplify-by-decoration",
-	)
+// BranchNode is a branch in the branch graph
+type BranchNode struct {
+	Name       string
+	Hash       string
+	LastCommit time.Time
+	Parent     string   // branch this one forked from, empty for roots
+	Children   []string // branches forked from this one, by name
+	ForkPoint  string   // merge base with Parent
+	Commits    int      // commits reachable from the tip
+	Ahead      int      // commits not on Parent
+	Behind     int      // commits on Parent since ForkPoint
+}
+
+// BranchGraph is the topology of the local branches. Every branch hangs
+// off the branch it has the fewest commits of its own against, with the
+// root branch on top; branches sharing no history with any other are
+// roots of their own. When a branch forks from several others, as after
+// merging one into another, only the first branches to reach each of its
+// fork points are weighed.
+type BranchGraph struct {
+	Root     string
+	Branches []BranchNode // depth-first from the roots, children by name
+}
+
+// defaultBranches are the names tried for the root of a BranchGraph before
+// the current branch
+var defaultBranches = []string{"main", "master"}
+
+// BranchGraph builds the branch graph rooted at root, or when empty at
+// main, master, the current branch or the first branch, whichever exists.
+// Commit counts skip ignored authors, like DetailedBranchInfo.
+func (b *BranchAnalyzer) BranchGraph(root string) (*BranchGraph, error) {
+	result := &BranchGraph{}
+
+	output, err := b.backend.ForEachRef(b.branchInfoFormat(), "refs/heads/")
 	if err != nil {
-		return "", fmt.Errorf("failed to generate branch tree: %w", err)
+		return nil, fmt.Errorf("failed to get branch info: %w", err)
+	}
+
+	if strings.TrimSpace(output) == "" {
+		return result, nil
+	}
+
+	graph, err := b.commitGraph()
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := b.branchInfo(output, graph)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(branches) == 0 {
+		return result, nil
+	}
+
+	nodes := make(map[string]*BranchNode, len(branches))
+	for _, branch := range branches {
+		nodes[branch.Name] = &BranchNode{
+			Name:       branch.Name,
+			Hash:       branch.tip,
+			LastCommit: branch.LastCommit,
+			Commits:    branch.CommitCount,
+		}
+	}
+
+	result.Root = b.graphRoot(root, nodes)
+
+	// Place the branches closest to the root first, so every branch can
+	// only hang off one placed before it and the graph has no cycles.
+	// Each placed branch claims the commits no branch placed before it
+	// reaches, owner i+1 standing for placed[i].
+	rootNode := nodes[result.Root]
+	placed := []*BranchNode{rootNode}
+	graph.claim(rootNode.Hash, 1)
+
+	distance := make(map[string]int, len(nodes))
+	var pending []*BranchNode
+	for name, node := range nodes {
+		if node != rootNode {
+			distance[name], _ = graph.unclaimed(node.Hash)
+			pending = append(pending, node)
+		}
 	}
 
-	return output, nil
+	sort.Slice(pending, func(i, j int) bool {
+		if distance[pending[i].Name] != distance[pending[j].Name] {
+			return distance[pending[i].Name] < distance[pending[j].Name]
+		}
+		return pending[i].Name < pending[j].Name
+	})
+
+	for _, node := range pending {
+		ahead, boundary := graph.unclaimed(node.Hash)
+
+		var owners []int32
+		for _, id := range boundary {
+			if !slices.Contains(owners, graph.owners[id]) {
+				owners = append(owners, graph.owners[id])
+			}
+		}
+		slices.Sort(owners)
+
+		var parent *BranchNode
+		switch len(owners) {
+		case 0:
+			// Unrelated to every placed branch
+		case 1:
+			// The owner reaches every fork point, so only the unclaimed
+			// commits are not on it: no placed branch can do better, and
+			// none placed earlier reaches them all
+			parent = placed[owners[0]-1]
+		default:
+			// Fork points on several branches: pick among their owners,
+			// the first placed winning ties
+			for _, owner := range owners {
+				candidate := placed[owner-1]
+				graph.mark(candidate.Hash)
+
+				count, stops := graph.exclusive(node.Hash)
+				if parent == nil || count < ahead {
+					parent, boundary, ahead = candidate, stops, count
+				}
+			}
+		}
+
+		if parent != nil {
+			node.Parent = parent.Name
+			node.ForkPoint = graph.mergeBase(boundary)
+			node.Ahead = ahead
+
+			// Both tips share the commits node is not ahead by
+			node.Behind = parent.Commits - (node.Commits - ahead)
+
+			parent.Children = append(parent.Children, node.Name)
+		}
+
+		placed = append(placed, node)
+		graph.claim(node.Hash, int32(len(placed)))
+	}
+
+	// Lay the graph out depth-first from the root, then the other roots
+	var visit func(node *BranchNode)
+	visit = func(node *BranchNode) {
+		sort.Strings(node.Children)
+		result.Branches = append(result.Branches, *node)
+		for _, child := range node.Children {
+			visit(nodes[child])
+		}
+	}
+
+	visit(rootNode)
+
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Name < pending[j].Name })
+	for _, node := range pending {
+		if node.Parent == "" {
+			visit(node)
+		}
+	}
+
+	return result, nil
+}
+
+// graphRoot returns the name of the root branch among nodes
+func (b *BranchAnalyzer) graphRoot(root string, nodes map[string]*BranchNode) string {
+	candidates := append([]string{root}, defaultBranches...)
+	if current, err := b.backend.CurrentBranch(); err == nil {
+		candidates = append(candidates, current)
+	}
+
+	for _, name := range candidates {
+		if _, ok := nodes[name]; ok {
+			return name
+		}
+	}
+
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names[0]
 }
 
 // MergeStatistics represents merge commit statistics
@@ -210,44 +403,16 @@ type MergeStatistics struct {
 
 // GetMergeStatistics analyzes merge commits
 func (b *BranchAnalyzer) GetMergeStatistics() (*MergeStatistics, error) {
-	// Get merge commits only
 	opts := *b.options
+	opts.NoMerges = false
 	opts.MergesOnly = true
-	opts.Format = "%an|%ad"
-	args := git.BuildLogArgs(&opts)
-	args = append([]string{"--date=format:%Y-%m"}, args...)
 
-	output, err := b.backend.Log(args...)
-	if err != nil {
+	merges := NewMergeAggregator().In(b.options.Location)
+	if err := NewEngine(b.backend, &opts).Run(merges); err != nil {
 		return nil, fmt.Errorf("failed to get merge statistics: %w", err)
 	}
 
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-
-	stats := &MergeStatistics{
-		TotalMerges:    len(lines),
-		MergesByAuthor: make(map[string]int),
-		MergesByMonth:  make(map[string]int),
-	}
-
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-
-		parts := strings.Split(line, "|")
-		if len(parts) < 2 {
-			continue
-		}
-
-		author := parts[0]
-		month := parts[1]
-
-		stats.MergesByAuthor[author]++
-		stats.MergesByMonth[month]++
-	}
-
-	return stats, nil
+	return merges.Results(), nil
 }
 
 // CompareWithBranch compares current branch with another branch
@@ -257,16 +422,21 @@ func (b *BranchAnalyzer) CompareWithBranch(otherBranch string) (*BranchCompariso
 		return nil, err
 	}
 
-	// Commits in current but not in other
-	aheadOutput, err := b.backend.RevList("--count", fmt.Sprintf("%s..%s", otherBranch, currentBranch))
+	return b.CompareBranches(currentBranch, otherBranch)
+}
+
+// CompareBranches counts the commits unique to each of two branches
+func (b *BranchAnalyzer) CompareBranches(branch, otherBranch string) (*BranchComparison, error) {
+	// Commits in branch but not in other
+	aheadOutput, err := b.backend.RevList("--count", fmt.Sprintf("%s..%s", otherBranch, branch))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to compare branches: %w", err)
 	}
 
-	// Commits in other but not in current
-	behindOutput, err := b.backend.RevList("--count", fmt.Sprintf("%s..%s", currentBranch, otherBranch))
+	// Commits in other but not in branch
+	behindOutput, err := b.backend.RevList("--count", fmt.Sprintf("%s..%s", branch, otherBranch))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to compare branches: %w", err)
 	}
 
 	var ahead, behind int
@@ -274,17 +444,23 @@ func (b *BranchAnalyzer) CompareWithBranch(otherBranch string) (*BranchCompariso
 	fmt.Sscanf(strings.TrimSpace(behindOutput), "%d", &behind)
 
 	return &BranchComparison{
-		CurrentBranch: currentBranch,
-		OtherBranch:   otherBranch,
-		Ahead:         ahead,
-		Behind:        behind,
+		Branch:      branch,
+		OtherBranch: otherBranch,
+		Ahead:       ahead,
+		Behind:      behind,
 	}, nil
 }
 
 // BranchComparison represents comparison between two branches
 type BranchComparison struct {
-	CurrentBranch string
-	OtherBranch   string
-	Ahead         int // commits in current but not in other
-	Behind        int // commits in other but not in current
+	Branch      string
+	OtherBranch string
+	Ahead       int // commits in branch but not in other
+	Behind      int // commits in other but not in branch
+}
+
+// Resolve returns the hash of the commit branch points to, or an error
+// when branch names no commit
+func (b *BranchAnalyzer) Resolve(branch string) (string, error) {
+	return resolveCommit(b.backend, branch)
 }
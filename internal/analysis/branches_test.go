@@ -0,0 +1,191 @@
+package analysis
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+func TestBranchGraph(t *testing.T) {
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	repo.Commit("Alice", "alice@example.com", "c1", day)
+	repo.Commit("Alice", "alice@example.com", "c2", day)
+	fork := repo.Git(day, "rev-parse", "HEAD")
+
+	repo.Git(day, "checkout", "-q", "-b", "feature")
+	repo.Commit("Bob", "bob@example.com", "f1", day)
+	repo.Commit("Bob", "bob@example.com", "f2", day)
+	repo.Git(day, "checkout", "-q", "-b", "feature-fix")
+	repo.Commit("Bob", "bob@example.com", "x1", day)
+
+	repo.Git(day, "checkout", "-q", "main")
+	repo.Commit("Alice", "alice@example.com", "c3", day)
+	repo.Git(day, "checkout", "-q", "-b", "hotfix")
+	repo.Commit("Carol", "carol@example.com", "h1", day)
+
+	repo.Git(day, "checkout", "-q", "--orphan", "orphan")
+	repo.Commit("Dan", "dan@example.com", "o1", day)
+	repo.Git(day, "checkout", "-q", "feature")
+
+	gogit, err := git.NewGoGitBackend(repo.Dir)
+	if err != nil {
+		t.Fatalf("NewGoGitBackend() error = %v", err)
+	}
+
+	for name, backend := range map[string]git.Backend{"exec": repo.backend(), "go-git": gogit} {
+		t.Run(name, func(t *testing.T) {
+			graph, err := NewBranchAnalyzer(backend, &git.LogOptions{}).BranchGraph("")
+			if err != nil {
+				t.Fatalf("BranchGraph() error = %v", err)
+			}
+
+			// main is preferred over the checked out branch
+			if graph.Root != "main" {
+				t.Errorf("Root = %q, want main", graph.Root)
+			}
+
+			var order []string
+			nodes := make(map[string]BranchNode)
+			for _, node := range graph.Branches {
+				order = append(order, node.Name)
+				nodes[node.Name] = node
+			}
+
+			want := []string{"main", "feature", "feature-fix", "hotfix", "orphan"}
+			if !slices.Equal(order, want) {
+				t.Fatalf("branches = %v, want %v", order, want)
+			}
+
+			tests := []struct {
+				name, parent           string
+				commits, ahead, behind int
+			}{
+				{"main", "", 3, 0, 0},
+				{"feature", "main", 4, 2, 1},
+				{"feature-fix", "feature", 5, 1, 0},
+				{"hotfix", "main", 4, 1, 0},
+				{"orphan", "", 1, 0, 0},
+			}
+
+			for _, tt := range tests {
+				node := nodes[tt.name]
+				if node.Parent != tt.parent || node.Commits != tt.commits || node.Ahead != tt.ahead || node.Behind != tt.behind {
+					t.Errorf("%s = %+v, want parent %q, %d commits, +%d -%d", tt.name, node, tt.parent, tt.commits, tt.ahead, tt.behind)
+				}
+			}
+
+			if got := nodes["feature"].ForkPoint + "\n"; got != fork {
+				t.Errorf("feature fork point = %q, want %q", got, fork)
+			}
+
+			if children := nodes["main"].Children; !slices.Equal(children, []string{"feature", "hotfix"}) {
+				t.Errorf("main children = %v, want feature and hotfix", children)
+			}
+
+			// An explicit root takes precedence
+			graph, err = NewBranchAnalyzer(backend, &git.LogOptions{}).BranchGraph("feature")
+			if err != nil || graph.Root != "feature" || graph.Branches[0].Parent != "" {
+				t.Errorf("BranchGraph(feature) = %+v, %v, want feature at the root", graph, err)
+			}
+		})
+	}
+}
+
+func TestBranchGraphMergedBranches(t *testing.T) {
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	repo.Commit("Alice", "alice@example.com", "c1", day)
+
+	repo.Git(day, "checkout", "-q", "-b", "hotfix")
+	repo.Commit("Carol", "carol@example.com", "h1", day)
+
+	repo.Git(day, "checkout", "-q", "-b", "feature", "main")
+	repo.Commit("Bob", "bob@example.com", "f1", day)
+	repo.Commit("Bob", "bob@example.com", "f2", day)
+	fork := repo.Git(day, "rev-parse", "HEAD")
+
+	// combo forks from both feature and hotfix, and has fewer commits of
+	// its own against feature
+	repo.Git(day, "checkout", "-q", "-b", "combo")
+	repo.Git(day, "merge", "-q", "--no-ff", "-m", "merge hotfix", "hotfix")
+
+	graph, err := NewBranchAnalyzer(repo.backend(), &git.LogOptions{}).BranchGraph("main")
+	if err != nil {
+		t.Fatalf("BranchGraph() error = %v", err)
+	}
+
+	nodes := make(map[string]BranchNode)
+	for _, node := range graph.Branches {
+		nodes[node.Name] = node
+	}
+
+	combo := nodes["combo"]
+	if combo.Parent != "feature" || combo.Commits != 5 || combo.Ahead != 2 || combo.Behind != 0 {
+		t.Errorf("combo = %+v, want parent feature, 5 commits, +2 -0", combo)
+	}
+
+	if got := combo.ForkPoint + "\n"; got != fork {
+		t.Errorf("combo fork point = %q, want %q", got, fork)
+	}
+
+	if hotfix := nodes["hotfix"]; hotfix.Parent != "main" || hotfix.Ahead != 1 {
+		t.Errorf("hotfix = %+v, want parent main, +1", hotfix)
+	}
+}
+
+func TestDetailedBranchInfoPipes(t *testing.T) {
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	repo.Commit("Alice", "alice@example.com", "c1", day)
+	repo.Git(day, "checkout", "-q", "-b", "fix|pipe")
+	repo.Commit("Bob | Builder", "bob@example.com", "f1", day)
+
+	gogit, err := git.NewGoGitBackend(repo.Dir)
+	if err != nil {
+		t.Fatalf("NewGoGitBackend() error = %v", err)
+	}
+
+	for name, backend := range map[string]git.Backend{"exec": repo.backend(), "go-git": gogit} {
+		t.Run(name, func(t *testing.T) {
+			branches, err := NewBranchAnalyzer(backend, &git.LogOptions{}).DetailedBranchInfo()
+			if err != nil {
+				t.Fatalf("DetailedBranchInfo() error = %v", err)
+			}
+
+			found := false
+			for _, b := range branches {
+				if b.Name == "fix|pipe" {
+					found = b.Author == "Bob | Builder" && b.CommitCount == 2
+				}
+			}
+			if len(branches) != 2 || !found {
+				t.Errorf("DetailedBranchInfo() = %+v, want fix|pipe by Bob | Builder with 2 commits", branches)
+			}
+		})
+	}
+}
+
+func TestDetailedBranchInfoMalformed(t *testing.T) {
+	repo := newFixtureRepo(t)
+	repo.Commit("Alice", "alice@example.com", "c1", time.Time{})
+
+	for _, output := range []string{
+		"main\x002024-05-01 12:00:00 +0000\n",
+		"main\x00yesterday\x00abc1234\x00Alice\x00abc1234abc1234\n",
+	} {
+		backend := &tagListBackend{Backend: repo.backend(), output: output}
+
+		_, err := NewBranchAnalyzer(backend, &git.LogOptions{}).DetailedBranchInfo()
+		if !errors.Is(err, parse.ErrParseError) {
+			t.Errorf("DetailedBranchInfo() of %q error = %v, want ErrParseError", output, err)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package analysis
+
+import (
+	"math"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+const (
+	// knowledgeHalfLife is the age at which a change counts half as much
+	// towards an author's knowledge of a file
+	knowledgeHalfLife = 180 * 24 * time.Hour
+
+	// knowledgeShare is the recency-weighted share of a file's changes an
+	// author needs to count as knowing it. The top author always does.
+	knowledgeShare = 0.25
+)
+
+// BusFactorAnalyzer measures how concentrated knowledge of the code is
+type BusFactorAnalyzer struct {
+	backend git.Backend
+	options *git.LogOptions
+}
+
+// NewBusFactorAnalyzer creates a new bus factor analyzer
+func NewBusFactorAnalyzer(backend git.Backend, options *git.LogOptions) *BusFactorAnalyzer {
+	return &BusFactorAnalyzer{
+		backend: backend,
+		options: options,
+	}
+}
+
+// BusFactorDetails is the bus factor of a directory: the minimum number of
+// authors whose departure would orphan more than half of its files
+type BusFactorDetails struct {
+	Path       string
+	Factor     int
+	Files      int
+	KeyAuthors []string // emails, in the order they were removed
+}
+
+// BusFactorReport holds the bus factor of the repository and of every
+// directory, plus the paths whose knowledge rests on a single author
+type BusFactorReport struct {
+	Total       BusFactorDetails
+	Directories []BusFactorDetails
+	AtRisk      []string
+}
+
+// BusFactor computes the bus factor of the files in the analyzed tree that
+// were changed in the selected history
+func (b *BusFactorAnalyzer) BusFactor() (*BusFactorReport, error) {
+	files, err := listTree(b.backend, b.options, "")
+	if err != nil {
+		return nil, err
+	}
+
+	knowledge := newKnowledgeAggregator()
+	if err := NewEngine(b.backend, b.options).Run(knowledge); err != nil {
+		return nil, err
+	}
+
+	knowers := make(map[string][]string)
+	dirs := make(map[string][]string)
+	var all []string
+
+	for _, name := range files {
+		authors := knowledge.knowers(name)
+		if len(authors) == 0 {
+			continue
+		}
+
+		knowers[name] = authors
+		all = append(all, name)
+
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			dirs[dir] = append(dirs[dir], name)
+		}
+	}
+
+	report := &BusFactorReport{
+		Total:       busFactor(".", all, knowers),
+		Directories: make([]BusFactorDetails, 0, len(dirs)),
+		AtRisk:      []string{},
+	}
+
+	if report.Total.Factor == 1 {
+		report.AtRisk = append(report.AtRisk, report.Total.Path)
+	}
+
+	for dir, names := range dirs {
+		report.Directories = append(report.Directories, busFactor(dir, names, knowers))
+	}
+
+	sort.Slice(report.Directories, func(i, j int) bool {
+		return report.Directories[i].Path < report.Directories[j].Path
+	})
+
+	for _, d := range report.Directories {
+		if d.Factor == 1 {
+			report.AtRisk = append(report.AtRisk, d.Path)
+		}
+	}
+
+	return report, nil
+}
+
+// busFactor greedily removes the author who knows the most remaining files
+// until more than half of the files have no one left who knows them
+func busFactor(dir string, files []string, knowers map[string][]string) BusFactorDetails {
+	result := BusFactorDetails{Path: dir, Files: len(files), KeyAuthors: []string{}}
+	removed := make(map[string]bool)
+
+	orphaned := func(name string) bool {
+		for _, author := range knowers[name] {
+			if !removed[author] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for {
+		counts := make(map[string]int)
+		lost := 0
+
+		for _, name := range files {
+			if orphaned(name) {
+				lost++
+				continue
+			}
+			for _, author := range knowers[name] {
+				if !removed[author] {
+					counts[author]++
+				}
+			}
+		}
+
+		if lost*2 > len(files) || len(counts) == 0 {
+			break
+		}
+
+		best := ""
+		for author, n := range counts {
+			if best == "" || n > counts[best] || (n == counts[best] && author < best) {
+				best = author
+			}
+		}
+
+		removed[best] = true
+		result.KeyAuthors = append(result.KeyAuthors, best)
+	}
+
+	result.Factor = len(result.KeyAuthors)
+	return result
+}
+
+// knowledgeAggregator accumulates recency-weighted changes per file and
+// author. Weights are relative to the first commit seen, newest by default,
+// which keeps them in range; only their ratios matter. Renamed files are
+// followed like in FileAggregator, so knowledge of a file carries over to
+// its new name.
+type knowledgeAggregator struct {
+	files   map[string]map[string]float64 // path -> email -> weight
+	renamed map[string]string             // old path -> path the knowledge is kept under
+	ref     time.Time
+}
+
+func newKnowledgeAggregator() *knowledgeAggregator {
+	return &knowledgeAggregator{
+		files:   make(map[string]map[string]float64),
+		renamed: make(map[string]string),
+	}
+}
+
+// NeedsChanges implements Aggregator
+func (k *knowledgeAggregator) NeedsChanges() bool { return true }
+
+// Add implements Aggregator
+func (k *knowledgeAggregator) Add(commit *parse.CommitInfo) {
+	if k.ref.IsZero() {
+		k.ref = commit.Date
+	}
+
+	weight := math.Exp2(commit.Date.Sub(k.ref).Hours() / knowledgeHalfLife.Hours())
+
+	for _, change := range commit.Changes {
+		path := change.File
+		if current, ok := k.renamed[path]; ok {
+			path = current
+		}
+
+		authors, ok := k.files[path]
+		if !ok {
+			authors = make(map[string]float64)
+			k.files[path] = authors
+		}
+		authors[commit.Email] += weight
+
+		if change.OldFile != "" && !change.Copied && change.OldFile != path {
+			k.renamed[change.OldFile] = path
+		}
+	}
+}
+
+// knowers returns the authors who know a file, sorted by email
+func (k *knowledgeAggregator) knowers(name string) []string {
+	authors := k.files[name]
+
+	total, top := 0.0, 0.0
+	for _, w := range authors {
+		total += w
+		top = max(top, w)
+	}
+
+	var result []string
+	for author, w := range authors {
+		if w == top || w >= total*knowledgeShare {
+			result = append(result, author)
+		}
+	}
+
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,119 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+)
+
+// newBusFactorFixture builds a tree where Alice long ago wrote core/, Bob
+// owns docs/ and recently took over core/c.go, and both work on shared/
+func newBusFactorFixture(t *testing.T) *fixtureRepo {
+	t.Helper()
+
+	repo := newFixtureRepo(t)
+	old := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	recent := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	repo.Write("core/a.go", "package core\n")
+	repo.Write("core/b.go", "package core\n")
+	repo.Write("core/c.go", "package core\n")
+	repo.Commit("Alice", "alice@example.com", "core", old)
+
+	repo.Write("docs/x.md", "x\n")
+	repo.Write("docs/y.md", "y\n")
+	repo.Commit("Bob", "bob@example.com", "docs", recent)
+
+	repo.Write("shared/s.go", "package shared\n")
+	repo.Commit("Alice", "alice@example.com", "shared", recent.AddDate(0, 0, 1))
+
+	repo.Write("shared/s.go", "package shared\n\nvar s = 1\n")
+	repo.Write("core/c.go", "package core\n\nvar c = 1\n")
+	repo.Commit("Bob", "bob@example.com", "take over", recent.AddDate(0, 0, 2))
+
+	return repo
+}
+
+func TestBusFactor(t *testing.T) {
+	repo := newBusFactorFixture(t)
+
+	report, err := NewBusFactorAnalyzer(repo.backend(), &git.LogOptions{}).BusFactor()
+	if err != nil {
+		t.Fatalf("BusFactor() error = %v", err)
+	}
+
+	want := BusFactorDetails{Path: ".", Factor: 2, Files: 6, KeyAuthors: []string{"bob@example.com", "alice@example.com"}}
+	if !reflect.DeepEqual(report.Total, want) {
+		t.Errorf("Total = %+v, want %+v", report.Total, want)
+	}
+
+	factors := make(map[string]int)
+	for _, d := range report.Directories {
+		factors[d.Path] = d.Factor
+	}
+
+	if want := map[string]int{"core": 1, "docs": 1, "shared": 2}; !reflect.DeepEqual(factors, want) {
+		t.Errorf("directory factors = %v, want %v", factors, want)
+	}
+
+	if want := []string{"core", "docs"}; !reflect.DeepEqual(report.AtRisk, want) {
+		t.Errorf("AtRisk = %v, want %v", report.AtRisk, want)
+	}
+}
+
+func TestKnowledgeRecency(t *testing.T) {
+	repo := newBusFactorFixture(t)
+
+	knowledge := newKnowledgeAggregator()
+	if err := NewEngine(repo.backend(), &git.LogOptions{}).Run(knowledge); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		// Alice's change is two years older, worth a sixteenth of Bob's
+		{"core/c.go", []string{"bob@example.com"}},
+		// The only author knows a file however old the change
+		{"core/a.go", []string{"alice@example.com"}},
+		{"shared/s.go", []string{"alice@example.com", "bob@example.com"}},
+	}
+
+	for _, tt := range tests {
+		if got := knowledge.knowers(tt.file); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("knowers(%s) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestBusFactorRenames(t *testing.T) {
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	content := "package lib\n\nfunc A() {}\nfunc B() {}\nfunc C() {}\n"
+
+	repo.Write("lib/a.go", content)
+	repo.Commit("Alice", "alice@example.com", "lib", day)
+	repo.Write("lib/a.go", content+"func D() {}\n")
+	repo.Commit("Alice", "alice@example.com", "more lib", day.AddDate(0, 0, 1))
+	repo.Git(day, "mv", "lib/a.go", "lib/b.go")
+	repo.Commit("Carol", "carol@example.com", "move", day.AddDate(0, 0, 2))
+
+	report, err := NewBusFactorAnalyzer(repo.backend(), &git.LogOptions{}).BusFactor()
+	if err != nil {
+		t.Fatalf("BusFactor() error = %v", err)
+	}
+
+	// Alice's changes under the old name still count, so she knows the
+	// file along with whoever moved it
+	want := BusFactorDetails{Path: "lib", Factor: 2, Files: 1, KeyAuthors: []string{"alice@example.com", "carol@example.com"}}
+	if len(report.Directories) != 1 || !reflect.DeepEqual(report.Directories[0], want) {
+		t.Errorf("Directories = %+v, want %+v", report.Directories, want)
+	}
+
+	if len(report.AtRisk) != 0 {
+		t.Errorf("AtRisk = %v, want none", report.AtRisk)
+	}
+}
@@ -0,0 +1,197 @@
+package analysis
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/inovacc/git-nerds/internal/cache"
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+// cacheBatch is the number of commits parsed before they are written to
+// the commit cache
+const cacheBatch = 1000
+
+// cacheable reports whether the commits selected by options can be served
+// from the commit cache. Path specs change which commits git selects and
+// which changes it reports, and extra arguments may change anything.
+func cacheable(options *git.LogOptions) bool {
+	return options.CacheDir != "" && len(options.PathSpec) == 0 && len(options.ExtraArgs) == 0 &&
+		options.Author == "" && options.Format == ""
+}
+
+// cachedCommits passes the commits selected by options to fn, newest
+// first, from the commit cache in options.CacheDir, until fn returns false.
+// Only the commits that aren't reachable from a ref tip processed by an
+// earlier run are read from git, while other runs are kept from writing;
+// the selected commits are then read one by one. When the tip of the
+// analyzed ref was rewritten, or .mailmap changed, the cache starts over.
+func cachedCommits(backend git.Backend, options *git.LogOptions, fn func(parse.CommitInfo) bool) error {
+	store, err := cache.Open(options.CacheDir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	fingerprint := cacheFingerprint(backend, options)
+	if store.Fingerprint() != fingerprint {
+		if err := store.Reset(fingerprint); err != nil {
+			return err
+		}
+	}
+
+	ref := treeRev(options)
+
+	tip, err := resolveCommit(backend, ref)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+
+	if old := store.Tips()[ref]; old != "" && old != tip && !isAncestor(backend, old, tip) {
+		if err := store.Reset(fingerprint); err != nil {
+			return err
+		}
+	}
+
+	if store.Tips()[ref] != tip {
+		if err := fillCache(backend, store, options, ref, tip); err != nil {
+			return err
+		}
+	}
+
+	if err := store.Unlock(); err != nil {
+		return err
+	}
+
+	// Let git apply the date, merge and limit filters
+	selection := *options
+	selection.Branch = tip
+	stream, err := backend.LogStream(append([]string{"--pretty=format:%H"}, git.BuildLogArgs(&selection)...)...)
+	if err != nil {
+		return fmt.Errorf("failed to list commits: %w", err)
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		hash := strings.TrimSpace(scanner.Text())
+		if hash == "" {
+			continue
+		}
+
+		commit, ok, err := store.Get(hash)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("commit %s missing from the commit cache", hash)
+		}
+
+		if !fn(commit) {
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	return nil
+}
+
+// fillCache parses the commits reachable from tip that no processed tip
+// reaches, and records tip for ref
+func fillCache(backend git.Backend, store *cache.Store, options *git.LogOptions, ref, tip string) error {
+	args := append(parse.StreamArgs(true), git.BuildDiffArgs(options)...)
+	args = append(args, tip)
+
+	// Everything reachable from a processed tip is stored already
+	for _, known := range store.Tips() {
+		if _, err := resolveCommit(backend, known); err == nil {
+			args = append(args, "^"+known)
+		}
+	}
+
+	stream, err := backend.LogStream(args...)
+	if err != nil {
+		return fmt.Errorf("failed to get log: %w", err)
+	}
+	defer stream.Close()
+
+	sizer := newBlobSizer(backend)
+	batch := make([]parse.CommitInfo, 0, cacheBatch)
+
+	// add sizes the binary and LFS changes of the batch and stores it
+	add := func() error {
+		if err := sizer.resolve(batch); err != nil {
+			return err
+		}
+		if err := store.Add(batch); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	scanner := parse.NewCommitScanner(stream)
+	for scanner.Scan() {
+		batch = append(batch, scanner.Commit())
+
+		if len(batch) == cacheBatch {
+			if err := add(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read log: %w", err)
+	}
+
+	if err := add(); err != nil {
+		return err
+	}
+
+	return store.SetTip(ref, tip)
+}
+
+// resolveCommit returns the hash of the commit rev points to
+func resolveCommit(backend git.Backend, rev string) (string, error) {
+	output, err := backend.RevList("-n", "1", rev)
+	if err != nil {
+		return "", err
+	}
+
+	hash := strings.TrimSpace(output)
+	if hash == "" {
+		return "", fmt.Errorf("no commit at %s", rev)
+	}
+
+	return hash, nil
+}
+
+// isAncestor reports whether commit is reachable from tip. A commit that no
+// longer exists is not.
+func isAncestor(backend git.Backend, commit, tip string) bool {
+	output, err := backend.RevList("-n", "1", commit, "^"+tip)
+	return err == nil && strings.TrimSpace(output) == ""
+}
+
+// cacheFingerprint identifies what the stored commits depend on besides
+// history: the top-level .mailmap git applies to author identities, the
+// top-level .gitattributes telling which files are stored with Git LFS,
+// and the rename detection settings. Mailmaps named by the mailmap.file
+// and mailmap.blob settings aren't covered.
+func cacheFingerprint(backend git.Backend, options *git.LogOptions) string {
+	h := sha256.New()
+	h.Write(rootFile(backend, ".mailmap"))
+	h.Write([]byte{0})
+	h.Write(rootFile(backend, ".gitattributes"))
+	fmt.Fprintf(h, "\x00%v", git.BuildDiffArgs(options))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,171 @@
+package analysis
+
+import (
+	"io"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+// recordingBackend records the git log walks of the wrapped backend that
+// parse commits
+type recordingBackend struct {
+	git.Backend
+	walks [][]string
+}
+
+func (b *recordingBackend) LogStream(args ...string) (io.ReadCloser, error) {
+	if len(args) > 0 && args[0] == parse.StreamArgs(false)[0] {
+		b.walks = append(b.walks, args)
+	}
+	return b.Backend.LogStream(args...)
+}
+
+// collect drains Commits
+func collect(t *testing.T, backend git.Backend, options *git.LogOptions) []parse.CommitInfo {
+	t.Helper()
+
+	var commits []parse.CommitInfo
+	for commit, err := range Commits(backend, options) {
+		if err != nil {
+			t.Fatalf("Commits() error = %v", err)
+		}
+		commits = append(commits, commit)
+	}
+
+	return commits
+}
+
+func TestCommitCache(t *testing.T) {
+	repo := newEngineFixture(t)
+
+	gogit, err := git.NewGoGitBackend(repo.Dir)
+	if err != nil {
+		t.Fatalf("NewGoGitBackend() error = %v", err)
+	}
+
+	for name, backend := range map[string]git.Backend{"exec": repo.backend(), "go-git": gogit} {
+		t.Run(name, func(t *testing.T) {
+			want := collect(t, backend, &git.LogOptions{})
+
+			recorder := &recordingBackend{Backend: backend}
+			options := &git.LogOptions{CacheDir: t.TempDir()}
+
+			// The first run fills the cache, the second one reads it only
+			for run := 0; run < 2; run++ {
+				if got := collect(t, recorder, options); !reflect.DeepEqual(got, want) {
+					t.Errorf("run %d: cached commits = %+v, want %+v", run, got, want)
+				}
+			}
+
+			if len(recorder.walks) != 1 {
+				t.Errorf("git log ran %d times, want once", len(recorder.walks))
+			}
+
+			// Filters apply on top of the cache
+			filtered := &git.LogOptions{CacheDir: options.CacheDir, NoMerges: true, Limit: 2}
+			got := collect(t, recorder, filtered)
+			if wantFiltered := collect(t, backend, &git.LogOptions{NoMerges: true, Limit: 2}); !reflect.DeepEqual(got, wantFiltered) {
+				t.Errorf("filtered cached commits = %+v, want %+v", got, wantFiltered)
+			}
+		})
+	}
+}
+
+func TestCommitCacheIncremental(t *testing.T) {
+	repo := newEngineFixture(t)
+	day := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	recorder := &recordingBackend{Backend: repo.backend()}
+	options := &git.LogOptions{CacheDir: t.TempDir()}
+
+	collect(t, recorder, options)
+	oldTip := strings.TrimSpace(repo.Git(time.Time{}, "rev-parse", "HEAD"))
+
+	repo.Write("new.go", "package main\n")
+	repo.Commit("Carol", "carol@example.com", "new", day)
+
+	got := collect(t, recorder, options)
+	if want := collect(t, repo.backend(), &git.LogOptions{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("cached commits after a new commit = %+v, want %+v", got, want)
+	}
+
+	if walk := recorder.walks[len(recorder.walks)-1]; !slices.Contains(walk, "^"+oldTip) {
+		t.Errorf("second walk = %v, want it to stop at the previous tip", walk)
+	}
+
+	// Rewriting history starts over
+	repo.Git(day, "commit", "-q", "--amend", "-m", "rewritten")
+
+	got = collect(t, recorder, options)
+	if len(got) != 6 || got[0].Subject != "rewritten" {
+		t.Errorf("cached commits after a rewrite = %d, newest %q, want 6 and the rewritten commit", len(got), got[0].Subject)
+	}
+	if walk := recorder.walks[len(recorder.walks)-1]; slices.ContainsFunc(walk, func(arg string) bool { return strings.HasPrefix(arg, "^") }) {
+		t.Errorf("walk after a rewrite = %v, want a full walk", walk)
+	}
+
+	// So does a new .mailmap, the identities git reports change
+	repo.Write(".mailmap", "Carol Doe <carol@example.com>\n")
+
+	got = collect(t, recorder, options)
+	if got[0].Author != "Carol Doe" {
+		t.Errorf("author after a .mailmap change = %q, want Carol Doe", got[0].Author)
+	}
+}
+
+func TestCommitCacheConcurrent(t *testing.T) {
+	repo := newEngineFixture(t)
+	want := collect(t, repo.backend(), &git.LogOptions{})
+	options := &git.LogOptions{CacheDir: t.TempDir()}
+
+	// Runs sharing a cache take turns filling it
+	results := make([][]parse.CommitInfo, 4)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for commit, err := range Commits(repo.backend(), options) {
+				if err != nil {
+					t.Errorf("Commits() error = %v", err)
+					return
+				}
+				results[i] = append(results[i], commit)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, got := range results {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("run %d: cached commits = %+v, want %+v", i, got, want)
+		}
+	}
+
+	recorder := &recordingBackend{Backend: repo.backend()}
+	if got := collect(t, recorder, options); !reflect.DeepEqual(got, want) || len(recorder.walks) != 0 {
+		t.Errorf("cached commits afterwards = %+v after %d walks, want %+v from the cache alone", got, len(recorder.walks), want)
+	}
+}
+
+func TestCommitCacheBypass(t *testing.T) {
+	repo := newEngineFixture(t)
+	dir := filepath.Join(t.TempDir(), "cache")
+
+	options := &git.LogOptions{CacheDir: dir, PathSpec: []string{"README.md"}}
+	if cacheable(options) {
+		t.Errorf("cacheable(%+v) = true, want false with a path spec", options)
+	}
+
+	if got := collect(t, repo.backend(), options); len(got) != 2 {
+		t.Errorf("commits touching README.md = %d, want 2", len(got))
+	}
+}
@@ -0,0 +1,233 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+// UnreleasedVersion is the version name used for commits after the latest tag
+const UnreleasedVersion = "Unreleased"
+
+// ChangelogAnalyzer builds changelogs from tags and conventional commits
+type ChangelogAnalyzer struct {
+	backend git.Backend
+	options *git.LogOptions
+}
+
+// NewChangelogAnalyzer creates a new changelog analyzer
+func NewChangelogAnalyzer(backend git.Backend, options *git.LogOptions) *ChangelogAnalyzer {
+	return &ChangelogAnalyzer{
+		backend: backend,
+		options: options,
+	}
+}
+
+// ChangelogVersion represents the changes released under a single version
+type ChangelogVersion struct {
+	Version string
+	Date    time.Time
+	Changes []ChangeEntry
+}
+
+// ChangeEntry represents a single parsed commit in a changelog
+type ChangeEntry struct {
+	Type     string // feat, fix, docs, ...; empty for non-conventional subjects
+	Scope    string
+	Message  string
+	Breaking bool
+	Hash     string
+	Author   string
+	Email    string
+	Date     time.Time
+}
+
+// tagRef represents a tag with the date used to order releases
+type tagRef struct {
+	Name string
+	Date time.Time
+}
+
+// conventionalRe matches "type(scope)!: message"
+var conventionalRe = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?:\s*(.+)$`)
+
+// Changelogs returns the changelog grouped by version, newest first.
+// Commits after the latest tag are grouped under UnreleasedVersion.
+func (c *ChangelogAnalyzer) Changelogs() ([]ChangelogVersion, error) {
+	return c.changelogs("")
+}
+
+// ChangelogsByAuthor returns the changelog restricted to commits matching author
+func (c *ChangelogAnalyzer) ChangelogsByAuthor(author string) ([]ChangelogVersion, error) {
+	return c.changelogs(author)
+}
+
+func (c *ChangelogAnalyzer) changelogs(author string) ([]ChangelogVersion, error) {
+	tags, err := c.tags()
+	if err != nil {
+		return nil, err
+	}
+
+	// Versions newest first, each with the tag its commits lead up to and
+	// the previous tag they follow
+	versions := make([]ChangelogVersion, 0, len(tags)+1)
+	tips := make([]string, 0, len(tags)+1)
+	bases := make([]string, 0, len(tags)+1)
+
+	versions = append(versions, ChangelogVersion{Version: UnreleasedVersion})
+	tips = append(tips, c.options.Branch)
+
+	for i := len(tags) - 1; i >= 0; i-- {
+		versions = append(versions, ChangelogVersion{Version: tags[i].Name, Date: tags[i].Date})
+		tips = append(tips, tags[i].Name)
+		bases = append(bases, tags[i].Name)
+	}
+	bases = append(bases, "")
+
+	// Limit counts the commits of the whole changelog, so only the newest
+	// versions are filled once it is reached
+	limit := c.options.Limit
+	filled := 0
+
+	for i := range versions {
+		if c.options.Limit > 0 && limit == 0 {
+			break
+		}
+
+		changes, err := c.changes(tips[i], bases[i], author, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		versions[i].Changes = changes
+		limit -= len(changes)
+		filled++
+	}
+	versions = versions[:filled]
+
+	// Unreleased commits are dated by the newest of them
+	if len(versions) > 0 && versions[0].Version == UnreleasedVersion {
+		if len(versions[0].Changes) == 0 {
+			versions = versions[1:]
+		} else {
+			versions[0].Date = versions[0].Changes[0].Date
+		}
+	}
+
+	if author != "" {
+		filtered := versions[:0]
+		for _, v := range versions {
+			if len(v.Changes) > 0 {
+				filtered = append(filtered, v)
+			}
+		}
+		versions = filtered
+	}
+
+	return versions, nil
+}
+
+// tags lists tags ordered from oldest to newest
+func (c *ChangelogAnalyzer) tags() ([]tagRef, error) {
+	output, err := c.backend.ForEachRef(
+		"--sort=creatordate",
+		"--format=%(refname:short)|%(creatordate:iso)",
+		"refs/tags/",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	tags := make([]tagRef, 0, len(lines))
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		idx := strings.LastIndex(line, "|")
+		if idx < 0 {
+			return nil, fmt.Errorf("%w: tag record %q", parse.ErrParseError, line)
+		}
+
+		// Tags of trees and blobs have no date, nor commits to list
+		name, value := line[:idx], line[idx+1:]
+		if value == "" {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02 15:04:05 -0700", value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: tag %s: invalid date %q", parse.ErrParseError, name, value)
+		}
+
+		tags = append(tags, tagRef{Name: name, Date: date})
+	}
+
+	// Keep creation order stable for tags sharing a timestamp
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].Date.Before(tags[j].Date)
+	})
+
+	return tags, nil
+}
+
+// changes returns the parsed commits reachable from tip but not from base,
+// newest first, at most limit of them when positive. An empty tip stands
+// for HEAD, an empty base for the root.
+func (c *ChangelogAnalyzer) changes(tip, base, author string, limit int) ([]ChangeEntry, error) {
+	opts := *c.options
+	opts.Branch, opts.Exclude, opts.Limit = tip, base, limit
+	if author != "" {
+		opts.Author = author
+	}
+
+	entries := make([]ChangeEntry, 0)
+
+	for commit, err := range streamCommits(c.backend, &opts, false) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to get changelog commits for %s: %w", treeRev(&opts), err)
+		}
+
+		entry := ParseConventionalCommit(commit.Subject, commit.Body)
+		entry.Hash = commit.Hash
+		entry.Author = commit.Author
+		entry.Email = commit.Email
+		entry.Date = commit.Date
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// ParseConventionalCommit parses a commit subject and body following the
+// Conventional Commits specification. Subjects that don't follow it are
+// returned with an empty Type and the whole subject as Message.
+func ParseConventionalCommit(subject, body string) ChangeEntry {
+	subject = strings.TrimSpace(subject)
+	entry := ChangeEntry{Message: subject}
+
+	if m := conventionalRe.FindStringSubmatch(subject); m != nil {
+		entry.Type = strings.ToLower(m[1])
+		entry.Scope = strings.TrimSpace(m[2])
+		entry.Breaking = m[3] == "!"
+		entry.Message = strings.TrimSpace(m[4])
+	}
+
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			entry.Breaking = true
+			break
+		}
+	}
+
+	return entry
+}
@@ -0,0 +1,197 @@
+package analysis
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		body    string
+		want    ChangeEntry
+	}{
+		{
+			name:    "type only",
+			subject: "fix: handle empty output",
+			want:    ChangeEntry{Type: "fix", Message: "handle empty output"},
+		},
+		{
+			name:    "type and scope",
+			subject: "feat(parser): support tabs",
+			want:    ChangeEntry{Type: "feat", Scope: "parser", Message: "support tabs"},
+		},
+		{
+			name:    "breaking marker",
+			subject: "refactor(api)!: drop Foo",
+			want:    ChangeEntry{Type: "refactor", Scope: "api", Message: "drop Foo", Breaking: true},
+		},
+		{
+			name:    "breaking footer",
+			subject: "feat: new config",
+			body:    "Some details.\n\nBREAKING CHANGE: config moved",
+			want:    ChangeEntry{Type: "feat", Message: "new config", Breaking: true},
+		},
+		{
+			name:    "non conventional",
+			subject: "Update README",
+			want:    ChangeEntry{Message: "Update README"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseConventionalCommit(tt.subject, tt.body)
+			if got != tt.want {
+				t.Errorf("ParseConventionalCommit() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangelogs(t *testing.T) {
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	repo.Write("a.txt", "a")
+	repo.Commit("Alice", "alice@example.com", "feat: initial feature", day)
+	repo.Git(day, "tag", "v1.0.0")
+
+	repo.Write("b.txt", "b")
+	repo.Commit("Bob", "bob@example.com", "fix(core): off by one", day.AddDate(0, 0, 1))
+	repo.Write("c.txt", "c")
+	repo.Commit("Alice", "alice@example.com", "feat(api)!: rename endpoint", day.AddDate(0, 0, 2))
+	repo.Git(day.AddDate(0, 0, 2), "tag", "-a", "v2.0.0", "-m", "release 2")
+
+	repo.Write("d.txt", "d")
+	repo.Commit("Bob", "bob@example.com", "docs: explain setup", day.AddDate(0, 0, 3))
+
+	// A tag without a date, which is no version
+	repo.Git(day, "tag", "snapshot", "HEAD^{tree}")
+
+	analyzer := NewChangelogAnalyzer(repo.backend(), &git.LogOptions{NoMerges: true})
+
+	versions, err := analyzer.Changelogs()
+	if err != nil {
+		t.Fatalf("Changelogs() error = %v", err)
+	}
+
+	wantVersions := []string{UnreleasedVersion, "v2.0.0", "v1.0.0"}
+	if len(versions) != len(wantVersions) {
+		t.Fatalf("Changelogs() returned %d versions, want %d", len(versions), len(wantVersions))
+	}
+
+	for i, v := range versions {
+		if v.Version != wantVersions[i] {
+			t.Errorf("version %d = %s, want %s", i, v.Version, wantVersions[i])
+		}
+	}
+
+	v2 := versions[1]
+	if len(v2.Changes) != 2 {
+		t.Fatalf("v2.0.0 has %d changes, want 2", len(v2.Changes))
+	}
+
+	if !v2.Changes[0].Breaking || v2.Changes[0].Scope != "api" {
+		t.Errorf("v2.0.0 newest change = %+v, want breaking api change", v2.Changes[0])
+	}
+
+	if v2.Changes[1].Type != "fix" || v2.Changes[1].Scope != "core" {
+		t.Errorf("v2.0.0 oldest change = %+v, want fix(core)", v2.Changes[1])
+	}
+
+	byBob, err := analyzer.ChangelogsByAuthor("bob@example.com")
+	if err != nil {
+		t.Fatalf("ChangelogsByAuthor() error = %v", err)
+	}
+
+	if len(byBob) != 2 {
+		t.Fatalf("ChangelogsByAuthor() returned %d versions, want 2", len(byBob))
+	}
+
+	for _, v := range byBob {
+		for _, c := range v.Changes {
+			if c.Email != "bob@example.com" {
+				t.Errorf("ChangelogsByAuthor() included change by %s", c.Email)
+			}
+		}
+	}
+}
+
+func TestChangelogsIdentitiesAndLimit(t *testing.T) {
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	repo.Commit("Alice", "alice@example.com", "feat: initial feature", day)
+	repo.Git(day, "tag", "v1.0.0")
+
+	repo.Commit("Bob", "bob@old.example.com", "fix: off by one", day.AddDate(0, 0, 1))
+	repo.Commit("dependabot[bot]", "49699333+dependabot[bot]@users.noreply.github.com", "chore: bump", day.AddDate(0, 0, 2))
+	repo.Write(".mailmap", "Bob <bob@example.com> <bob@old.example.com>\n")
+	repo.Commit("Alice", "alice@example.com", "feat: second feature", day.AddDate(0, 0, 3))
+	repo.Git(day, "tag", "v2.0.0")
+
+	repo.Commit("Bob", "bob@example.com", "docs: explain setup", day.AddDate(0, 0, 4))
+
+	emails := func(versions []ChangelogVersion) map[string][]string {
+		result := make(map[string][]string)
+		for _, v := range versions {
+			result[v.Version] = []string{}
+			for _, c := range v.Changes {
+				result[v.Version] = append(result[v.Version], c.Email)
+			}
+		}
+		return result
+	}
+
+	// Commits are mailmapped and bots dropped like everywhere else
+	versions, err := NewChangelogAnalyzer(repo.backend(), &git.LogOptions{IgnoreBots: true}).Changelogs()
+	want := map[string][]string{
+		UnreleasedVersion: {"bob@example.com"},
+		"v2.0.0":          {"alice@example.com", "bob@example.com"},
+		"v1.0.0":          {"alice@example.com"},
+	}
+	if got := emails(versions); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Changelogs() = %v, %v, want %v", got, err, want)
+	}
+
+	// Limit keeps the newest commits of the whole changelog
+	versions, err = NewChangelogAnalyzer(repo.backend(), &git.LogOptions{IgnoreBots: true, Limit: 2}).Changelogs()
+	want = map[string][]string{
+		UnreleasedVersion: {"bob@example.com"},
+		"v2.0.0":          {"alice@example.com"},
+	}
+	if got := emails(versions); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Changelogs() with Limit 2 = %v, %v, want %v", got, err, want)
+	}
+}
+
+// tagListBackend lists fixed for-each-ref output, tags or branches alike
+type tagListBackend struct {
+	git.Backend
+	output string
+}
+
+func (b *tagListBackend) ForEachRef(...string) (string, error) {
+	return b.output, nil
+}
+
+func TestChangelogsInvalidTagDate(t *testing.T) {
+	repo := newFixtureRepo(t)
+	repo.Commit("Alice", "alice@example.com", "feat: initial feature", time.Time{})
+
+	for _, output := range []string{"v1.0.0|yesterday\n", "v1.0.0\n"} {
+		backend := &tagListBackend{Backend: repo.backend(), output: output}
+
+		_, err := NewChangelogAnalyzer(backend, &git.LogOptions{}).Changelogs()
+		if !errors.Is(err, parse.ErrParseError) {
+			t.Errorf("Changelogs() of tags %q error = %v, want ErrParseError", output, err)
+		}
+	}
+}
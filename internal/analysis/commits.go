@@ -0,0 +1,136 @@
+package analysis
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+// Commits streams the commits selected by options, with their per-file
+// changes, from a single git log invocation. Author identities are
+// mailmapped and unified according to the options, and commits by ignored
+// authors are skipped. Stopping the iteration
+// early terminates git.
+func Commits(backend git.Backend, options *git.LogOptions) iter.Seq2[parse.CommitInfo, error] {
+	return streamCommits(backend, options, true)
+}
+
+// streamCommits is Commits with optional per-file changes
+func streamCommits(backend git.Backend, options *git.LogOptions, numstat bool) iter.Seq2[parse.CommitInfo, error] {
+	return func(yield func(parse.CommitInfo, error) bool) {
+		filter, err := newAuthorFilter(options)
+		if err != nil {
+			yield(parse.CommitInfo{}, err)
+			return
+		}
+
+		identities, err := newIdentityResolver(backend, options)
+		if err != nil {
+			yield(parse.CommitInfo{}, err)
+			return
+		}
+
+		selection, window := newLogWindow(options, filter != nil)
+
+		// emit unifies the identities of a commit and yields it unless its
+		// author is ignored or it falls outside the window, returning false
+		// once iteration must stop. Co-authors who are ignored are
+		// dropped from the trailers.
+		emit := func(commit parse.CommitInfo) bool {
+			useDate(&commit, options.DateSource)
+			if window != nil && !window.admit(commit.Date) {
+				return true
+			}
+
+			if !identities.empty() {
+				commit.Email = identities.resolve(commit.Author, commit.Email)
+			}
+
+			if filter.ignored(commit.Author, commit.Email) {
+				return true
+			}
+
+			if len(commit.Trailers) > 0 {
+				commit.Trailers = resolveTrailers(commit.Trailers, identities, filter)
+			}
+
+			return yield(commit, nil) && (window == nil || !window.full())
+		}
+
+		if cacheable(selection) {
+			err := cachedCommits(backend, selection, func(commit parse.CommitInfo) bool {
+				// Cached commits always carry their changes
+				if !numstat {
+					commit.Additions, commit.Deletions = 0, 0
+					commit.Files, commit.Changes = nil, nil
+				}
+
+				return emit(commit)
+			})
+			if err != nil {
+				yield(parse.CommitInfo{}, fmt.Errorf("failed to read commit cache: %w", err))
+			}
+			return
+		}
+
+		args := parse.StreamArgs(numstat)
+		if numstat {
+			args = append(args, git.BuildDiffArgs(options)...)
+		}
+		args = append(args, git.BuildLogArgs(selection)...)
+
+		stream, err := backend.LogStream(args...)
+		if err != nil {
+			yield(parse.CommitInfo{}, fmt.Errorf("failed to get log: %w", err))
+			return
+		}
+		defer stream.Close()
+
+		// Commits with binary or LFS changes wait for their sizes, holding
+		// back the ones behind them to keep the order
+		sizer := newBlobSizer(backend)
+		pending := make([]parse.CommitInfo, 0, blobBatch)
+
+		flush := func() bool {
+			if err := sizer.resolve(pending); err != nil {
+				yield(parse.CommitInfo{}, err)
+				return false
+			}
+
+			for _, commit := range pending {
+				if !emit(commit) {
+					return false
+				}
+			}
+
+			pending = pending[:0]
+			return true
+		}
+
+		scanner := parse.NewCommitScanner(stream)
+		for scanner.Scan() {
+			commit := scanner.Commit()
+
+			if len(pending) == 0 && (!numstat || !sizer.needed(commit)) {
+				if !emit(commit) {
+					return
+				}
+				continue
+			}
+
+			pending = append(pending, commit)
+			if len(pending) == blobBatch && !flush() {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			yield(parse.CommitInfo{}, fmt.Errorf("failed to read log: %w", err))
+			return
+		}
+
+		flush()
+	}
+}
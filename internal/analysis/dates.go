@@ -0,0 +1,80 @@
+package analysis
+
+import (
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+// useDate sets the date commit is analyzed by according to source, one of
+// the git.Date sources
+func useDate(commit *parse.CommitInfo, source string) {
+	if source == git.DateCommitter {
+		commit.Date = commit.CommitDate
+	} else {
+		commit.Date = commit.AuthorDate
+	}
+}
+
+// defaultDateSkew is how far an author date may run ahead of its committer
+// date when LogOptions.MaxDateSkew is unset
+const defaultDateSkew = 24 * time.Hour
+
+// logWindow applies the parts of LogOptions git can't apply itself: Since
+// and Until to author dates, since git's --since and --until compare
+// committer dates, and Limit once the commits of ignored authors are
+// dropped, so Limit always counts the commits analyzed. Commits are
+// committed after they were authored, except on skewed clocks, so git
+// still bounds the walk with Since moved back by MaxDateSkew. Until can't
+// be handed to git at all: rebased and long-lived commits land any time
+// after they were written.
+type logWindow struct {
+	since time.Time
+	until time.Time
+	limit int
+	count int
+}
+
+// newLogWindow returns the options to select commits from git with and the
+// window to apply to them, nil when git's selection is exact. filtered
+// tells whether commits selected by git may still be dropped.
+func newLogWindow(options *git.LogOptions, filtered bool) (*git.LogOptions, *logWindow) {
+	dates := options.DateSource != git.DateCommitter && (!options.Since.IsZero() || !options.Until.IsZero())
+	if !dates && (!filtered || options.Limit <= 0) {
+		return options, nil
+	}
+
+	window := &logWindow{limit: options.Limit}
+	selection := *options
+	selection.Limit = 0
+
+	if dates {
+		window.since, window.until = options.Since, options.Until
+		selection.Until = time.Time{}
+
+		if !options.Since.IsZero() {
+			skew := options.MaxDateSkew
+			if skew <= 0 {
+				skew = defaultDateSkew
+			}
+			selection.Since = options.Since.Add(-skew)
+		}
+	}
+
+	return &selection, window
+}
+
+// admit reports whether a commit made at date is in the window
+func (w *logWindow) admit(date time.Time) bool {
+	if !w.since.IsZero() && date.Before(w.since) {
+		return false
+	}
+	return w.until.IsZero() || !date.After(w.until)
+}
+
+// full records a selected commit and reports whether Limit is reached
+func (w *logWindow) full() bool {
+	w.count++
+	return w.limit > 0 && w.count >= w.limit
+}
@@ -0,0 +1,48 @@
+package analysis
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+)
+
+func TestLogWindowBoundsAuthorDates(t *testing.T) {
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	repo.Commit("Alice", "alice@example.com", "old", day)
+	repo.Commit("Alice", "alice@example.com", "recent", day.AddDate(0, 0, 10))
+	// Authored on a clock running three hours ahead of the committer's
+	repo.Git(day.AddDate(0, 0, 20), "commit", "-q", "--allow-empty", "--author", "Bob <bob@example.com>",
+		"--date", day.AddDate(0, 0, 20).Add(3*time.Hour).Format(time.RFC3339), "-m", "skewed")
+
+	since := day.AddDate(0, 0, 20).Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		skew  time.Duration
+		bound time.Time
+	}{
+		{"default", 0, since.Add(-24 * time.Hour)},
+		{"custom", 2 * time.Hour, since.Add(-2 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &recordingBackend{Backend: repo.backend()}
+
+			commits := collect(t, backend, &git.LogOptions{Since: since, MaxDateSkew: tt.skew})
+			if len(commits) != 1 || commits[0].Subject != "skewed" {
+				t.Errorf("Commits() since %v = %+v, want the skewed commit", since, commits)
+			}
+
+			// git bounds the walk by committer date, moved back by the skew
+			want := git.BuildLogArgs(&git.LogOptions{Since: tt.bound})[0]
+			if len(backend.walks) != 1 || !slices.Contains(backend.walks[0], want) {
+				t.Errorf("walks = %v, want one bounded by %s", backend.walks, want)
+			}
+		})
+	}
+}
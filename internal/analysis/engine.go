@@ -0,0 +1,399 @@
+package analysis
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+// Aggregator consumes the commits of a shared history walk
+type Aggregator interface {
+	// Add records a single commit
+	Add(commit *parse.CommitInfo)
+
+	// NeedsChanges reports whether Add reads per-file changes, which
+	// makes the walk considerably more expensive
+	NeedsChanges() bool
+}
+
+// Engine walks the history once and feeds every commit to a set of
+// aggregators, so analyses don't each spawn their own git processes
+type Engine struct {
+	backend git.Backend
+	options *git.LogOptions
+}
+
+// NewEngine creates a new analysis engine
+func NewEngine(backend git.Backend, options *git.LogOptions) *Engine {
+	return &Engine{
+		backend: backend,
+		options: options,
+	}
+}
+
+// Run performs a single walk over the commits selected by the options
+func (e *Engine) Run(aggregators ...Aggregator) error {
+	numstat := false
+	for _, agg := range aggregators {
+		if agg.NeedsChanges() {
+			numstat = true
+			break
+		}
+	}
+
+	for commit, err := range streamCommits(e.backend, e.options, numstat) {
+		if err != nil {
+			return err
+		}
+
+		for _, agg := range aggregators {
+			agg.Add(&commit)
+		}
+	}
+
+	return nil
+}
+
+// dayKey formats the calendar day of t in its own time zone, matching
+// git's --date=short
+func dayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// inZone returns t in loc, or in the time zone the commit recorded when
+// loc is nil, as git.LogOptions.Location is for author-local time
+func inZone(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		return t
+	}
+	return t.In(loc)
+}
+
+// AuthorAggregator accumulates per-author statistics keyed by email. The
+// name reported for an author is the most recent one seen.
+type AuthorAggregator struct {
+	authors  map[string]*AuthorDetails
+	days     map[string]map[string]struct{}
+	changes  bool
+	split    string         // line split with co-authors, empty to credit authors only
+	location *time.Location // time zone of the active days, nil for author-local
+}
+
+// NewAuthorAggregator creates an empty author aggregator
+func NewAuthorAggregator() *AuthorAggregator {
+	return newAuthorAggregator(true)
+}
+
+// newAuthorAggregator creates an author aggregator that only counts commits
+// unless changes is set
+func newAuthorAggregator(changes bool) *AuthorAggregator {
+	return &AuthorAggregator{
+		authors: make(map[string]*AuthorDetails),
+		days:    make(map[string]map[string]struct{}),
+		changes: changes,
+	}
+}
+
+// CreditCoAuthors makes the aggregator credit the co-authors named by
+// Co-authored-by trailers with the commit as well, splitting its lines
+// according to split (git.SplitEven, git.SplitFull or git.SplitAuthor)
+func (a *AuthorAggregator) CreditCoAuthors(split string) *AuthorAggregator {
+	a.split = split
+	return a
+}
+
+// In makes the aggregator report dates and count active days in loc
+// instead of the time zone of each commit, when loc isn't nil
+func (a *AuthorAggregator) In(loc *time.Location) *AuthorAggregator {
+	a.location = loc
+	return a
+}
+
+// NeedsChanges implements Aggregator
+func (a *AuthorAggregator) NeedsChanges() bool { return a.changes }
+
+// Add implements Aggregator
+func (a *AuthorAggregator) Add(commit *parse.CommitInfo) {
+	for _, c := range credits(commit, a.split) {
+		a.credit(commit, c)
+	}
+}
+
+// credit records the share of commit attributed to one identity
+func (a *AuthorAggregator) credit(commit *parse.CommitInfo, c credit) {
+	key := c.email
+	date := inZone(commit.Date, a.location)
+
+	author, exists := a.authors[key]
+	if !exists {
+		author = &AuthorDetails{
+			Name:        c.name,
+			Email:       c.email,
+			FirstCommit: date,
+			LastCommit:  date,
+		}
+		a.authors[key] = author
+		a.days[key] = make(map[string]struct{})
+	}
+
+	author.Commits++
+	author.LinesAdded += c.additions
+	author.LinesDeleted += c.deletions
+	author.FilesChanged += c.files
+
+	if date.Before(author.FirstCommit) {
+		author.FirstCommit = date
+	}
+	if date.After(author.LastCommit) {
+		author.LastCommit = date
+	}
+
+	a.days[key][dayKey(date)] = struct{}{}
+}
+
+// Days returns the days each author committed on, keyed by email
+func (a *AuthorAggregator) Days() map[string]map[string]struct{} {
+	return a.days
+}
+
+// Results returns the authors sorted by commits descending
+func (a *AuthorAggregator) Results() []AuthorDetails {
+	result := make([]AuthorDetails, 0, len(a.authors))
+	for key, author := range a.authors {
+		author.ActiveDays = len(a.days[key])
+		result = append(result, *author)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Commits != result[j].Commits {
+			return result[i].Commits > result[j].Commits
+		}
+		return result[i].Email < result[j].Email
+	})
+
+	return result
+}
+
+// TemporalAggregator accumulates commit counts over time. Dates are
+// bucketed in the time zone recorded by each commit, like git's --date,
+// unless another one is set with In. ByTimezone always counts the UTC
+// offsets the commits were made at.
+type TemporalAggregator struct {
+	Commits     int
+	FirstCommit time.Time
+	LastCommit  time.Time
+	ByDay       map[string]int // YYYY-MM-DD
+	ByMonth     map[string]int // YYYY-MM
+	ByYear      map[string]int // YYYY
+	ByWeekday   map[string]int // Monday, Tuesday, ...
+	ByHour      map[int]int    // 0-23
+	ByTimezone  map[string]int // -0700
+
+	coAuthors bool
+	location  *time.Location
+}
+
+// NewTemporalAggregator creates an empty temporal aggregator
+func NewTemporalAggregator() *TemporalAggregator {
+	return &TemporalAggregator{
+		ByDay:      make(map[string]int),
+		ByMonth:    make(map[string]int),
+		ByYear:     make(map[string]int),
+		ByWeekday:  make(map[string]int),
+		ByHour:     make(map[int]int),
+		ByTimezone: make(map[string]int),
+	}
+}
+
+// CreditCoAuthors makes the breakdowns count every commit once for its
+// author and once for each co-author named by its Co-authored-by trailers
+func (t *TemporalAggregator) CreditCoAuthors() *TemporalAggregator {
+	t.coAuthors = true
+	return t
+}
+
+// In makes the aggregator bucket dates in loc instead of the time zone of
+// each commit, when loc isn't nil
+func (t *TemporalAggregator) In(loc *time.Location) *TemporalAggregator {
+	t.location = loc
+	return t
+}
+
+// NeedsChanges implements Aggregator
+func (t *TemporalAggregator) NeedsChanges() bool { return false }
+
+// Add implements Aggregator
+func (t *TemporalAggregator) Add(commit *parse.CommitInfo) {
+	date := inZone(commit.Date, t.location)
+
+	weight := 1
+	if t.coAuthors {
+		weight = len(credits(commit, git.SplitFull))
+	}
+
+	t.Commits++
+	if t.FirstCommit.IsZero() || date.Before(t.FirstCommit) {
+		t.FirstCommit = date
+	}
+	if date.After(t.LastCommit) {
+		t.LastCommit = date
+	}
+
+	t.ByDay[dayKey(date)] += weight
+	t.ByMonth[date.Format("2006-01")] += weight
+	t.ByYear[date.Format("2006")] += weight
+	t.ByWeekday[date.Weekday().String()] += weight
+	t.ByHour[date.Hour()] += weight
+	t.ByTimezone[commit.Date.Format("-0700")] += weight
+}
+
+// ActiveDays returns the number of distinct days with commits
+func (t *TemporalAggregator) ActiveDays() int {
+	return len(t.ByDay)
+}
+
+// FileDetails represents change statistics for a single path
+type FileDetails struct {
+	Path          string
+	PreviousPaths []string // paths the file was renamed from, most recent first
+	Changes       int      // commits touching the file
+	Additions     int
+	Deletions     int
+	Authors       []string // emails, most recent first
+	LastModified  time.Time
+
+	// Binary and Git LFS content, as of the most recent change: whether
+	// the file is binary or stored with LFS and its size in bytes, the
+	// changes to its binary or LFS content and the bytes they added
+	Binary        bool
+	LFS           bool
+	Size          int64
+	BinaryChanges int
+	LFSChanges    int
+	SizeDelta     int64
+}
+
+// FileAggregator accumulates per-file statistics. Renamed files are
+// followed, so their earlier history counts towards the current path.
+// Commits must be added newest first, as the walk yields them.
+type FileAggregator struct {
+	files   map[string]*FileDetails
+	renamed map[string]string // old path -> path the history is kept under
+}
+
+// NewFileAggregator creates an empty file aggregator
+func NewFileAggregator() *FileAggregator {
+	return &FileAggregator{
+		files:   make(map[string]*FileDetails),
+		renamed: make(map[string]string),
+	}
+}
+
+// NeedsChanges implements Aggregator
+func (f *FileAggregator) NeedsChanges() bool { return true }
+
+// Add implements Aggregator
+func (f *FileAggregator) Add(commit *parse.CommitInfo) {
+	for _, change := range commit.Changes {
+		path := change.File
+		if current, ok := f.renamed[path]; ok {
+			path = current
+		}
+
+		file, exists := f.files[path]
+		if !exists {
+			file = &FileDetails{Path: path, Binary: change.Binary, LFS: change.LFS, Size: change.Size}
+			f.files[path] = file
+		}
+
+		// Older commits touching the source belong to this file now,
+		// unless it was copied and lives on under its own name
+		if change.OldFile != "" && !change.Copied && change.OldFile != path {
+			f.renamed[change.OldFile] = path
+			if !slices.Contains(file.PreviousPaths, change.OldFile) {
+				file.PreviousPaths = append(file.PreviousPaths, change.OldFile)
+			}
+		}
+
+		file.Changes++
+		file.Additions += change.Additions
+		file.Deletions += change.Deletions
+		file.SizeDelta += change.SizeDelta
+
+		switch {
+		case change.LFS:
+			file.LFSChanges++
+		case change.Binary:
+			file.BinaryChanges++
+		}
+
+		if commit.Date.After(file.LastModified) {
+			file.LastModified = commit.Date
+		}
+
+		if !slices.Contains(file.Authors, commit.Email) {
+			file.Authors = append(file.Authors, commit.Email)
+		}
+	}
+}
+
+// Results returns the files sorted by number of changes descending
+func (f *FileAggregator) Results() []FileDetails {
+	result := make([]FileDetails, 0, len(f.files))
+	for _, file := range f.files {
+		result = append(result, *file)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Changes != result[j].Changes {
+			return result[i].Changes > result[j].Changes
+		}
+		return result[i].Path < result[j].Path
+	})
+
+	return result
+}
+
+// MergeAggregator accumulates statistics about the merge commits in the
+// walk. It only sees merges when the options include them.
+type MergeAggregator struct {
+	stats    MergeStatistics
+	location *time.Location
+}
+
+// NewMergeAggregator creates an empty merge aggregator
+func NewMergeAggregator() *MergeAggregator {
+	return &MergeAggregator{stats: MergeStatistics{
+		MergesByAuthor: make(map[string]int),
+		MergesByMonth:  make(map[string]int),
+	}}
+}
+
+// In makes the aggregator bucket months in loc instead of the time zone of
+// each commit, when loc isn't nil
+func (m *MergeAggregator) In(loc *time.Location) *MergeAggregator {
+	m.location = loc
+	return m
+}
+
+// NeedsChanges implements Aggregator
+func (m *MergeAggregator) NeedsChanges() bool { return false }
+
+// Add implements Aggregator
+func (m *MergeAggregator) Add(commit *parse.CommitInfo) {
+	if len(commit.Parents) < 2 {
+		return
+	}
+
+	m.stats.TotalMerges++
+	m.stats.MergesByAuthor[commit.Author]++
+	m.stats.MergesByMonth[inZone(commit.Date, m.location).Format("2006-01")]++
+}
+
+// Results returns the merge statistics
+func (m *MergeAggregator) Results() *MergeStatistics {
+	return &m.stats
+}
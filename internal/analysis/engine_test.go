@@ -0,0 +1,128 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+)
+
+// newEngineFixture builds a history with two authors, a feature branch and
+// a merge commit
+func newEngineFixture(t *testing.T) *fixtureRepo {
+	t.Helper()
+
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 3, 4, 9, 30, 0, 0, time.UTC) // a Monday
+
+	repo.Write("main.go", "package main\n")
+	repo.Commit("Alice", "alice@example.com", "initial", day)
+
+	repo.Write("main.go", "package main\n\nfunc main() {}\n")
+	repo.Write("README.md", "# demo\n")
+	repo.Commit("Bob", "bob@example.com", "add readme", day.Add(2*time.Hour))
+
+	repo.Git(day, "checkout", "-q", "-b", "feature")
+	repo.Write("feature.go", "package main\n\nvar x = 1\n")
+	repo.Commit("Alice", "alice@example.com", "feature", day.AddDate(0, 0, 1))
+
+	repo.Git(day, "checkout", "-q", "main")
+	repo.Write("README.md", "# demo\n\nmore\n")
+	repo.Commit("Alice", "alice@example.com", "docs", day.AddDate(0, 0, 2))
+
+	merge := day.AddDate(0, 0, 3)
+	repo.Git(merge, "merge", "-q", "--no-ff", "-m", "merge feature", "feature")
+
+	return repo
+}
+
+func TestEngineRun(t *testing.T) {
+	repo := newEngineFixture(t)
+
+	authors := NewAuthorAggregator()
+	temporal := NewTemporalAggregator()
+	files := NewFileAggregator()
+	merges := NewMergeAggregator()
+
+	engine := NewEngine(repo.backend(), &git.LogOptions{})
+	if err := engine.Run(authors, temporal, files, merges); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if temporal.Commits != 5 || temporal.ActiveDays() != 4 {
+		t.Errorf("Commits = %d, ActiveDays = %d, want 5 and 4", temporal.Commits, temporal.ActiveDays())
+	}
+
+	wantWeekdays := map[string]int{"Monday": 2, "Tuesday": 1, "Wednesday": 1, "Thursday": 1}
+	if !reflect.DeepEqual(temporal.ByWeekday, wantWeekdays) {
+		t.Errorf("ByWeekday = %v, want %v", temporal.ByWeekday, wantWeekdays)
+	}
+
+	// The merge is authored by the fixture identity
+	gotAuthors := authors.Results()
+	if len(gotAuthors) != 3 {
+		t.Fatalf("authors = %+v, want 3", gotAuthors)
+	}
+
+	alice := gotAuthors[0]
+	if alice.Email != "alice@example.com" || alice.Commits != 3 || alice.ActiveDays != 3 {
+		t.Errorf("alice = %+v, want 3 commits over 3 days", alice)
+	}
+
+	if alice.LinesAdded != 1+3+2 || alice.FilesChanged != 3 {
+		t.Errorf("alice lines/files = +%d/%d, want +6/3", alice.LinesAdded, alice.FilesChanged)
+	}
+
+	gotFiles := files.Results()
+	if len(gotFiles) != 3 {
+		t.Fatalf("files = %+v, want 3", gotFiles)
+	}
+
+	readme := gotFiles[0]
+	if readme.Path != "README.md" || readme.Changes != 2 || readme.Additions != 3 || readme.Deletions != 0 {
+		t.Errorf("README.md = %+v, want 2 changes, +3/-0", readme)
+	}
+
+	if want := []string{"alice@example.com", "bob@example.com"}; !reflect.DeepEqual(readme.Authors, want) {
+		t.Errorf("README.md authors = %v, want %v", readme.Authors, want)
+	}
+
+	if merges.Results().TotalMerges != 1 {
+		t.Errorf("TotalMerges = %d, want 1", merges.Results().TotalMerges)
+	}
+}
+
+func TestEngineRunNoMerges(t *testing.T) {
+	repo := newEngineFixture(t)
+
+	temporal := NewTemporalAggregator()
+	merges := NewMergeAggregator()
+
+	if err := NewEngine(repo.backend(), &git.LogOptions{NoMerges: true}).Run(temporal, merges); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if temporal.Commits != 4 || merges.Results().TotalMerges != 0 {
+		t.Errorf("Commits = %d, merges = %d, want 4 and 0", temporal.Commits, merges.Results().TotalMerges)
+	}
+}
+
+func TestDetailedBranchInfoCommitCounts(t *testing.T) {
+	repo := newEngineFixture(t)
+
+	branches, err := NewBranchAnalyzer(repo.backend(), &git.LogOptions{}).DetailedBranchInfo()
+	if err != nil {
+		t.Fatalf("DetailedBranchInfo() error = %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, b := range branches {
+		counts[b.Name] = b.CommitCount
+	}
+
+	want := map[string]int{"main": 5, "feature": 3}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("commit counts = %v, want %v", counts, want)
+	}
+}
@@ -0,0 +1,214 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+// FileRevision is a commit that changed a file, with the path the file had
+type FileRevision struct {
+	Hash      string
+	Author    string
+	Email     string
+	Date      time.Time
+	Subject   string
+	Path      string // path of the file after the commit
+	OldPath   string // path before the commit when it renamed or copied the file
+	Additions int
+	Deletions int
+	Binary    bool
+}
+
+// FileAnalyzer provides per-file analytics
+type FileAnalyzer struct {
+	backend git.Backend
+	options *git.LogOptions
+}
+
+// NewFileAnalyzer creates a new file analyzer
+func NewFileAnalyzer(backend git.Backend, options *git.LogOptions) *FileAnalyzer {
+	return &FileAnalyzer{
+		backend: backend,
+		options: options,
+	}
+}
+
+// Files returns statistics for every file changed in the selected history,
+// most frequently changed first
+func (f *FileAnalyzer) Files() ([]FileDetails, error) {
+	files := NewFileAggregator()
+	if err := NewEngine(f.backend, f.options).Run(files); err != nil {
+		return nil, fmt.Errorf("failed to get file stats: %w", err)
+	}
+
+	return files.Results(), nil
+}
+
+// TopFiles returns the N most frequently changed files
+func (f *FileAnalyzer) TopFiles(limit int) ([]FileDetails, error) {
+	all, err := f.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	if limit < 0 {
+		limit = 0
+	}
+	if limit > len(all) {
+		limit = len(all)
+	}
+
+	return all[:limit], nil
+}
+
+// History returns the commits that changed path, newest first, following
+// the file across renames and copies like git log --follow. The log is
+// limited to the name the file has at the time; where that name appears or
+// disappears, the commit is diffed in full to tell a rename or copy from an
+// addition or deletion, and a rename resumes the log from its parents under
+// the old name. The path spec of the options is ignored and their limit
+// caps the revisions. The log reads every commit up to Since, so renames
+// are found whoever made them; Until and the merge and author filters only
+// select the revisions returned.
+func (f *FileAnalyzer) History(path string) ([]FileRevision, error) {
+	opts := *f.options
+	opts.Limit, opts.Until = 0, time.Time{}
+	opts.NoMerges, opts.MergesOnly = false, false
+	opts.Author, opts.IgnoreAuthors, opts.IgnoreBots = "", nil, false
+
+	selects, err := f.revisionFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]FileRevision, 0)
+	current := path
+
+	for {
+		opts.PathSpec = []string{current}
+		var parents []string
+
+		for commit, err := range streamCommits(f.backend, &opts, true) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to get file history: %w", err)
+			}
+
+			change, ok := changeTo(commit.Changes, current)
+			if !ok {
+				continue
+			}
+
+			// Limited to one path, git shows a rename as an addition
+			// here and a deletion under the old name
+			if change.OldFile == "" && (change.OldBlob == "" || change.NewBlob == "") {
+				change, ok, err = f.fullChange(commit.Hash, current)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue // renamed away, the newer name was followed already
+				}
+			}
+
+			if selects(&commit) {
+				revisions = append(revisions, FileRevision{
+					Hash:      commit.Hash,
+					Author:    commit.Author,
+					Email:     commit.Email,
+					Date:      commit.Date,
+					Subject:   commit.Subject,
+					Path:      change.File,
+					OldPath:   change.OldFile,
+					Additions: change.Additions,
+					Deletions: change.Deletions,
+					Binary:    change.Binary,
+				})
+
+				if f.options.Limit > 0 && len(revisions) == f.options.Limit {
+					return revisions, nil
+				}
+			}
+
+			if change.OldFile != "" {
+				current, parents = change.OldFile, commit.Parents
+				break
+			}
+		}
+
+		if len(parents) == 0 {
+			return revisions, nil
+		}
+
+		opts.Branch = parents[0]
+		opts.ExtraArgs = append(f.options.ExtraArgs[:len(f.options.ExtraArgs):len(f.options.ExtraArgs)], parents[1:]...)
+	}
+}
+
+// revisionFilter returns whether History returns a commit, applying the
+// Until, merge and author filters its log leaves out. Like the go-git
+// backend, Author matches the commit's "name <email>".
+func (f *FileAnalyzer) revisionFilter() (func(*parse.CommitInfo) bool, error) {
+	ignored, err := newAuthorFilter(f.options)
+	if err != nil {
+		return nil, err
+	}
+
+	var author *regexp.Regexp
+	if f.options.Author != "" {
+		if author, err = regexp.Compile(f.options.Author); err != nil {
+			return nil, fmt.Errorf("invalid author pattern %q: %w", f.options.Author, err)
+		}
+	}
+
+	until := f.options.Until
+
+	return func(commit *parse.CommitInfo) bool {
+		merge := len(commit.Parents) > 1
+		switch {
+		case f.options.NoMerges && merge, f.options.MergesOnly && !merge:
+			return false
+		case !until.IsZero() && commit.Date.After(until):
+			return false
+		case author != nil && !author.MatchString(commit.Author+" <"+commit.Email+">"):
+			return false
+		}
+		return !ignored.ignored(commit.Author, commit.Email)
+	}, nil
+}
+
+// fullChange returns the change the commit at hash made to path, with
+// renames and copies detected against every other path. It reports false
+// when path was renamed away.
+func (f *FileAnalyzer) fullChange(hash, path string) (parse.FileStats, bool, error) {
+	opts := *f.options
+	opts.Branch, opts.Exclude, opts.ExtraArgs, opts.PathSpec = hash, "", nil, nil
+	opts.Since, opts.Until, opts.Limit = time.Time{}, time.Time{}, 1
+	opts.NoMerges, opts.MergesOnly = false, false
+	opts.Author, opts.IgnoreAuthors, opts.IgnoreBots = "", nil, false
+	opts.CacheDir = ""
+
+	for commit, err := range streamCommits(f.backend, &opts, true) {
+		if err != nil {
+			return parse.FileStats{}, false, fmt.Errorf("failed to get file history: %w", err)
+		}
+
+		change, ok := changeTo(commit.Changes, path)
+		return change, ok, nil
+	}
+
+	return parse.FileStats{}, false, nil
+}
+
+// changeTo returns the change to path among changes
+func changeTo(changes []parse.FileStats, path string) (parse.FileStats, bool) {
+	for _, change := range changes {
+		if change.File == path {
+			return change, true
+		}
+	}
+	return parse.FileStats{}, false
+}
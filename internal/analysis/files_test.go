@@ -0,0 +1,233 @@
+package analysis
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+)
+
+func TestFileAnalyzer(t *testing.T) {
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	repo.Write("hot.go", "a\n")
+	repo.Write("cold.go", "x\n")
+	repo.Commit("Alice", "alice@example.com", "initial", day)
+
+	repo.Write("hot.go", "a\nb\n")
+	repo.Commit("Bob", "bob@example.com", "grow", day.AddDate(0, 0, 1))
+
+	repo.Write("hot.go", "b\n")
+	repo.Git(day, "mv", "cold.go", "moved.go")
+	repo.Commit("Alice", "alice@example.com", "shrink", day.AddDate(0, 0, 2))
+
+	analyzer := NewFileAnalyzer(repo.backend(), &git.LogOptions{})
+
+	files, err := analyzer.Files()
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+
+	paths := make(map[string]FileDetails)
+	for _, f := range files {
+		paths[f.Path] = f
+	}
+
+	hot := paths["hot.go"]
+	if hot.Changes != 3 || hot.Additions != 2 || hot.Deletions != 1 {
+		t.Errorf("hot.go = %+v, want 3 changes, +2/-1", hot)
+	}
+
+	if len(hot.Authors) != 2 || hot.Authors[0] != "alice@example.com" {
+		t.Errorf("hot.go authors = %v, want alice (most recent) then bob", hot.Authors)
+	}
+
+	if !hot.LastModified.Equal(day.AddDate(0, 0, 2)) {
+		t.Errorf("hot.go LastModified = %v, want %v", hot.LastModified, day.AddDate(0, 0, 2))
+	}
+
+	// Renames are reported under the new path, with the history of the old one
+	moved, ok := paths["moved.go"]
+	if !ok || moved.Changes != 2 || !reflect.DeepEqual(moved.PreviousPaths, []string{"cold.go"}) {
+		t.Errorf("moved.go = %+v, want 2 changes renamed from cold.go", moved)
+	}
+
+	if _, ok := paths["cold.go"]; ok {
+		t.Errorf("Files() = %+v, want cold.go merged into moved.go", files)
+	}
+
+	top, err := analyzer.TopFiles(1)
+	if err != nil {
+		t.Fatalf("TopFiles() error = %v", err)
+	}
+
+	if len(top) != 1 || top[0].Path != "hot.go" {
+		t.Errorf("TopFiles(1) = %+v, want hot.go", top)
+	}
+
+	if all, _ := analyzer.TopFiles(100); len(all) != len(files) {
+		t.Errorf("TopFiles(100) returned %d files, want %d", len(all), len(files))
+	}
+}
+
+func TestFileHistory(t *testing.T) {
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	content := "package util\n\nfunc A() {}\nfunc B() {}\nfunc C() {}\n"
+
+	repo.Write("util.go", content)
+	repo.Write("other.go", "package other\n")
+	repo.Commit("Alice", "alice@example.com", "initial", day)
+
+	repo.Git(day, "mv", "util.go", "lib.go")
+	repo.Write("lib.go", content+"func D() {}\n")
+	repo.Commit("Bob", "bob@example.com", "move", day.AddDate(0, 0, 1))
+
+	repo.Write("other.go", "package other\n\nvar x = 1\n")
+	repo.Commit("Bob", "bob@example.com", "unrelated", day.AddDate(0, 0, 2))
+
+	repo.Write("lib.go", content+"func D() {}\nfunc E() {}\n")
+	repo.Commit("Carol", "carol@example.com", "grow", day.AddDate(0, 0, 3))
+
+	gogit, err := git.NewGoGitBackend(repo.Dir)
+	if err != nil {
+		t.Fatalf("NewGoGitBackend() error = %v", err)
+	}
+
+	for name, backend := range map[string]git.Backend{"exec": repo.backend(), "go-git": gogit} {
+		t.Run(name, func(t *testing.T) {
+			history, err := NewFileAnalyzer(backend, &git.LogOptions{}).History("lib.go")
+			if err != nil {
+				t.Fatalf("History() error = %v", err)
+			}
+
+			var got []string
+			for _, rev := range history {
+				got = append(got, rev.Subject+":"+rev.OldPath+">"+rev.Path)
+			}
+
+			want := []string{"grow:>lib.go", "move:util.go>lib.go", "initial:>util.go"}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("History() = %v, want %v", got, want)
+			}
+
+			limited, _ := NewFileAnalyzer(backend, &git.LogOptions{Limit: 2}).History("lib.go")
+			if len(limited) != 2 {
+				t.Errorf("History() with limit 2 returned %d revisions", len(limited))
+			}
+
+			reviewers, err := NewAuthorAnalyzer(backend, &git.LogOptions{}).SuggestReviewers("lib.go", 5)
+			if err != nil || !reflect.DeepEqual(reviewers, []string{"alice@example.com", "bob@example.com", "carol@example.com"}) {
+				t.Errorf("SuggestReviewers() = %v, %v, want everyone who touched the file before and after the move", reviewers, err)
+			}
+
+			// Below the threshold the move is a deletion and an addition
+			strict, _ := NewFileAnalyzer(backend, &git.LogOptions{RenameThreshold: 100}).History("lib.go")
+			if len(strict) != 2 || strict[1].OldPath != "" {
+				t.Errorf("History() with exact renames only = %+v, want grow and the addition by move", strict)
+			}
+
+			// The old path ends where the file was moved
+			if old, _ := NewFileAnalyzer(backend, &git.LogOptions{}).History("util.go"); len(old) != 1 {
+				t.Errorf("History(old path) = %+v, want initial only", old)
+			}
+		})
+	}
+}
+
+func TestFileHistoryFilters(t *testing.T) {
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	content := "package util\n\nfunc A() {}\nfunc B() {}\nfunc C() {}\n"
+
+	repo.Write("util.go", content)
+	repo.Commit("Alice", "alice@example.com", "initial", day)
+
+	repo.Git(day, "mv", "util.go", "lib.go")
+	repo.Commit("Bob", "bob@example.com", "move", day.AddDate(0, 0, 1))
+
+	repo.Write("lib.go", content+"func D() {}\n")
+	repo.Commit("Carol", "carol@example.com", "grow", day.AddDate(0, 0, 2))
+
+	gogit, err := git.NewGoGitBackend(repo.Dir)
+	if err != nil {
+		t.Fatalf("NewGoGitBackend() error = %v", err)
+	}
+
+	// Renames are followed whether or not their commits are selected
+	tests := map[string]struct {
+		options git.LogOptions
+		want    []string
+	}{
+		"ignored mover": {git.LogOptions{IgnoreAuthors: []string{"bob@"}}, []string{"grow:>lib.go", "initial:>util.go"}},
+		"author":        {git.LogOptions{Author: "Alice"}, []string{"initial:>util.go"}},
+		"until":         {git.LogOptions{Until: day}, []string{"initial:>util.go"}},
+		"no merges":     {git.LogOptions{NoMerges: true}, []string{"grow:>lib.go", "move:util.go>lib.go", "initial:>util.go"}},
+		// Merges carry no changes of their own in the log
+		"merges only": {git.LogOptions{MergesOnly: true}, nil},
+	}
+
+	for name, tt := range tests {
+		for backendName, backend := range map[string]git.Backend{"exec": repo.backend(), "go-git": gogit} {
+			t.Run(name+"/"+backendName, func(t *testing.T) {
+				history, err := NewFileAnalyzer(backend, &tt.options).History("lib.go")
+				if err != nil {
+					t.Fatalf("History() error = %v", err)
+				}
+
+				var got []string
+				for _, rev := range history {
+					got = append(got, rev.Subject+":"+rev.OldPath+">"+rev.Path)
+				}
+
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("History() = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestFileHistoryWalksThePath(t *testing.T) {
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	content := "package a\n\nfunc A() {}\nfunc B() {}\nfunc C() {}\n"
+
+	repo.Write("a.go", content)
+	repo.Commit("Alice", "alice@example.com", "initial", day)
+
+	repo.Git(day, "mv", "a.go", "b.go")
+	repo.Commit("Bob", "bob@example.com", "first move", day.AddDate(0, 0, 1))
+
+	repo.Write("other.go", "package other\n")
+	repo.Commit("Bob", "bob@example.com", "unrelated", day.AddDate(0, 0, 2))
+
+	repo.Git(day, "mv", "b.go", "c.go")
+	repo.Commit("Carol", "carol@example.com", "second move", day.AddDate(0, 0, 3))
+
+	backend := &recordingBackend{Backend: repo.backend()}
+
+	history, err := NewFileAnalyzer(backend, &git.LogOptions{}).History("c.go")
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+
+	var got []string
+	for _, rev := range history {
+		got = append(got, rev.Subject+":"+rev.OldPath+">"+rev.Path)
+	}
+
+	if want := []string{"second move:b.go>c.go", "first move:a.go>b.go", "initial:>a.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("History() = %v, want %v", got, want)
+	}
+
+	// Only single commits are read without a path
+	for _, walk := range backend.walks {
+		if !slices.Contains(walk, "--") && !slices.Contains(walk, "--max-count=1") {
+			t.Errorf("History() walked the whole history: %v", walk)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/inovacc/git-nerds/internal/git"
+)
+
+// BotPatterns are the author patterns excluded by LogOptions.IgnoreBots
+var BotPatterns = []string{
+	`\[bot\]`,
+	`(?i)dependabot`,
+	`(?i)renovate`,
+	`(?i)github-actions`,
+}
+
+// authorFilter excludes commits whose author name or email matches one of
+// the LogOptions.IgnoreAuthors patterns
+type authorFilter struct {
+	patterns []*regexp.Regexp
+}
+
+// newAuthorFilter compiles the ignore patterns of options. A nil filter
+// excludes nothing.
+func newAuthorFilter(options *git.LogOptions) (*authorFilter, error) {
+	patterns := options.IgnoreAuthors
+	if options.IgnoreBots {
+		patterns = append(patterns[:len(patterns):len(patterns)], BotPatterns...)
+	}
+
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	f := &authorFilter{patterns: make([]*regexp.Regexp, 0, len(patterns))}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore author pattern %q: %w", pattern, err)
+		}
+		f.patterns = append(f.patterns, re)
+	}
+
+	return f, nil
+}
+
+// ignored reports whether an author identity is excluded
+func (f *authorFilter) ignored(name, email string) bool {
+	if f == nil {
+		return false
+	}
+
+	for _, re := range f.patterns {
+		if re.MatchString(name) || re.MatchString(email) {
+			return true
+		}
+	}
+
+	return false
+}
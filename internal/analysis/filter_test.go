@@ -0,0 +1,161 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+)
+
+// newBotFixture builds a history with a human author, a renamed bot and a
+// bot that only shows up in its email
+func newBotFixture(t *testing.T) *fixtureRepo {
+	t.Helper()
+
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+
+	repo.Write("go.mod", "module demo\n")
+	repo.Commit("Alice", "alice@example.com", "initial", day)
+
+	repo.Write("go.mod", "module demo\n\ngo 1.22\n")
+	repo.Commit("dependabot[bot]", "49699333+dependabot[bot]@users.noreply.github.com", "bump go", day.Add(time.Hour))
+
+	repo.Write("renovate.json", "{}\n")
+	repo.Commit("Renovate", "bot@renovateapp.com", "configure renovate", day.Add(2*time.Hour))
+
+	repo.Write("main.go", "package main\n")
+	repo.Commit("Bob", "bob@example.com", "add main", day.AddDate(0, 0, 1))
+
+	return repo
+}
+
+func TestIgnoreAuthors(t *testing.T) {
+	repo := newBotFixture(t)
+
+	tests := []struct {
+		name    string
+		options git.LogOptions
+		want    map[string]int
+	}{
+		{
+			name:    "no filter",
+			options: git.LogOptions{},
+			want:    map[string]int{"Alice": 1, "Bob": 1, "dependabot[bot]": 1, "Renovate": 1},
+		},
+		{
+			name:    "by name",
+			options: git.LogOptions{IgnoreAuthors: []string{"^Bob$"}},
+			want:    map[string]int{"Alice": 1, "dependabot[bot]": 1, "Renovate": 1},
+		},
+		{
+			name:    "by email",
+			options: git.LogOptions{IgnoreAuthors: []string{`@renovateapp\.com$`}},
+			want:    map[string]int{"Alice": 1, "Bob": 1, "dependabot[bot]": 1},
+		},
+		{
+			name:    "bots",
+			options: git.LogOptions{IgnoreBots: true},
+			want:    map[string]int{"Alice": 1, "Bob": 1},
+		},
+		{
+			name:    "bots and patterns",
+			options: git.LogOptions{IgnoreBots: true, IgnoreAuthors: []string{"alice@"}},
+			want:    map[string]int{"Bob": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAuthorAnalyzer(repo.backend(), &tt.options).CommitsPerAuthor()
+			if err != nil {
+				t.Fatalf("CommitsPerAuthor() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CommitsPerAuthor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIgnoreAuthorsAcrossAnalyzers(t *testing.T) {
+	repo := newBotFixture(t)
+	options := &git.LogOptions{IgnoreBots: true}
+
+	days, err := NewTemporalAnalyzer(repo.backend(), options).CommitsByDay()
+	if err != nil {
+		t.Fatalf("CommitsByDay() error = %v", err)
+	}
+
+	if want := map[string]int{"2024-05-06": 1, "2024-05-07": 1}; !reflect.DeepEqual(days, want) {
+		t.Errorf("CommitsByDay() = %v, want %v", days, want)
+	}
+
+	files, err := NewFileAnalyzer(repo.backend(), options).Files()
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+
+	for _, f := range files {
+		if f.Path == "renovate.json" || f.Changes != 1 {
+			t.Errorf("file %+v should only count human changes", f)
+		}
+	}
+
+	branches, err := NewBranchAnalyzer(repo.backend(), options).DetailedBranchInfo()
+	if err != nil {
+		t.Fatalf("DetailedBranchInfo() error = %v", err)
+	}
+
+	if len(branches) != 1 || branches[0].CommitCount != 2 {
+		t.Errorf("branches = %+v, want main with 2 commits", branches)
+	}
+}
+
+func TestIgnoreAuthorsWithLimit(t *testing.T) {
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+
+	repo.Commit("Alice", "alice@example.com", "one", day)
+	repo.Commit("Alice", "alice@example.com", "two", day.Add(time.Hour))
+	repo.Commit("dependabot[bot]", "bot@example.com", "bump a", day.Add(2*time.Hour))
+	repo.Commit("dependabot[bot]", "bot@example.com", "bump b", day.Add(3*time.Hour))
+
+	gogit, err := git.NewGoGitBackend(repo.Dir)
+	if err != nil {
+		t.Fatalf("NewGoGitBackend() error = %v", err)
+	}
+
+	// Limit counts the commits left once the bots are dropped, whatever
+	// else is set
+	tests := map[string]git.LogOptions{
+		"limit":     {},
+		"until":     {Until: day.AddDate(0, 0, 1)},
+		"committer": {Until: day.AddDate(0, 0, 1), DateSource: git.DateCommitter},
+		"cached":    {CacheDir: t.TempDir()},
+	}
+
+	for name, options := range tests {
+		for backendName, backend := range map[string]git.Backend{"exec": repo.backend(), "go-git": gogit} {
+			t.Run(name+"/"+backendName, func(t *testing.T) {
+				options.Limit, options.IgnoreBots = 2, true
+
+				got, err := NewAuthorAnalyzer(backend, &options).CommitsPerAuthor()
+				if want := map[string]int{"Alice": 2}; err != nil || !reflect.DeepEqual(got, want) {
+					t.Errorf("CommitsPerAuthor() = %v, %v, want %v", got, err, want)
+				}
+			})
+		}
+	}
+}
+
+func TestIgnoreAuthorsInvalidPattern(t *testing.T) {
+	repo := newBotFixture(t)
+
+	_, err := NewAuthorAnalyzer(repo.backend(), &git.LogOptions{IgnoreAuthors: []string{"("}}).DetailedAuthorStats()
+	if err == nil {
+		t.Error("DetailedAuthorStats() with invalid pattern should fail")
+	}
+}
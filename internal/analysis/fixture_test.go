@@ -0,0 +1,32 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/gittest"
+)
+
+// fixtureRepo is a gittest repository that also opens a backend
+type fixtureRepo struct {
+	*gittest.Repo
+	t *testing.T
+}
+
+// newFixtureRepo initializes an empty repository in a temporary directory
+func newFixtureRepo(t *testing.T) *fixtureRepo {
+	t.Helper()
+	return &fixtureRepo{Repo: gittest.New(t), t: t}
+}
+
+// backend returns an exec backend bound to the fixture
+func (r *fixtureRepo) backend() git.Backend {
+	r.t.Helper()
+
+	backend, err := git.NewExecBackend(r.Dir)
+	if err != nil {
+		r.t.Fatalf("NewExecBackend() error = %v", err)
+	}
+
+	return backend
+}
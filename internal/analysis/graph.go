@@ -0,0 +1,297 @@
+package analysis
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/inovacc/git-nerds/internal/git"
+)
+
+// commitGraph is a compact parent graph of the repository history, used
+// to answer reachability questions without spawning git per ref
+type commitGraph struct {
+	index   map[string]int32
+	hashes  []string
+	parents [][]int32
+	ignored []bool // commits by ignored authors, not counted
+	stamp   []uint32
+	epoch   uint32
+
+	// Ancestors of the commit passed to mark, kept apart from the stamps
+	// of the walks that query them
+	marks     []uint32
+	markEpoch uint32
+
+	// Owner of every commit given to claim, zero when unclaimed
+	owners []int32
+}
+
+// loadCommitGraph reads the parent graph of every ref in a single walk,
+// marking the commits excluded by filter
+func loadCommitGraph(backend git.Backend, filter *authorFilter) (*commitGraph, error) {
+	stream, err := backend.LogStream("--all", "--pretty=tformat:%H %P%x00%aN%x00%aE")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit graph: %w", err)
+	}
+	defer stream.Close()
+
+	g := &commitGraph{index: make(map[string]int32)}
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		line, ident, _ := strings.Cut(scanner.Text(), "\x00")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		id := g.id(fields[0])
+		for _, parent := range fields[1:] {
+			g.parents[id] = append(g.parents[id], g.id(parent))
+		}
+
+		name, email, _ := strings.Cut(ident, "\x00")
+		if filter.ignored(name, email) {
+			g.ignored[id] = true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read commit graph: %w", err)
+	}
+
+	g.stamp = make([]uint32, len(g.parents))
+	g.marks = make([]uint32, len(g.parents))
+	g.owners = make([]int32, len(g.parents))
+	return g, nil
+}
+
+// id returns the node of a commit hash, adding it if needed
+func (g *commitGraph) id(hash string) int32 {
+	if id, ok := g.index[hash]; ok {
+		return id
+	}
+
+	id := int32(len(g.parents))
+	g.index[hash] = id
+	g.hashes = append(g.hashes, hash)
+	g.parents = append(g.parents, nil)
+	g.ignored = append(g.ignored, false)
+	return id
+}
+
+// reachable returns the number of commits reachable from hash, like
+// git rev-list --count without the ignored commits. Unknown hashes yield
+// zero.
+func (g *commitGraph) reachable(hash string) int {
+	start, ok := g.index[hash]
+	if !ok {
+		return 0
+	}
+
+	g.epoch++
+	g.stamp[start] = g.epoch
+
+	count := 0
+	stack := []int32{start}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !g.ignored[id] {
+			count++
+		}
+
+		for _, parent := range g.parents[id] {
+			if g.stamp[parent] != g.epoch {
+				g.stamp[parent] = g.epoch
+				stack = append(stack, parent)
+			}
+		}
+	}
+
+	return count
+}
+
+// mark records the commits reachable from hash, for the next calls to
+// exclusive
+func (g *commitGraph) mark(hash string) {
+	g.markEpoch++
+
+	start, ok := g.index[hash]
+	if !ok {
+		return
+	}
+
+	g.marks[start] = g.markEpoch
+	stack := []int32{start}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, parent := range g.parents[id] {
+			if g.marks[parent] != g.markEpoch {
+				g.marks[parent] = g.markEpoch
+				stack = append(stack, parent)
+			}
+		}
+	}
+}
+
+// exclusive returns the number of commits reachable from hash but not
+// from the commit last passed to mark, like git rev-list --count B ^A
+// without the ignored commits, along with the marked commits where the
+// walk stopped. Those are the merge bases, possibly with some of their
+// ancestors; no boundary means the histories are unrelated.
+func (g *commitGraph) exclusive(hash string) (int, []int32) {
+	start, ok := g.index[hash]
+	if !ok {
+		return 0, nil
+	}
+
+	if g.marks[start] == g.markEpoch {
+		return 0, []int32{start}
+	}
+
+	g.epoch++
+	g.stamp[start] = g.epoch
+
+	count := 0
+	var boundary []int32
+
+	stack := []int32{start}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !g.ignored[id] {
+			count++
+		}
+
+		for _, parent := range g.parents[id] {
+			if g.stamp[parent] == g.epoch {
+				continue
+			}
+			g.stamp[parent] = g.epoch
+
+			if g.marks[parent] == g.markEpoch {
+				boundary = append(boundary, parent)
+				continue
+			}
+			stack = append(stack, parent)
+		}
+	}
+
+	return count, boundary
+}
+
+// claim gives owner, which must not be zero, the commits reachable from
+// hash that no earlier claim reached. Walks stop at claimed commits, so
+// claiming any number of tips reads the history only once.
+func (g *commitGraph) claim(hash string, owner int32) {
+	start, ok := g.index[hash]
+	if !ok || g.owners[start] != 0 {
+		return
+	}
+
+	g.owners[start] = owner
+	stack := []int32{start}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, parent := range g.parents[id] {
+			if g.owners[parent] == 0 {
+				g.owners[parent] = owner
+				stack = append(stack, parent)
+			}
+		}
+	}
+}
+
+// unclaimed is exclusive against every claimed commit instead of the
+// marked ones: it returns the number of commits reachable from hash that
+// no claim reached, without the ignored commits, along with the claimed
+// commits where the walk stopped
+func (g *commitGraph) unclaimed(hash string) (int, []int32) {
+	start, ok := g.index[hash]
+	if !ok {
+		return 0, nil
+	}
+
+	if g.owners[start] != 0 {
+		return 0, []int32{start}
+	}
+
+	g.epoch++
+	g.stamp[start] = g.epoch
+
+	count := 0
+	var boundary []int32
+
+	stack := []int32{start}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !g.ignored[id] {
+			count++
+		}
+
+		for _, parent := range g.parents[id] {
+			if g.stamp[parent] == g.epoch {
+				continue
+			}
+			g.stamp[parent] = g.epoch
+
+			if g.owners[parent] != 0 {
+				boundary = append(boundary, parent)
+				continue
+			}
+			stack = append(stack, parent)
+		}
+	}
+
+	return count, boundary
+}
+
+// mergeBase picks the best common ancestor among the boundary of an
+// exclusive walk: the one no other boundary commit descends from
+func (g *commitGraph) mergeBase(boundary []int32) string {
+	if len(boundary) == 0 {
+		return ""
+	}
+
+	for _, candidate := range boundary {
+		g.epoch++
+
+		ancestor := false
+		stack := make([]int32, 0, len(boundary))
+		for _, other := range boundary {
+			if other != candidate {
+				g.stamp[other] = g.epoch
+				stack = append(stack, other)
+			}
+		}
+
+		for len(stack) > 0 && !ancestor {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			for _, parent := range g.parents[id] {
+				if parent == candidate {
+					ancestor = true
+					break
+				}
+				if g.stamp[parent] != g.epoch {
+					g.stamp[parent] = g.epoch
+					stack = append(stack, parent)
+				}
+			}
+		}
+
+		if !ancestor {
+			return g.hashes[candidate]
+		}
+	}
+
+	return g.hashes[boundary[0]]
+}
@@ -0,0 +1,188 @@
+package analysis
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/inovacc/git-nerds/internal/git"
+)
+
+// githubNoreply matches GitHub's private commit addresses, with or without
+// the numeric user id prefix
+var githubNoreply = regexp.MustCompile(`(?i)^(?:\d+\+)?([^@]+)@users\.noreply\.github\.com$`)
+
+// identityResolver maps the identities an author commits under to one
+// canonical email. The mailmap is applied by git through %aN/%aE; this
+// adds LogOptions.IdentityAliases and the GitHub noreply heuristic on top.
+type identityResolver struct {
+	aliases map[string]string // lowercased email or name -> canonical email
+	noreply map[string]string // lowercased noreply email -> canonical email
+	mailmap *git.Mailmap      // for identities git doesn't map, like trailers
+}
+
+// newIdentityResolver builds a resolver for options. The noreply heuristic
+// needs a cheap pre-scan of the author identities in the history.
+func newIdentityResolver(backend git.Backend, options *git.LogOptions) (*identityResolver, error) {
+	r := &identityResolver{
+		aliases: make(map[string]string, len(options.IdentityAliases)),
+		noreply: make(map[string]string),
+		mailmap: git.ParseMailmap(string(rootFile(backend, ".mailmap"))),
+	}
+
+	for alias, canonical := range options.IdentityAliases {
+		r.aliases[strings.ToLower(alias)] = canonical
+	}
+
+	if options.MergeNoreply {
+		if err := r.learnNoreply(backend, options); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// empty reports whether the resolver never changes an identity
+func (r *identityResolver) empty() bool {
+	return len(r.aliases) == 0 && len(r.noreply) == 0
+}
+
+// resolve returns the canonical email for an author identity
+func (r *identityResolver) resolve(name, email string) string {
+	email = r.alias(name, email)
+
+	if canonical, ok := r.noreply[strings.ToLower(email)]; ok {
+		return canonical
+	}
+
+	return email
+}
+
+// resolveTrailer returns the canonical name and email for the identity
+// of a trailer, applying the mailmap first as git does for authors
+func (r *identityResolver) resolveTrailer(name, email string) (string, string) {
+	name, email = r.mailmap.Resolve(name, email)
+	return name, r.resolve(name, email)
+}
+
+// alias applies IdentityAliases, matching the email first, then the name
+func (r *identityResolver) alias(name, email string) string {
+	if canonical, ok := r.aliases[strings.ToLower(email)]; ok {
+		return canonical
+	}
+	if canonical, ok := r.aliases[strings.ToLower(name)]; ok {
+		return canonical
+	}
+	return email
+}
+
+// learnNoreply maps GitHub noreply addresses to the regular address most
+// used under the same name or GitHub login. Noreply addresses without a
+// regular counterpart are merged with the other variants of their login.
+func (r *identityResolver) learnNoreply(backend git.Backend, options *git.LogOptions) error {
+	opts := *options
+	opts.Format = ""
+	args := append([]string{"--pretty=tformat:%aN%x00%aE"}, git.BuildLogArgs(&opts)...)
+
+	stream, err := backend.LogStream(args...)
+	if err != nil {
+		return fmt.Errorf("failed to scan identities: %w", err)
+	}
+	defer stream.Close()
+
+	type usage struct {
+		email   string // as first seen
+		names   map[string]bool
+		commits int
+	}
+
+	emails := make(map[string]*usage)
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		name, email, ok := strings.Cut(scanner.Text(), "\x00")
+		if !ok {
+			continue
+		}
+
+		email = r.alias(name, email)
+		key := strings.ToLower(email)
+
+		u, exists := emails[key]
+		if !exists {
+			u = &usage{email: email, names: make(map[string]bool)}
+			emails[key] = u
+		}
+		u.names[strings.ToLower(name)] = true
+		u.commits++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to scan identities: %w", err)
+	}
+
+	// Index regular addresses by the names they were used with
+	byName := make(map[string][]string)
+	for key, u := range emails {
+		if githubNoreply.MatchString(key) {
+			continue
+		}
+		for name := range u.names {
+			byName[name] = append(byName[name], key)
+		}
+	}
+
+	// best picks the most used address, breaking ties alphabetically
+	best := func(keys []string) string {
+		sort.Slice(keys, func(i, j int) bool {
+			if emails[keys[i]].commits != emails[keys[j]].commits {
+				return emails[keys[i]].commits > emails[keys[j]].commits
+			}
+			return keys[i] < keys[j]
+		})
+		return keys[0]
+	}
+
+	byLogin := make(map[string][]string)
+
+	for key, u := range emails {
+		m := githubNoreply.FindStringSubmatch(key)
+		if m == nil {
+			continue
+		}
+
+		login := strings.ToLower(m[1])
+		byLogin[login] = append(byLogin[login], key)
+
+		var candidates []string
+		for name := range u.names {
+			candidates = append(candidates, byName[name]...)
+		}
+		candidates = append(candidates, byName[login]...)
+
+		if len(candidates) > 0 {
+			r.noreply[key] = emails[best(candidates)].email
+		}
+	}
+
+	for _, variants := range byLogin {
+		canonical := emails[best(variants)].email
+		for _, key := range variants {
+			if target, mapped := r.noreply[key]; mapped {
+				canonical = target
+				break
+			}
+		}
+
+		for _, key := range variants {
+			if _, mapped := r.noreply[key]; !mapped {
+				r.noreply[key] = canonical
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+)
+
+// newIdentityFixture commits as one person under several identities
+func newIdentityFixture(t *testing.T) *fixtureRepo {
+	t.Helper()
+
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	commits := []struct{ name, email string }{
+		{"Jane Doe", "jane@corp.com"},
+		{"Jane Doe", "jane@corp.com"},
+		{"jane", "jane@laptop.local"},
+		{"Jane Doe", "12345+janed@users.noreply.github.com"},
+		{"janed", "janed@users.noreply.github.com"},
+		{"J. Doe", "jd@personal.dev"},
+		{"Sam", "98765+sam@users.noreply.github.com"},
+		{"Sam", "sam@users.noreply.github.com"},
+	}
+
+	for i, c := range commits {
+		repo.Write("file.txt", c.email+"\n")
+		repo.Commit(c.name, c.email, "change", day.Add(time.Duration(i)*time.Hour))
+	}
+
+	repo.Write(".mailmap", "Jane Doe <jane@corp.com> <jane@laptop.local>\n")
+
+	return repo
+}
+
+func TestAuthorIdentityUnification(t *testing.T) {
+	repo := newIdentityFixture(t)
+
+	tests := []struct {
+		name    string
+		options *git.LogOptions
+		want    map[string]int
+	}{
+		{
+			name:    "mailmap only",
+			options: &git.LogOptions{},
+			want: map[string]int{
+				"jane@corp.com":                        3,
+				"12345+janed@users.noreply.github.com": 1,
+				"janed@users.noreply.github.com":       1,
+				"jd@personal.dev":                      1,
+				"98765+sam@users.noreply.github.com":   1,
+				"sam@users.noreply.github.com":         1,
+			},
+		},
+		{
+			name: "aliases and noreply heuristic",
+			options: &git.LogOptions{
+				IdentityAliases: map[string]string{"JD@personal.dev": "jane@corp.com"},
+				MergeNoreply:    true,
+			},
+			want: map[string]int{
+				"jane@corp.com":                      6,
+				"98765+sam@users.noreply.github.com": 2,
+			},
+		},
+		{
+			name: "alias by name",
+			options: &git.LogOptions{
+				IdentityAliases: map[string]string{"j. doe": "jane@corp.com"},
+			},
+			want: map[string]int{
+				"jane@corp.com":                        4,
+				"12345+janed@users.noreply.github.com": 1,
+				"janed@users.noreply.github.com":       1,
+				"98765+sam@users.noreply.github.com":   1,
+				"sam@users.noreply.github.com":         1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := NewAuthorAnalyzer(repo.backend(), tt.options)
+
+			authors, err := analyzer.DetailedAuthorStats()
+			if err != nil {
+				t.Fatalf("DetailedAuthorStats() error = %v", err)
+			}
+
+			got := make(map[string]int)
+			for _, a := range authors {
+				got[a.Email] = a.Commits
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commits by email = %v, want %v", got, tt.want)
+			}
+
+			// CommitsPerAuthor groups the same identities by name
+			perAuthor, err := analyzer.CommitsPerAuthor()
+			if err != nil {
+				t.Fatalf("CommitsPerAuthor() error = %v", err)
+			}
+
+			byName := make(map[string]int)
+			for _, a := range authors {
+				byName[a.Name] += a.Commits
+			}
+
+			if !reflect.DeepEqual(perAuthor, byName) {
+				t.Errorf("CommitsPerAuthor() = %v, want %v", perAuthor, byName)
+			}
+		})
+	}
+}
+
+func TestSuggestReviewersUnifiesIdentities(t *testing.T) {
+	repo := newIdentityFixture(t)
+
+	options := &git.LogOptions{MergeNoreply: true}
+	reviewers, err := NewAuthorAnalyzer(repo.backend(), options).SuggestReviewers("file.txt", 5)
+	if err != nil {
+		t.Fatalf("SuggestReviewers() error = %v", err)
+	}
+
+	want := []string{"jane@corp.com", "98765+sam@users.noreply.github.com", "jd@personal.dev"}
+	if !reflect.DeepEqual(reviewers, want) {
+		t.Errorf("SuggestReviewers() = %v, want %v", reviewers, want)
+	}
+}
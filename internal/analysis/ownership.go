@@ -0,0 +1,357 @@
+package analysis
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+// ageBuckets are the upper bounds of the line age distribution. Lines older
+// than the last bound fall into a final open-ended bucket.
+var ageBuckets = []struct {
+	label  string
+	maxAge time.Duration
+}{
+	{"< 1 month", 30 * 24 * time.Hour},
+	{"1-6 months", 182 * 24 * time.Hour},
+	{"6-12 months", 365 * 24 * time.Hour},
+	{"1-2 years", 2 * 365 * 24 * time.Hour},
+	{"> 2 years", 0},
+}
+
+// OwnershipAnalyzer attributes the lines surviving in the tree to the
+// authors who last changed them, using git blame
+type OwnershipAnalyzer struct {
+	backend git.Backend
+	options *git.LogOptions
+	now     time.Time
+}
+
+// NewOwnershipAnalyzer creates a new ownership analyzer
+func NewOwnershipAnalyzer(backend git.Backend, options *git.LogOptions) *OwnershipAnalyzer {
+	return &OwnershipAnalyzer{
+		backend: backend,
+		options: options,
+		now:     time.Now(),
+	}
+}
+
+// LineOwner is an author's share of the surviving lines
+type LineOwner struct {
+	Name    string
+	Email   string
+	Lines   int
+	Percent float64
+}
+
+// AgeBucket counts surviving lines by time since they were last changed
+type AgeBucket struct {
+	Label   string
+	MaxAge  time.Duration // zero for the open-ended oldest bucket
+	Lines   int
+	Percent float64
+}
+
+// OwnershipDetails describes who owns the surviving lines of a file or
+// directory and how old they are
+type OwnershipDetails struct {
+	Path       string
+	Lines      int
+	Owners     []LineOwner // most lines first
+	Age        []AgeBucket // youngest first
+	AverageAge time.Duration
+}
+
+// OwnershipReport is the ownership of every file and directory in the tree
+type OwnershipReport struct {
+	Total       OwnershipDetails
+	Directories []OwnershipDetails
+	Files       []OwnershipDetails
+}
+
+// Ownership returns the ownership of a single file, or of all files below
+// a directory. A target missing from the analyzed tree is an
+// ErrInvalidPath.
+func (o *OwnershipAnalyzer) Ownership(target string) (*OwnershipDetails, error) {
+	target = strings.Trim(path.Clean("/"+target), "/")
+
+	total := newOwnershipTally(target)
+	if target == "" {
+		total.path = "."
+	}
+	err := o.blameTree(target, func(_ string, file *ownershipTally) {
+		total.merge(file)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := total.details()
+	return &result, nil
+}
+
+// Report returns the ownership of every file, every directory and the
+// whole tree
+func (o *OwnershipAnalyzer) Report() (*OwnershipReport, error) {
+	total := newOwnershipTally(".")
+	dirs := make(map[string]*ownershipTally)
+	var files []OwnershipDetails
+
+	err := o.blameTree("", func(name string, file *ownershipTally) {
+		files = append(files, file.details())
+		total.merge(file)
+
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			tally, ok := dirs[dir]
+			if !ok {
+				tally = newOwnershipTally(dir)
+				dirs[dir] = tally
+			}
+			tally.merge(file)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &OwnershipReport{
+		Total:       total.details(),
+		Directories: make([]OwnershipDetails, 0, len(dirs)),
+		Files:       files,
+	}
+
+	for _, tally := range dirs {
+		report.Directories = append(report.Directories, tally.details())
+	}
+
+	sort.Slice(report.Directories, func(i, j int) bool {
+		return report.Directories[i].Path < report.Directories[j].Path
+	})
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].Path < report.Files[j].Path
+	})
+
+	return report, nil
+}
+
+// blameTree blames every text file below target and passes the tally of
+// each one to fn, in tree order. Files are blamed concurrently by a
+// bounded pool of workers.
+func (o *OwnershipAnalyzer) blameTree(target string, fn func(name string, file *ownershipTally)) error {
+	filter, err := newAuthorFilter(o.options)
+	if err != nil {
+		return err
+	}
+
+	identities, err := newIdentityResolver(o.backend, o.options)
+	if err != nil {
+		return err
+	}
+
+	files, err := listTree(o.backend, o.options, target)
+	if err != nil {
+		return err
+	}
+
+	tallies := make([]*ownershipTally, len(files))
+	errs := make([]error, len(files))
+	jobs := make(chan int)
+
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				if failed.Load() {
+					continue
+				}
+
+				tallies[i], errs[i] = o.blameFile(files[i], filter, identities)
+				if errs[i] != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, name := range files {
+		if errs[i] != nil {
+			return errs[i]
+		}
+		if tallies[i] != nil {
+			fn(name, tallies[i])
+		}
+	}
+
+	return nil
+}
+
+// blameFile tallies the surviving lines of a file, or returns nil when
+// the file is binary
+func (o *OwnershipAnalyzer) blameFile(name string, filter *authorFilter, identities *identityResolver) (*ownershipTally, error) {
+	out, err := o.backend.Blame("--line-porcelain", treeRev(o.options), "--", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame %s: %w", name, err)
+	}
+
+	lines, err := parse.ParseBlamePorcelain(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse blame of %s: %w", name, err)
+	}
+
+	file := newOwnershipTally(name)
+	for _, line := range lines {
+		if strings.IndexByte(line.Text, 0) >= 0 {
+			return nil, nil
+		}
+
+		email := identities.resolve(line.Author, line.Email)
+		if filter.ignored(line.Author, email) {
+			continue
+		}
+
+		file.add(line.Author, email, line.Date, o.now)
+	}
+
+	return file, nil
+}
+
+// ownershipTally accumulates surviving lines for a path
+type ownershipTally struct {
+	path   string
+	lines  int
+	owners map[string]*ownerTally
+	ages   []int
+	ageSum float64 // seconds, a Duration would overflow on large trees
+}
+
+type ownerTally struct {
+	name   string
+	email  string
+	lines  int
+	latest time.Time
+}
+
+func newOwnershipTally(path string) *ownershipTally {
+	return &ownershipTally{
+		path:   path,
+		owners: make(map[string]*ownerTally),
+		ages:   make([]int, len(ageBuckets)),
+	}
+}
+
+// add counts one line last changed by an author at date
+func (t *ownershipTally) add(name, email string, date, now time.Time) {
+	owner, ok := t.owners[email]
+	if !ok {
+		owner = &ownerTally{email: email}
+		t.owners[email] = owner
+	}
+
+	owner.lines++
+	if !date.Before(owner.latest) {
+		owner.name = name
+		owner.latest = date
+	}
+
+	age := max(now.Sub(date), 0)
+	t.lines++
+	t.ageSum += age.Seconds()
+
+	for i, bucket := range ageBuckets {
+		if bucket.maxAge == 0 || age < bucket.maxAge {
+			t.ages[i]++
+			break
+		}
+	}
+}
+
+// merge adds the lines of other to t
+func (t *ownershipTally) merge(other *ownershipTally) {
+	t.lines += other.lines
+	t.ageSum += other.ageSum
+
+	for i, n := range other.ages {
+		t.ages[i] += n
+	}
+
+	for email, o := range other.owners {
+		owner, ok := t.owners[email]
+		if !ok {
+			owner = &ownerTally{email: email}
+			t.owners[email] = owner
+		}
+
+		owner.lines += o.lines
+		if !o.latest.Before(owner.latest) {
+			owner.name = o.name
+			owner.latest = o.latest
+		}
+	}
+}
+
+// details converts the tally into its public form
+func (t *ownershipTally) details() OwnershipDetails {
+	result := OwnershipDetails{
+		Path:   t.path,
+		Lines:  t.lines,
+		Owners: make([]LineOwner, 0, len(t.owners)),
+		Age:    make([]AgeBucket, len(ageBuckets)),
+	}
+
+	for _, owner := range t.owners {
+		result.Owners = append(result.Owners, LineOwner{
+			Name:    owner.name,
+			Email:   owner.email,
+			Lines:   owner.lines,
+			Percent: percent(owner.lines, t.lines),
+		})
+	}
+
+	sort.Slice(result.Owners, func(i, j int) bool {
+		if result.Owners[i].Lines != result.Owners[j].Lines {
+			return result.Owners[i].Lines > result.Owners[j].Lines
+		}
+		return result.Owners[i].Email < result.Owners[j].Email
+	})
+
+	for i, bucket := range ageBuckets {
+		result.Age[i] = AgeBucket{
+			Label:   bucket.label,
+			MaxAge:  bucket.maxAge,
+			Lines:   t.ages[i],
+			Percent: percent(t.ages[i], t.lines),
+		}
+	}
+
+	if t.lines > 0 {
+		result.AverageAge = time.Duration(t.ageSum / float64(t.lines) * float64(time.Second))
+	}
+
+	return result
+}
+
+// percent returns part as a percentage of total
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
@@ -0,0 +1,128 @@
+package analysis
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+)
+
+// newOwnershipFixture builds a tree where Alice wrote most of src/a.go and
+// Bob later rewrote one of its lines and added src/b.go and a binary
+func newOwnershipFixture(t *testing.T) *fixtureRepo {
+	t.Helper()
+
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	repo.Write("README.md", "# demo\n")
+	repo.Write("src/a.go", "package src\n\nvar a = 1\nvar b = 2\n")
+	repo.Commit("Alice", "alice@example.com", "initial", day)
+
+	repo.Write("src/a.go", "package src\n\nvar a = 1\nvar b = 3\n")
+	repo.Write("src/b.go", "package src\n\nvar c = 4\n")
+	repo.Write("logo.png", "\x89PNG\x00\x01\x02")
+	repo.Commit("Bob", "bob@example.com", "tweak", day.AddDate(0, 5, 20))
+
+	return repo
+}
+
+// owners summarizes ownership as email -> lines
+func owners(details OwnershipDetails) map[string]int {
+	result := make(map[string]int)
+	for _, o := range details.Owners {
+		result[o.Email] = o.Lines
+	}
+	return result
+}
+
+func TestOwnership(t *testing.T) {
+	repo := newOwnershipFixture(t)
+
+	gogit, err := git.NewGoGitBackend(repo.Dir)
+	if err != nil {
+		t.Fatalf("NewGoGitBackend() error = %v", err)
+	}
+
+	for name, backend := range map[string]git.Backend{"exec": repo.backend(), "go-git": gogit} {
+		t.Run(name, func(t *testing.T) {
+			analyzer := NewOwnershipAnalyzer(backend, &git.LogOptions{})
+			analyzer.now = time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+
+			file, err := analyzer.Ownership("src/a.go")
+			if err != nil {
+				t.Fatalf("Ownership() error = %v", err)
+			}
+
+			if file.Lines != 4 || file.Owners[0].Email != "alice@example.com" || file.Owners[0].Percent != 75 {
+				t.Errorf("src/a.go = %+v, want 4 lines, alice owning 75%%", file)
+			}
+
+			// Alice's lines are 181 days old, Bob's 10
+			if file.Age[0].Lines != 1 || file.Age[1].Lines != 3 {
+				t.Errorf("src/a.go age = %+v, want 1 line < 1 month and 3 in 1-6 months", file.Age)
+			}
+
+			dir, err := analyzer.Ownership("src/")
+			if err != nil {
+				t.Fatalf("Ownership() error = %v", err)
+			}
+
+			if want := map[string]int{"alice@example.com": 3, "bob@example.com": 4}; dir.Path != "src" || !reflect.DeepEqual(owners(*dir), want) {
+				t.Errorf("src = %+v, want %v", dir, want)
+			}
+
+			// A path of the tree without text lines is no error, a path
+			// missing from it is
+			if binary, err := analyzer.Ownership("logo.png"); err != nil || binary.Lines != 0 {
+				t.Errorf("Ownership(logo.png) = %+v, %v, want no lines", binary, err)
+			}
+
+			for _, missing := range []string{"src/missing.go", "docs", "src/a"} {
+				if _, err := analyzer.Ownership(missing); !errors.Is(err, ErrInvalidPath) {
+					t.Errorf("Ownership(%s) error = %v, want ErrInvalidPath", missing, err)
+				}
+			}
+
+			report, err := analyzer.Report()
+			if err != nil {
+				t.Fatalf("Report() error = %v", err)
+			}
+
+			var files []string
+			for _, f := range report.Files {
+				files = append(files, f.Path)
+			}
+
+			// The binary is skipped
+			if want := []string{"README.md", "src/a.go", "src/b.go"}; !reflect.DeepEqual(files, want) {
+				t.Errorf("report files = %v, want %v", files, want)
+			}
+
+			if len(report.Directories) != 1 || report.Directories[0].Path != "src" || report.Directories[0].Lines != 7 {
+				t.Errorf("report directories = %+v, want src with 7 lines", report.Directories)
+			}
+
+			if want := map[string]int{"alice@example.com": 4, "bob@example.com": 4}; report.Total.Lines != 8 || !reflect.DeepEqual(owners(report.Total), want) {
+				t.Errorf("report total = %+v, want 8 lines split %v", report.Total, want)
+			}
+		})
+	}
+}
+
+func TestOwnershipIgnoreAuthors(t *testing.T) {
+	repo := newOwnershipFixture(t)
+
+	analyzer := NewOwnershipAnalyzer(repo.backend(), &git.LogOptions{IgnoreAuthors: []string{"^Bob$"}})
+
+	file, err := analyzer.Ownership("src/a.go")
+	if err != nil {
+		t.Fatalf("Ownership() error = %v", err)
+	}
+
+	if want := map[string]int{"alice@example.com": 3}; file.Lines != 3 || !reflect.DeepEqual(owners(*file), want) {
+		t.Errorf("src/a.go = %+v, want only alice's 3 lines", file)
+	}
+}
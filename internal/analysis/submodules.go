@@ -0,0 +1,137 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+// SubmoduleAnalyzer inspects the submodules registered in the analyzed tree
+type SubmoduleAnalyzer struct {
+	backend git.Backend
+	options *git.LogOptions
+}
+
+// NewSubmoduleAnalyzer creates a new submodule analyzer
+func NewSubmoduleAnalyzer(backend git.Backend, options *git.LogOptions) *SubmoduleAnalyzer {
+	return &SubmoduleAnalyzer{
+		backend: backend,
+		options: options,
+	}
+}
+
+// SubmoduleInfo describes a submodule and the history of its pin
+type SubmoduleInfo struct {
+	Name      string
+	Path      string
+	URL       string
+	Branch    string
+	Commit    string    // commit pinned in the analyzed tree
+	Bumps     int       // commits that moved the pin after it was added
+	UpdatedAt time.Time // when the pin last changed
+}
+
+// Submodules lists the submodules of .gitmodules that are pinned in the
+// analyzed tree, in .gitmodules order
+func (s *SubmoduleAnalyzer) Submodules() ([]SubmoduleInfo, error) {
+	rev := treeRev(s.options)
+
+	pins, err := s.pins(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pins) == 0 {
+		return []SubmoduleInfo{}, nil
+	}
+
+	data, err := s.backend.Show(rev + ":.gitmodules")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .gitmodules: %w", err)
+	}
+
+	result := make([]SubmoduleInfo, 0, len(pins))
+
+	for _, cfg := range git.ParseGitmodules(data) {
+		commit, ok := pins[cfg.Path]
+		if !ok {
+			continue
+		}
+
+		info := SubmoduleInfo{
+			Name:   cfg.Name,
+			Path:   cfg.Path,
+			URL:    cfg.URL,
+			Branch: cfg.Branch,
+			Commit: commit,
+		}
+
+		if err := s.history(rev, &info); err != nil {
+			return nil, err
+		}
+
+		result = append(result, info)
+	}
+
+	return result, nil
+}
+
+// pins maps the path of every gitlink in the tree to its pinned commit
+func (s *SubmoduleAnalyzer) pins(rev string) (map[string]string, error) {
+	output, err := s.backend.LsTree("-r", "-z", rev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list submodules: %w", err)
+	}
+
+	pins := make(map[string]string)
+	for _, entry := range strings.Split(output, "\x00") {
+		if entry == "" {
+			continue
+		}
+
+		meta, name, ok := strings.Cut(entry, "\t")
+		if !ok {
+			return nil, fmt.Errorf("%w: tree entry %q", parse.ErrParseError, entry)
+		}
+
+		fields := strings.Fields(meta)
+		if len(fields) == 3 && fields[1] == "commit" {
+			pins[name] = fields[2]
+		}
+	}
+
+	return pins, nil
+}
+
+// history counts the commits that changed the pin of a submodule, dated
+// by LogOptions.DateSource
+func (s *SubmoduleAnalyzer) history(rev string, info *SubmoduleInfo) error {
+	format := "--pretty=format:%aI"
+	if s.options.DateSource == git.DateCommitter {
+		format = "--pretty=format:%cI"
+	}
+
+	output, err := s.backend.Log(format, rev, "--", info.Path)
+	if err != nil {
+		return fmt.Errorf("failed to get history of submodule %s: %w", info.Path, err)
+	}
+
+	dates := strings.Fields(output)
+	if len(dates) == 0 {
+		return nil
+	}
+
+	updated, err := time.Parse(time.RFC3339, dates[0])
+	if err != nil {
+		return fmt.Errorf("%w: submodule %s: invalid date %q", parse.ErrParseError, info.Path, dates[0])
+	}
+
+	// The oldest commit added the submodule
+	info.Bumps = len(dates) - 1
+	info.UpdatedAt = updated
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+)
+
+// newSubmoduleFixture builds a repository pinning a library submodule that
+// was bumped twice
+func newSubmoduleFixture(t *testing.T) (*fixtureRepo, *fixtureRepo) {
+	t.Helper()
+
+	day := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	lib := newFixtureRepo(t)
+	lib.Write("lib.go", "package lib\n")
+	lib.Commit("Carol", "carol@example.com", "feat: lib", day)
+
+	repo := newFixtureRepo(t)
+	repo.Write("main.go", "package main\n")
+	repo.Commit("Alice", "alice@example.com", "feat: main", day)
+	repo.Git(day, "-c", "protocol.file.allow=always", "submodule", "add", "-q", lib.Dir, "vendor/lib")
+	repo.Commit("Alice", "alice@example.com", "chore: add lib", day.AddDate(0, 0, 1))
+
+	for i := 1; i <= 2; i++ {
+		lib.Write("lib.go", strings.Repeat("// bump\n", i))
+		lib.Commit("Carol", "carol@example.com", "fix: bump", day.AddDate(0, 0, 1+i))
+
+		repo.Git(day, "-C", "vendor/lib", "pull", "-q", "origin", "main")
+		repo.Commit("Bob", "bob@example.com", "chore: bump lib", day.AddDate(0, 0, 1+i))
+	}
+
+	return repo, lib
+}
+
+func TestSubmodules(t *testing.T) {
+	repo, lib := newSubmoduleFixture(t)
+	head := strings.TrimSpace(lib.Git(time.Time{}, "rev-parse", "HEAD"))
+
+	gogit, err := git.NewGoGitBackend(repo.Dir)
+	if err != nil {
+		t.Fatalf("NewGoGitBackend() error = %v", err)
+	}
+
+	for name, backend := range map[string]git.Backend{"exec": repo.backend(), "go-git": gogit} {
+		t.Run(name, func(t *testing.T) {
+			submodules, err := NewSubmoduleAnalyzer(backend, &git.LogOptions{}).Submodules()
+			if err != nil {
+				t.Fatalf("Submodules() error = %v", err)
+			}
+
+			if len(submodules) != 1 {
+				t.Fatalf("Submodules() = %+v, want one submodule", submodules)
+			}
+
+			s := submodules[0]
+			if s.Name != "vendor/lib" || s.Path != "vendor/lib" || s.URL != lib.Dir || s.Commit != head || s.Bumps != 2 {
+				t.Errorf("Submodules() = %+v, want vendor/lib pinned at %s with 2 bumps", s, head)
+			}
+
+			if want := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC); !s.UpdatedAt.Equal(want) {
+				t.Errorf("UpdatedAt = %v, want %v", s.UpdatedAt, want)
+			}
+		})
+	}
+}
+
+func TestSubmodulesDateSource(t *testing.T) {
+	repo, _ := newSubmoduleFixture(t)
+
+	// The last bump is committed again a week later
+	landed := time.Date(2024, 3, 11, 10, 0, 0, 0, time.UTC)
+	repo.Git(landed, "commit", "-q", "--amend", "--no-edit")
+
+	tests := map[string]time.Time{
+		git.DateAuthor:    time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC),
+		git.DateCommitter: landed,
+	}
+
+	for source, want := range tests {
+		submodules, err := NewSubmoduleAnalyzer(repo.backend(), &git.LogOptions{DateSource: source}).Submodules()
+		if err != nil || len(submodules) != 1 || !submodules[0].UpdatedAt.Equal(want) {
+			t.Errorf("Submodules() by %s date = %+v, %v, want updated at %v", source, submodules, err, want)
+		}
+	}
+}
+
+func TestSubmodulesNone(t *testing.T) {
+	repo := newFixtureRepo(t)
+	repo.Write("main.go", "package main\n")
+	repo.Commit("Alice", "alice@example.com", "feat: main", time.Time{})
+
+	submodules, err := NewSubmoduleAnalyzer(repo.backend(), &git.LogOptions{}).Submodules()
+	if err != nil || len(submodules) != 0 {
+		t.Errorf("Submodules() = %+v, %v, want none", submodules, err)
+	}
+}
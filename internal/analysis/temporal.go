@@ -24,109 +24,87 @@ func NewTemporalAnalyzer(backend git.Backend, options *git.LogOptions) *Temporal
 	}
 }
 
+// aggregate runs a single walk collecting the temporal breakdowns
+func (t *TemporalAnalyzer) aggregate() (*TemporalAggregator, error) {
+	temporal := NewTemporalAggregator().In(t.options.Location)
+	if t.options.CoAuthors {
+		temporal.CreditCoAuthors()
+	}
+
+	if err := NewEngine(t.backend, t.options).Run(temporal); err != nil {
+		return nil, fmt.Errorf("failed to get commit dates: %w", err)
+	}
+
+	return temporal, nil
+}
+
 // CommitsByDay returns commits grouped by day
 func (t *TemporalAnalyzer) CommitsByDay() (map[string]int, error) {
-	opts := *t.options
-	opts.Format = "%ad"
-	args := git.BuildLogArgs(&opts)
-	args = append([]string{"--date=short"}, args...)
-
-	output, err := t.backend.Log(args...)
+	temporal, err := t.aggregate()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commits by day: %w", err)
+		return nil, err
 	}
 
-	return parse.ParseDateCounts(output)
+	return temporal.ByDay, nil
 }
 
 // CommitsByMonth returns commits grouped by month (YYYY-MM format)
 func (t *TemporalAnalyzer) CommitsByMonth() (map[string]int, error) {
-	opts := *t.options
-	opts.Format = "%ad"
-	args := git.BuildLogArgs(&opts)
-	args = append([]string{"--date=format:%Y-%m"}, args...)
-
-	output, err := t.backend.Log(args...)
+	temporal, err := t.aggregate()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commits by month: %w", err)
+		return nil, err
 	}
 
-	return parse.ParseDateCounts(output)
+	return temporal.ByMonth, nil
 }
 
 // CommitsByYear returns commits grouped by year
 func (t *TemporalAnalyzer) CommitsByYear() (map[string]int, error) {
-	opts := *t.options
-	opts.Format = "%ad"
-	args := git.BuildLogArgs(&opts)
-	args = append([]string{"--date=format:%Y"}, args...)
-
-	output, err := t.backend.Log(args...)
+	temporal, err := t.aggregate()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commits by year: %w", err)
+		return nil, err
 	}
 
-	return parse.ParseDateCounts(output)
+	return temporal.ByYear, nil
 }
 
 // CommitsByWeekday returns commits grouped by weekday
 func (t *TemporalAnalyzer) CommitsByWeekday() (map[string]int, error) {
-	opts := *t.options
-	opts.Format = "%ad"
-	args := git.BuildLogArgs(&opts)
-	args = append([]string{"--date=format:%A"}, args...) // Full weekday name
-
-	output, err := t.backend.Log(args...)
+	temporal, err := t.aggregate()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commits by weekday: %w", err)
+		return nil, err
 	}
 
-	return parse.ParseDateCounts(output)
+	return temporal.ByWeekday, nil
 }
 
 // CommitsByHour returns commits grouped by hour (0-23)
 func (t *TemporalAnalyzer) CommitsByHour() (map[int]int, error) {
-	opts := *t.options
-	opts.Format = "%ad"
-	args := git.BuildLogArgs(&opts)
-	args = append([]string{"--date=format:%H"}, args...)
-
-	output, err := t.backend.Log(args...)
+	temporal, err := t.aggregate()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commits by hour: %w", err)
-	}
-
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	result := make(map[int]int)
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		var hour int
-		if _, err := fmt.Sscanf(line, "%d", &hour); err == nil {
-			result[hour]++
-		}
+		return nil, err
 	}
 
-	return result, nil
+	return temporal.ByHour, nil
 }
 
 // CommitsByTimezone returns commits grouped by timezone
 func (t *TemporalAnalyzer) CommitsByTimezone() (map[string]int, error) {
-	opts := *t.options
-	opts.Format = "%ad"
-	args := git.BuildLogArgs(&opts)
-	args = append([]string{"--date=format:%z"}, args...) // Timezone offset
-
-	output, err := t.backend.Log(args...)
+	temporal, err := t.aggregate()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commits by timezone: %w", err)
+		return nil, err
 	}
 
-	return parse.ParseDateCounts(output)
+	return temporal.ByTimezone, nil
+}
+
+// weight is the number of times commit counts in the breakdowns: once,
+// or once per credited author with LogOptions.CoAuthors
+func (t *TemporalAnalyzer) weight(commit *parse.CommitInfo) int {
+	if !t.options.CoAuthors {
+		return 1
+	}
+	return len(credits(commit, git.SplitFull))
 }
 
 // ActivityHeatmap represents commit activity heatmap data
@@ -142,55 +120,46 @@ type DayActivity struct {
 	HourlyBreakdown map[int]int
 }
 
-// GenerateHeatmap generates a heatmap for the last N days
+// GenerateHeatmap generates a heatmap for the last N days. Every day of
+// the range is listed, days without commits included.
 func (t *TemporalAnalyzer) GenerateHeatmap(days int) (*ActivityHeatmap, error) {
-	since := time.Now().AddDate(0, 0, -days)
-	opts := *t.options
-	opts.Since = since
-	opts.Format = "%ad|%H"
-	args := git.BuildLogArgs(&opts)
-	args = append([]string{"--date=format:%Y-%m-%d|%H"}, args...)
+	now := inZone(time.Now(), t.options.Location)
 
-	output, err := t.backend.Log(args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate heatmap: %w", err)
-	}
-
-	lines := strings.Split(strings.TrimSpace(output), "\n")
+	opts := *t.options
+	opts.Since = now.AddDate(0, 0, -days)
 
 	// Map to store day -> hour -> count
 	dayMap := make(map[string]map[int]int)
+	first, last := dayKey(opts.Since), dayKey(now)
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		parts := strings.Split(line, "|")
-		if len(parts) != 2 {
-			continue
+	for commit, err := range streamCommits(t.backend, &opts, false) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate heatmap: %w", err)
 		}
 
-		dateStr := parts[0]
-		var hour int
-		fmt.Sscanf(parts[1], "%d", &hour)
+		date := inZone(commit.Date, t.options.Location)
 
+		dateStr := dayKey(date)
 		if _, exists := dayMap[dateStr]; !exists {
 			dayMap[dateStr] = make(map[int]int)
 		}
-		dayMap[dateStr][hour]++
+		dayMap[dateStr][date.Hour()] += t.weight(&commit)
+
+		// Author-local dates may fall just outside
+		first, last = min(first, dateStr), max(last, dateStr)
 	}
 
-	// Convert to slice
-	result := &ActivityHeatmap{
-		Days: make([]DayActivity, 0, len(dayMap)),
+	start, err := time.Parse("2006-01-02", first)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate heatmap: %w", err)
 	}
 
-	for dateStr, hourMap := range dayMap {
-		date, err := time.Parse("2006-01-02", dateStr)
-		if err != nil {
-			continue
+	result := &ActivityHeatmap{}
+
+	for date := start; dayKey(date) <= last; date = date.AddDate(0, 0, 1) {
+		hourMap := dayMap[dayKey(date)]
+		if hourMap == nil {
+			hourMap = make(map[int]int)
 		}
 
 		total := 0
@@ -206,76 +175,297 @@ func (t *TemporalAnalyzer) GenerateHeatmap(days int) (*ActivityHeatmap, error) {
 		})
 	}
 
-	// Sort by date
-	sort.Slice(result.Days, func(i, j int) bool {
-		return result.Days[i].Date.Before(result.Days[j].Date)
-	})
+	return result, nil
+}
+
+// PunchcardData counts commits by weekday and hour of the day, in
+// LogOptions.Location or the time zone each commit was made in
+type PunchcardData struct {
+	Commits [7][24]int // weekday (Sunday first) -> hour -> commits
+	Lines   [7][24]int // weekday -> hour -> lines added and deleted, when requested
+	Total   int
+}
+
+// Punchcard builds the weekday by hour punchcard of the selected commits.
+// A non-empty author keeps the commits of one identity, see
+// newAuthorMatcher; lines also counts the lines each commit changed,
+// which needs their diffs.
+func (t *TemporalAnalyzer) Punchcard(author string, lines bool) (*PunchcardData, error) {
+	var match *authorMatcher
+	if author != "" {
+		m, err := newAuthorMatcher(t.backend, t.options, author)
+		if err != nil {
+			return nil, err
+		}
+		match = m
+	}
+
+	split := coAuthorSplit(t.options)
+	result := &PunchcardData{}
+
+	for commit, err := range streamCommits(t.backend, t.options, lines) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate punchcard: %w", err)
+		}
+
+		date := inZone(commit.Date, t.options.Location)
+		day, hour := date.Weekday(), date.Hour()
+		weight, changed := t.weight(&commit), commit.Additions+commit.Deletions
+
+		if match != nil {
+			if !match.credited(&commit, t.options.CoAuthors) {
+				continue
+			}
+
+			// Only the matched identity's share of a co-authored commit
+			weight = 1
+			if split != "" {
+				for _, c := range credits(&commit, split) {
+					if match.matches(c.name, c.email) {
+						changed = c.additions + c.deletions
+						break
+					}
+				}
+			}
+		}
+
+		result.Commits[day][hour] += weight
+		result.Total += weight
+		if lines {
+			result.Lines[day][hour] += changed
+		}
+	}
 
 	return result, nil
 }
 
 // CalendarData represents a commit calendar for visualization
 type CalendarData struct {
-	Year   int
-	Months []MonthData
+	Year      int       // year of From
+	From      time.Time // first day covered
+	To        time.Time // last day covered
+	Months    []MonthData
+	Total     int    // commits in the calendar
+	Max       int    // most commits on a single day
+	Quartiles [3]int // upper bounds of intensity levels 1 to 3
 }
 
 // MonthData represents commit data for a specific month
 type MonthData struct {
+	Year       int
 	Month      time.Month
-	WeekMatrix [][]int // week -> day of week -> commit count
+	Days       map[int]int // day of month -> commit count, days without commits left out
+	WeekMatrix [][]int     // week -> day of week (Sunday first) -> commit count, -1 outside the month
+}
+
+// Level returns the intensity of a day with count commits, from 0 for no
+// commits to 4 for more than three quarters of Max
+func (c *CalendarData) Level(count int) int {
+	switch {
+	case count <= 0:
+		return 0
+	case count <= c.Quartiles[0]:
+		return 1
+	case count <= c.Quartiles[1]:
+		return 2
+	case count <= c.Quartiles[2]:
+		return 3
+	default:
+		return 4
+	}
 }
 
 // GenerateCalendar generates a calendar view for a specific year and author
 func (t *TemporalAnalyzer) GenerateCalendar(year int, author string) (*CalendarData, error) {
+	location := t.options.Location
+	if location == nil {
+		location = time.Local
+	}
+
+	from := time.Date(year, 1, 1, 0, 0, 0, 0, location)
+	return t.GenerateCalendarRange(from, from.AddDate(1, 0, -1), author)
+}
+
+// GenerateCalendarRange generates a calendar of the whole months from
+// from to to, counting the commits made between those days inclusive. A
+// non-empty author keeps the commits of one identity, see newAuthorMatcher.
+func (t *TemporalAnalyzer) GenerateCalendarRange(from, to time.Time, author string) (*CalendarData, error) {
+	first, last := dayKey(from), dayKey(to)
+	if last < first {
+		return nil, fmt.Errorf("calendar ends on %s before it starts on %s", last, first)
+	}
+
+	// Dates are filtered by day below, git only narrows the walk. A day
+	// of slack on each side covers commits whose zone differs from the
+	// one of from and to.
 	opts := *t.options
-	opts.Since = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
-	opts.Until = time.Date(year, 12, 31, 23, 59, 59, 0, time.UTC)
+	opts.Since = time.Date(from.Year(), from.Month(), from.Day()-1, 0, 0, 0, 0, from.Location())
+	opts.Until = time.Date(to.Year(), to.Month(), to.Day()+2, 0, 0, 0, 0, to.Location())
+
+	var match *authorMatcher
 	if author != "" {
-		opts.Author = author
+		m, err := newAuthorMatcher(t.backend, &opts, author)
+		if err != nil {
+			return nil, err
+		}
+		match = m
 	}
-	opts.Format = "%ad"
-	args := git.BuildLogArgs(&opts)
-	args = append([]string{"--date=short"}, args...)
 
-	output, err := t.backend.Log(args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate calendar: %w", err)
-	}
+	dateCounts := make(map[string]int)
+	for commit, err := range streamCommits(t.backend, &opts, false) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate calendar: %w", err)
+		}
 
-	dateCounts, err := parse.ParseDateCounts(output)
-	if err != nil {
-		return nil, err
+		day := dayKey(inZone(commit.Date, t.options.Location))
+		if day < first || day > last {
+			continue
+		}
+
+		switch {
+		case match == nil:
+			dateCounts[day] += t.weight(&commit)
+		case match.credited(&commit, t.options.CoAuthors):
+			dateCounts[day]++
+		}
 	}
 
+	start := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC)
+
 	calendar := &CalendarData{
-		Year:   year,
-		Months: make([]MonthData, 12),
+		Year: from.Year(),
+		From: time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC),
+		To:   time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC),
 	}
 
-	// Initialize months
-	for i := 0; i < 12; i++ {
-		calendar.Months[i] = MonthData{
-			Month:      time.Month(i + 1),
-			WeekMatrix: make([][]int, 0),
-		}
+	for month := start; !month.After(end); month = month.AddDate(0, 1, 0) {
+		calendar.Months = append(calendar.Months, calendarMonth(month, dateCounts))
+	}
+
+	for _, count := range dateCounts {
+		calendar.Total += count
+		calendar.Max = max(calendar.Max, count)
 	}
 
-	// Populate with commit data
-	for dateStr, count := range dateCounts {
-		date, err := time.Parse("2006-01-02", dateStr)
-		if err != nil || date.Year() != year {
+	calendar.Quartiles = quartiles(calendar.Max)
+
+	return calendar, nil
+}
+
+// quartiles returns the upper bounds of intensity levels 1 to 3 for a
+// calendar whose busiest day has busiest commits. Bounds are rounded up,
+// so a single commit is always level 1 and the top levels stay empty until
+// the busiest day has enough commits to tell them apart.
+func quartiles(busiest int) [3]int {
+	return [3]int{(busiest + 3) / 4, (busiest + 1) / 2, (busiest*3 + 3) / 4}
+}
+
+// calendarMonth lays out the month starting on first, with the commit
+// counts of dateCounts, keyed by day
+func calendarMonth(first time.Time, dateCounts map[string]int) MonthData {
+	data := MonthData{
+		Year:  first.Year(),
+		Month: first.Month(),
+		Days:  make(map[int]int),
+	}
+
+	offset := int(first.Weekday())
+	days := first.AddDate(0, 1, -1).Day()
+
+	for cell := 0; cell < offset+days; cell++ {
+		if cell%7 == 0 {
+			week := []int{-1, -1, -1, -1, -1, -1, -1}
+			data.WeekMatrix = append(data.WeekMatrix, week)
+		}
+
+		if cell < offset {
 			continue
 		}
 
-		monthIdx := int(date.Month()) - 1
-		// Note: Calendar matrix population would require more complex logic
-		// This is a simplified version
-		_ = monthIdx
-		_ = count
+		day := cell - offset + 1
+		count := dateCounts[dayKey(first.AddDate(0, 0, day-1))]
+		if count > 0 {
+			data.Days[day] = count
+		}
+		data.WeekMatrix[cell/7][cell%7] = count
 	}
 
-	return calendar, nil
+	return data
+}
+
+// authorMatcher selects the commits of one identity, given as a name, an
+// email or "Name <email>". Emails are resolved through the mailmap and the
+// identity options like commit authors, so any address of a person works.
+type authorMatcher struct {
+	name  string
+	email string
+}
+
+// newAuthorMatcher builds a matcher for query, resolving its email
+func newAuthorMatcher(backend git.Backend, options *git.LogOptions, query string) (*authorMatcher, error) {
+	query = strings.TrimSpace(query)
+
+	name, email := parse.ParseIdentity(query)
+	if email == "" {
+		if strings.Contains(query, "@") {
+			email = query
+		} else {
+			name = query
+		}
+	}
+
+	if email != "" {
+		identities, err := newIdentityResolver(backend, options)
+		if err != nil {
+			return nil, err
+		}
+		name, email = identities.resolveTrailer(name, email)
+	}
+
+	return &authorMatcher{name: name, email: email}, nil
+}
+
+// matches reports whether a resolved identity is the one looked for
+func (m *authorMatcher) matches(name, email string) bool {
+	if m.email != "" {
+		return strings.EqualFold(email, m.email)
+	}
+	return strings.EqualFold(name, m.name)
+}
+
+// credited reports whether commit is authored by the identity or, with
+// coAuthors, co-authored by it
+func (m *authorMatcher) credited(commit *parse.CommitInfo, coAuthors bool) bool {
+	if !coAuthors {
+		return m.matches(commit.Author, commit.Email)
+	}
+
+	for _, c := range credits(commit, git.SplitFull) {
+		if m.matches(c.name, c.email) {
+			return true
+		}
+	}
+	return false
+}
+
+// DateDrift returns the selected commits whose author and committer dates
+// are more than threshold apart, newest first
+func (t *TemporalAnalyzer) DateDrift(threshold time.Duration) ([]parse.CommitInfo, error) {
+	var result []parse.CommitInfo
+
+	for commit, err := range streamCommits(t.backend, t.options, false) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare commit dates: %w", err)
+		}
+
+		drift := commit.CommitDate.Sub(commit.AuthorDate)
+		if drift > threshold || -drift > threshold {
+			result = append(result, commit)
+		}
+	}
+
+	return result, nil
 }
 
 // CommitTrend represents commit trend over time
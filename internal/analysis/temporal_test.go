@@ -1,7 +1,9 @@
 package analysis
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/inovacc/git-nerds/internal/git"
 )
@@ -242,6 +244,133 @@ func TestGenerateCalendar(t *testing.T) {
 	}
 }
 
+func TestCalendarLevels(t *testing.T) {
+	tests := []struct {
+		max    int
+		levels []int // of 0 to max commits
+	}{
+		{0, []int{0}},
+		{1, []int{0, 1}},
+		{2, []int{0, 1, 3}},
+		{3, []int{0, 1, 2, 3}},
+		{4, []int{0, 1, 2, 3, 4}},
+		{5, []int{0, 1, 1, 2, 3, 4}},
+		{8, []int{0, 1, 1, 2, 2, 3, 3, 4, 4}},
+	}
+
+	for _, tt := range tests {
+		calendar := &CalendarData{Max: tt.max, Quartiles: quartiles(tt.max)}
+
+		levels := make([]int, tt.max+1)
+		for count := range levels {
+			levels[count] = calendar.Level(count)
+		}
+
+		if !reflect.DeepEqual(levels, tt.levels) {
+			t.Errorf("levels with max %d = %v, want %v", tt.max, levels, tt.levels)
+		}
+	}
+}
+
+func TestGenerateCalendarRange(t *testing.T) {
+	repo := newFixtureRepo(t)
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+	}
+
+	repo.Write(".mailmap", "Bob Builder <bob@example.com>\nBob Builder <bob@example.com> <bob@old.example.com>\n")
+	repo.Commit("Alice", "alice@example.com", "one", at(2023, 12, 30))
+	repo.Commit("Bob", "bob@old.example.com", "two", at(2024, 1, 2))
+	repo.Commit("Bob", "bob@example.com", "three", at(2024, 1, 2))
+	repo.Commit("Alice", "alice@example.com", "four", at(2024, 1, 2))
+	repo.Commit("Alice", "alice@example.com", "five", at(2024, 2, 29))
+	repo.Commit("Alice", "alice@example.com", "six", at(2024, 3, 1))
+
+	analyzer := NewTemporalAnalyzer(repo.backend(), &git.LogOptions{})
+
+	calendar, err := analyzer.GenerateCalendarRange(at(2023, 12, 15), at(2024, 2, 29), "")
+	if err != nil {
+		t.Fatalf("GenerateCalendarRange() error = %v", err)
+	}
+
+	if len(calendar.Months) != 3 || calendar.Months[0].Year != 2023 || calendar.Months[2].Month != time.February {
+		t.Fatalf("Months = %+v, want December 2023 to February 2024", calendar.Months)
+	}
+	if calendar.Total != 5 || calendar.Max != 3 || calendar.Quartiles != [3]int{1, 2, 3} {
+		t.Errorf("Total, Max, Quartiles = %d, %d, %v, want 5, 3, [1 2 3]", calendar.Total, calendar.Max, calendar.Quartiles)
+	}
+	if calendar.Level(0) != 0 || calendar.Level(1) != 1 || calendar.Level(3) != 3 {
+		t.Errorf("Level(0, 1, 3) = %d, %d, %d, want 0, 1, 3", calendar.Level(0), calendar.Level(1), calendar.Level(3))
+	}
+
+	january := calendar.Months[1]
+	if !reflect.DeepEqual(january.Days, map[int]int{2: 3}) {
+		t.Errorf("January Days = %v, want {2: 3}", january.Days)
+	}
+	if len(january.WeekMatrix) != 5 || !reflect.DeepEqual(january.WeekMatrix[0], []int{-1, 0, 3, 0, 0, 0, 0}) {
+		t.Errorf("January WeekMatrix = %v, want 5 weeks starting on Monday", january.WeekMatrix)
+	}
+	if february := calendar.Months[2].WeekMatrix; !reflect.DeepEqual(february[len(february)-1], []int{0, 0, 0, 0, 1, -1, -1}) {
+		t.Errorf("February WeekMatrix = %v, want the 29th on Thursday", february)
+	}
+
+	for _, author := range []string{"Bob Builder", "bob@old.example.com", "Bobby <BOB@example.com>"} {
+		calendar, err := analyzer.GenerateCalendarRange(at(2023, 12, 15), at(2024, 2, 29), author)
+		if err != nil {
+			t.Fatalf("GenerateCalendarRange(%q) error = %v", author, err)
+		}
+		if calendar.Total != 2 || calendar.Months[1].Days[2] != 2 {
+			t.Errorf("GenerateCalendarRange(%q) = %d commits, want Bob's 2", author, calendar.Total)
+		}
+	}
+
+	if _, err := analyzer.GenerateCalendarRange(at(2024, 2, 1), at(2024, 1, 1), ""); err == nil {
+		t.Error("GenerateCalendarRange() with reversed dates should fail")
+	}
+}
+
+func TestPunchcard(t *testing.T) {
+	repo := newFixtureRepo(t)
+	east := time.FixedZone("UTC+2", 2*60*60)
+
+	repo.Write("a.go", "a\nb\n")
+	repo.Commit("Alice", "alice@example.com", "tuesday", time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC))
+	repo.Write("b.go", "a\nb\nc\n")
+	repo.Commit("Bob", "bob@example.com", "late saturday", time.Date(2024, 1, 6, 23, 30, 0, 0, east))
+	repo.Write("a.go", "a\nb\nc\nd\n")
+	repo.Commit("Alice", "alice@example.com", "pair\n\nCo-authored-by: Bob <bob@example.com>", time.Date(2024, 1, 9, 9, 45, 0, 0, time.UTC))
+
+	tests := []struct {
+		name      string
+		options   git.LogOptions
+		author    string
+		total     int
+		tuesday   [2]int // commits and lines at 9:00
+		saturday  [2]int // commits and lines at 23:00
+		withLines bool
+	}{
+		{"everyone", git.LogOptions{}, "", 3, [2]int{2, 4}, [2]int{1, 3}, true},
+		{"author", git.LogOptions{}, "bob@example.com", 1, [2]int{0, 0}, [2]int{1, 0}, false},
+		{"co-author", git.LogOptions{CoAuthors: true, CoAuthorSplit: git.SplitEven}, "Bob", 2, [2]int{1, 1}, [2]int{1, 3}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card, err := NewTemporalAnalyzer(repo.backend(), &tt.options).Punchcard(tt.author, tt.withLines)
+			if err != nil {
+				t.Fatalf("Punchcard() error = %v", err)
+			}
+
+			tuesday := [2]int{card.Commits[time.Tuesday][9], card.Lines[time.Tuesday][9]}
+			saturday := [2]int{card.Commits[time.Saturday][23], card.Lines[time.Saturday][23]}
+			if card.Total != tt.total || tuesday != tt.tuesday || saturday != tt.saturday {
+				t.Errorf("Punchcard() = %d total, Tuesday %v, Saturday %v, want %d, %v, %v",
+					card.Total, tuesday, saturday, tt.total, tt.tuesday, tt.saturday)
+			}
+		})
+	}
+}
+
 func TestGetCommitTrend(t *testing.T) {
 	backend := setupTestBackend(t)
 	opts := &git.LogOptions{
@@ -0,0 +1,170 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+// resolveTrailers unifies the identities named by trailers like those of
+// commit authors, and drops Co-authored-by trailers of ignored authors. A
+// new slice is returned so cached commits are left untouched.
+func resolveTrailers(trailers []parse.Trailer, identities *identityResolver, filter *authorFilter) []parse.Trailer {
+	result := make([]parse.Trailer, 0, len(trailers))
+
+	for _, trailer := range trailers {
+		if trailer.Email != "" {
+			trailer.Name, trailer.Email = identities.resolveTrailer(trailer.Name, trailer.Email)
+
+			if trailer.Is(parse.TrailerCoAuthoredBy) && filter.ignored(trailer.Name, trailer.Email) {
+				continue
+			}
+		}
+
+		result = append(result, trailer)
+	}
+
+	return result
+}
+
+// credit is the share of a commit attributed to one identity
+type credit struct {
+	name      string
+	email     string
+	additions int
+	deletions int
+	files     int
+}
+
+// credits attributes commit to its author and, unless split is empty, to
+// the distinct co-authors named by its Co-authored-by trailers. Line and
+// file counts are divided according to split, one of the git.Split modes.
+func credits(commit *parse.CommitInfo, split string) []credit {
+	people := []credit{{name: commit.Author, email: commit.Email}}
+
+	if split != "" {
+		for _, trailer := range commit.Trailers {
+			if !trailer.Is(parse.TrailerCoAuthoredBy) || trailer.Email == "" {
+				continue
+			}
+
+			duplicate := false
+			for _, p := range people {
+				if strings.EqualFold(p.email, trailer.Email) {
+					duplicate = true
+					break
+				}
+			}
+
+			if !duplicate {
+				people = append(people, credit{name: trailer.Name, email: trailer.Email})
+			}
+		}
+	}
+
+	files := len(commit.Files)
+
+	switch n := len(people); {
+	case n == 1 || split == git.SplitFull:
+		for i := range people {
+			people[i].additions, people[i].deletions, people[i].files = commit.Additions, commit.Deletions, files
+		}
+	case split == git.SplitAuthor:
+		people[0].additions, people[0].deletions, people[0].files = commit.Additions, commit.Deletions, files
+	default:
+		for i := range people {
+			people[i].additions, people[i].deletions, people[i].files = commit.Additions/n, commit.Deletions/n, files/n
+		}
+		people[0].additions += commit.Additions % n
+		people[0].deletions += commit.Deletions % n
+		people[0].files += files % n
+	}
+
+	return people
+}
+
+// coAuthorSplit returns the line split of options, empty when co-authors
+// aren't credited
+func coAuthorSplit(options *git.LogOptions) string {
+	if !options.CoAuthors {
+		return ""
+	}
+	if options.CoAuthorSplit == "" {
+		return git.SplitEven
+	}
+	return options.CoAuthorSplit
+}
+
+// TrailerCount is the number of commits carrying a trailer with the same
+// identity, or the same value for trailers that don't name anyone
+type TrailerCount struct {
+	Name    string
+	Email   string
+	Value   string
+	Commits int
+}
+
+// TrailerAggregator counts the trailers of one key, such as Reviewed-by
+type TrailerAggregator struct {
+	key    string
+	counts map[string]*TrailerCount
+}
+
+// NewTrailerAggregator creates an empty aggregator for trailers of key,
+// which is matched case-insensitively
+func NewTrailerAggregator(key string) *TrailerAggregator {
+	return &TrailerAggregator{
+		key:    key,
+		counts: make(map[string]*TrailerCount),
+	}
+}
+
+// NeedsChanges implements Aggregator
+func (t *TrailerAggregator) NeedsChanges() bool { return false }
+
+// Add implements Aggregator. A trailer repeated within a commit counts once.
+func (t *TrailerAggregator) Add(commit *parse.CommitInfo) {
+	seen := make(map[string]bool)
+
+	for _, trailer := range commit.Trailers {
+		if !trailer.Is(t.key) {
+			continue
+		}
+
+		key := "value:" + trailer.Value
+		if trailer.Email != "" {
+			key = "email:" + strings.ToLower(trailer.Email)
+		}
+
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		count, exists := t.counts[key]
+		if !exists {
+			count = &TrailerCount{Name: trailer.Name, Email: trailer.Email, Value: trailer.Value}
+			t.counts[key] = count
+		}
+		count.Commits++
+	}
+}
+
+// Results returns the counts sorted by commits descending
+func (t *TrailerAggregator) Results() []TrailerCount {
+	result := make([]TrailerCount, 0, len(t.counts))
+	for _, count := range t.counts {
+		result = append(result, *count)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Commits != result[j].Commits {
+			return result[i].Commits > result[j].Commits
+		}
+		return result[i].Value < result[j].Value
+	})
+
+	return result
+}
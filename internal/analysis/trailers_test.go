@@ -0,0 +1,103 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+func TestCredits(t *testing.T) {
+	commit := &parse.CommitInfo{
+		Author: "Alice", Email: "alice@example.com",
+		Additions: 10, Deletions: 5, Files: []string{"a.go", "b.go", "c.go"},
+		Trailers: []parse.Trailer{
+			{Key: "Co-authored-by", Name: "Bob", Email: "bob@example.com"},
+			{Key: "co-authored-by", Name: "Alice", Email: "ALICE@example.com"}, // the author again
+			{Key: "Reviewed-by", Name: "Carol", Email: "carol@example.com"},
+		},
+	}
+
+	tests := []struct {
+		split string
+		want  []credit
+	}{
+		{"", []credit{{"Alice", "alice@example.com", 10, 5, 3}}},
+		{git.SplitEven, []credit{{"Alice", "alice@example.com", 5, 3, 2}, {"Bob", "bob@example.com", 5, 2, 1}}},
+		{git.SplitFull, []credit{{"Alice", "alice@example.com", 10, 5, 3}, {"Bob", "bob@example.com", 10, 5, 3}}},
+		{git.SplitAuthor, []credit{{"Alice", "alice@example.com", 10, 5, 3}, {"Bob", "bob@example.com", 0, 0, 0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.split, func(t *testing.T) {
+			if got := credits(commit, tt.split); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("credits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoAuthorAttribution(t *testing.T) {
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	repo.Write(".mailmap", "Bob Builder <bob@example.com> <bob@old.example.com>\n")
+	repo.Write("main.go", "a\nb\nc\nd\n")
+	repo.Commit("Alice", "alice@example.com", "pair on main\n\nCo-authored-by: Bobby <bob@old.example.com>\n"+
+		"Co-authored-by: dependabot[bot] <bot@example.com>\nReviewed-by: Carol <carol@example.com>", day)
+
+	repo.Write("main.go", "a\nb\nc\nd\ne\n")
+	repo.Commit("Bob", "bob@example.com", "solo\n\nReviewed-by: Carol <carol@example.com>\nReviewed-by: Dan <dan@example.com>", day.AddDate(0, 0, 1))
+
+	base := git.LogOptions{IgnoreBots: true}
+
+	t.Run("authors only", func(t *testing.T) {
+		counts, err := NewAuthorAnalyzer(repo.backend(), &base).CommitsPerAuthor()
+		if err != nil || !reflect.DeepEqual(counts, map[string]int{"Alice": 1, "Bob": 1}) {
+			t.Errorf("CommitsPerAuthor() = %v, %v", counts, err)
+		}
+	})
+
+	t.Run("co-authors", func(t *testing.T) {
+		opts := base
+		opts.CoAuthors, opts.CoAuthorSplit = true, git.SplitEven
+
+		authors, err := NewAuthorAnalyzer(repo.backend(), &opts).DetailedAuthorStats()
+		if err != nil {
+			t.Fatalf("DetailedAuthorStats() error = %v", err)
+		}
+
+		got := make(map[string]AuthorDetails)
+		for _, a := range authors {
+			got[a.Email] = a
+		}
+
+		// The mailmap applies to trailers, the bot co-author is ignored
+		if len(got) != 2 || got["bob@example.com"].Commits != 2 || got["bob@example.com"].LinesAdded != 3 ||
+			got["alice@example.com"].LinesAdded != 3 {
+			t.Errorf("DetailedAuthorStats() = %+v, want Alice with +3 and Bob with 2 commits and +3", authors)
+		}
+
+		byDay, err := NewTemporalAnalyzer(repo.backend(), &opts).CommitsByDay()
+		if err != nil || byDay["2024-05-01"] != 2 || byDay["2024-05-02"] != 1 {
+			t.Errorf("CommitsByDay() = %v, %v, want the paired commit counted twice", byDay, err)
+		}
+	})
+
+	t.Run("reviewers", func(t *testing.T) {
+		reviewers, err := NewAuthorAnalyzer(repo.backend(), &base).Trailers(parse.TrailerReviewedBy)
+		if err != nil {
+			t.Fatalf("Trailers() error = %v", err)
+		}
+
+		want := []TrailerCount{
+			{Name: "Carol", Email: "carol@example.com", Value: "Carol <carol@example.com>", Commits: 2},
+			{Name: "Dan", Email: "dan@example.com", Value: "Dan <dan@example.com>", Commits: 1},
+		}
+		if !reflect.DeepEqual(reviewers, want) {
+			t.Errorf("Trailers() = %+v, want %+v", reviewers, want)
+		}
+	})
+}
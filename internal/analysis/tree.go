@@ -0,0 +1,62 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/inovacc/git-nerds/internal/git"
+)
+
+// treeRev returns the revision whose tree is analyzed
+func treeRev(options *git.LogOptions) string {
+	if options.Branch == "" {
+		return "HEAD"
+	}
+	return options.Branch
+}
+
+// ErrInvalidPath is returned when a path is missing from the analyzed tree
+var ErrInvalidPath = errors.New("invalid repository path")
+
+// listTree returns the regular files below target in the analyzed tree,
+// filtered by the options pathspec. Symlinks and submodules are skipped.
+// A target matching no entry of the tree is an ErrInvalidPath.
+func listTree(backend git.Backend, options *git.LogOptions, target string) ([]string, error) {
+	args := []string{"-r", "-z", treeRev(options)}
+	if target != "" {
+		args = append(args, "--", target)
+	}
+
+	output, err := backend.LsTree(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	var files []string
+	found := false
+	for _, entry := range strings.Split(output, "\x00") {
+		meta, name, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		found = true
+
+		fields := strings.Fields(meta)
+		if len(fields) != 3 || fields[1] != "blob" || fields[0] == "120000" {
+			continue
+		}
+
+		if len(options.PathSpec) > 0 && !git.MatchPathSpec(options.PathSpec, name) {
+			continue
+		}
+
+		files = append(files, name)
+	}
+
+	if target != "" && !found {
+		return nil, fmt.Errorf("%w: %s not found in %s", ErrInvalidPath, target, treeRev(options))
+	}
+
+	return files, nil
+}
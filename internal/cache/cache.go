@@ -0,0 +1,374 @@
+// Package cache persists parsed commits between runs, so history that was
+// already analyzed isn't read from git again
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/parse"
+)
+
+// version is bumped whenever the stored records change shape, discarding
+// caches written by older releases
+const version = 6
+
+const (
+	lockName       = "lock"
+	stateFile      = "state.json"
+	recordsPattern = "commits-*.jsonl"
+)
+
+// isoDate is the layout of git's %aI dates
+const isoDate = "2006-01-02T15:04:05-07:00"
+
+// state is the metadata of a cache
+type state struct {
+	Version     int               `json:"version"`
+	Fingerprint string            `json:"fingerprint"`
+	Records     string            `json:"records"` // name of the records file
+	Tips        map[string]string `json:"tips"`    // ref -> last processed commit
+}
+
+// record locates a stored commit in the records file
+type record struct {
+	offset int64
+	size   int
+}
+
+// Store holds parsed commits keyed by hash, and the tip of every ref whose
+// whole history has been stored. Commits are appended to a JSON lines file
+// and only their offsets are kept in memory; the tips are rewritten
+// atomically, so an interrupted run leaves at worst records that are
+// stored again.
+//
+// Open locks the cache against every other writer, other processes
+// included, until Unlock or Close. Reading needs no lock: stored records
+// never change, and Reset moves on to a new records file while runs still
+// reading the old one keep it open.
+type Store struct {
+	dir     string
+	lock    *os.File // nil once unlocked
+	state   state
+	index   map[string]record
+	records *os.File // records file open for reading
+	size    int64    // bytes of the records file indexed
+}
+
+// Open loads the cache in dir, creating it if needed, once no other run
+// is writing to it. A cache written by another version is discarded.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create commit cache: %w", err)
+	}
+
+	lock, err := os.OpenFile(filepath.Join(dir, lockName), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock commit cache: %w", err)
+	}
+	if err := lockFile(lock); err != nil {
+		lock.Close()
+		return nil, fmt.Errorf("failed to lock commit cache: %w", err)
+	}
+
+	s := &Store{dir: dir, lock: lock}
+	if err := s.open(); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// open reads the state and indexes the records, starting over when they
+// were written by another version or are damaged
+func (s *Store) open() error {
+	data, err := os.ReadFile(filepath.Join(s.dir, stateFile))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return s.Reset("")
+	case err != nil:
+		return fmt.Errorf("failed to read commit cache: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s.state); err != nil || s.state.Version != version || s.state.Records == "" {
+		return s.Reset("")
+	}
+
+	if err := s.load(); errors.Is(err, errDamaged) {
+		return s.Reset(s.state.Fingerprint)
+	} else if err != nil {
+		return err
+	}
+
+	s.prune()
+	return nil
+}
+
+// errDamaged is returned by load when a record can't be decoded, e.g.
+// after an interrupted write
+var errDamaged = errors.New("damaged commit cache")
+
+// load indexes the stored commits by hash
+func (s *Store) load() error {
+	s.index = make(map[string]record)
+	s.size = 0
+
+	f, err := os.Open(s.recordsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read commit cache: %w", err)
+	}
+	s.records = f
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				return errDamaged
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read commit cache: %w", err)
+		}
+
+		var key struct{ Hash string }
+		if err := json.Unmarshal(line, &key); err != nil || key.Hash == "" {
+			return errDamaged
+		}
+
+		s.index[key.Hash] = record{offset: s.size, size: len(line)}
+		s.size += int64(len(line))
+	}
+}
+
+// recordsPath returns the path of the records file
+func (s *Store) recordsPath() string {
+	return filepath.Join(s.dir, filepath.Base(s.state.Records))
+}
+
+// prune removes the records files left by earlier Resets. Files still open
+// for reading elsewhere stay readable, or where they can't be removed while
+// open, are removed by a later run.
+func (s *Store) prune() {
+	names, _ := filepath.Glob(filepath.Join(s.dir, "commits*.jsonl"))
+	for _, name := range names {
+		if filepath.Base(name) != s.state.Records {
+			os.Remove(name)
+		}
+	}
+}
+
+// errUnlocked is returned when writing to a store after Unlock
+var errUnlocked = errors.New("commit cache is not locked for writing")
+
+// Fingerprint returns the value recorded by Reset
+func (s *Store) Fingerprint() string {
+	return s.state.Fingerprint
+}
+
+// Reset empties the cache and records fingerprint, an opaque value
+// describing anything besides history the records depend on
+func (s *Store) Reset(fingerprint string) error {
+	if s.lock == nil {
+		return errUnlocked
+	}
+
+	if s.records != nil {
+		s.records.Close()
+		s.records = nil
+	}
+
+	f, err := os.CreateTemp(s.dir, recordsPattern)
+	if err != nil {
+		return fmt.Errorf("failed to reset commit cache: %w", err)
+	}
+	s.records = f
+
+	s.state = state{Version: version, Fingerprint: fingerprint, Records: filepath.Base(f.Name()), Tips: make(map[string]string)}
+	s.index = make(map[string]record)
+	s.size = 0
+
+	if err := s.save(); err != nil {
+		return err
+	}
+
+	s.prune()
+	return nil
+}
+
+// Get reads the stored commit with the given hash
+func (s *Store) Get(hash string) (parse.CommitInfo, bool, error) {
+	rec, ok := s.index[hash]
+	if !ok {
+		return parse.CommitInfo{}, false, nil
+	}
+
+	data := make([]byte, rec.size)
+	if _, err := s.records.ReadAt(data, rec.offset); err != nil {
+		return parse.CommitInfo{}, false, fmt.Errorf("failed to read commit cache: %w", err)
+	}
+
+	var commit parse.CommitInfo
+	if err := json.Unmarshal(data, &commit); err != nil {
+		return parse.CommitInfo{}, false, fmt.Errorf("failed to read commit %s from the commit cache: %w", hash, err)
+	}
+
+	// JSON writes UTC offsets as Z, parse them again the way git's
+	// ISO dates were so the time zones compare equal
+	commit.Date, _ = time.Parse(isoDate, commit.Date.Format(isoDate))
+	commit.AuthorDate, _ = time.Parse(isoDate, commit.AuthorDate.Format(isoDate))
+	commit.CommitDate, _ = time.Parse(isoDate, commit.CommitDate.Format(isoDate))
+
+	return commit, true, nil
+}
+
+// Len returns the number of stored commits
+func (s *Store) Len() int {
+	return len(s.index)
+}
+
+// Add stores commits, skipping the ones already present
+func (s *Store) Add(commits []parse.CommitInfo) error {
+	if s.lock == nil {
+		return errUnlocked
+	}
+
+	f, err := os.OpenFile(s.recordsPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write commit cache: %w", err)
+	}
+
+	w := bufio.NewWriter(f)
+	added := make(map[string]record)
+	size := s.size
+
+	for _, commit := range commits {
+		if _, ok := s.index[commit.Hash]; ok {
+			continue
+		}
+		if _, ok := added[commit.Hash]; ok {
+			continue
+		}
+
+		data, err := json.Marshal(commit)
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write commit cache: %w", err)
+		}
+		data = append(data, '\n')
+
+		if _, err := w.Write(data); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write commit cache: %w", err)
+		}
+
+		added[commit.Hash] = record{offset: size, size: len(data)}
+		size += int64(len(data))
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write commit cache: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write commit cache: %w", err)
+	}
+
+	if s.records == nil {
+		if s.records, err = os.Open(s.recordsPath()); err != nil {
+			return fmt.Errorf("failed to read commit cache: %w", err)
+		}
+	}
+
+	for hash, rec := range added {
+		s.index[hash] = rec
+	}
+	s.size = size
+
+	return nil
+}
+
+// Tips returns the last processed commit of every ref
+func (s *Store) Tips() map[string]string {
+	return s.state.Tips
+}
+
+// SetTip records that the whole history of ref up to hash is stored
+func (s *Store) SetTip(ref, hash string) error {
+	if s.lock == nil {
+		return errUnlocked
+	}
+
+	s.state.Tips[ref] = hash
+	return s.save()
+}
+
+// Unlock lets other runs write to the cache. The commits stored so far can
+// still be read, but no more can be added.
+func (s *Store) Unlock() error {
+	if s.lock == nil {
+		return nil
+	}
+
+	err := unlockFile(s.lock)
+	if cerr := s.lock.Close(); err == nil {
+		err = cerr
+	}
+	s.lock = nil
+
+	if err != nil {
+		return fmt.Errorf("failed to unlock commit cache: %w", err)
+	}
+	return nil
+}
+
+// Close unlocks the cache and releases the records file
+func (s *Store) Close() error {
+	err := s.Unlock()
+
+	if s.records != nil {
+		s.records.Close()
+		s.records = nil
+	}
+
+	return err
+}
+
+// save writes the state next to the records and moves it in place
+func (s *Store) save() error {
+	data, err := json.Marshal(s.state)
+	if err != nil {
+		return fmt.Errorf("failed to write commit cache: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, stateFile+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write commit cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write commit cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write commit cache: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, stateFile)); err != nil {
+		return fmt.Errorf("failed to write commit cache: %w", err)
+	}
+
+	return nil
+}
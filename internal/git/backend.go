@@ -1,6 +1,10 @@
 package git
 
-import "time"
+import (
+	"context"
+	"io"
+	"time"
+)
 
 // Backend defines the interface for Git operations
 // This allows for multiple implementations (exec, go-git, mocks)
@@ -11,6 +15,10 @@ type Backend interface {
 	// LogPretty executes git log with a custom pretty format
 	LogPretty(format string, args ...string) (string, error)
 
+	// LogStream executes git log and returns its output incrementally.
+	// The caller must Close the reader; closing before EOF stops git.
+	LogStream(args ...string) (io.ReadCloser, error)
+
 	// Branches lists branches
 	Branches(args ...string) (string, error)
 
@@ -32,26 +40,102 @@ type Backend interface {
 	// Shortlog summarizes git log output
 	Shortlog(args ...string) (string, error)
 
+	// Blame shows the commit that last changed each line of a file
+	Blame(args ...string) (string, error)
+
+	// LsTree lists the contents of a tree object
+	LsTree(args ...string) (string, error)
+
+	// RevParse queries repository paths and state, like git rev-parse
+	RevParse(args ...string) (string, error)
+
+	// Objects reads the named objects like git cat-file --batch: their
+	// type and size, and their content when no larger than maxContent
+	// bytes. Missing objects are left out of the result.
+	Objects(names []string, maxContent int64) (map[string]Object, error)
+
 	// CurrentBranch returns the current branch name
 	CurrentBranch() (string, error)
 
+	// WithContext returns a copy of the backend whose commands are
+	// cancelled when ctx is done
+	WithContext(ctx context.Context) Backend
+
 	// RootPath returns the repository root path
 	RootPath() string
 }
 
 // LogOptions provides structured options for git log queries
 type LogOptions struct {
-	Since         time.Time
-	Until         time.Time
-	Author        string
-	Format        string
-	Branch        string
-	PathSpec      []string
-	NoMerges      bool
-	MergesOnly    bool
-	Limit         int
+	Since      time.Time
+	Until      time.Time
+	Author     string
+	Format     string
+	Branch     string
+	Exclude    string // leaves out the commits reachable from it, as in Exclude..Branch
+	PathSpec   []string
+	NoMerges   bool
+	MergesOnly bool
+	Limit      int
+	ExtraArgs  []string
+
+	// Identity unification applied by the analyzers, not passed to git
+	IdentityAliases map[string]string // email or name -> canonical email
+	MergeNoreply    bool              // merge GitHub noreply addresses
+
+	// Author exclusion applied by the analyzers: regexes matched against
+	// author names and emails, plus the built-in bot list when IgnoreBots
 	IgnoreAuthors []string
-	ExtraArgs     []string
+	IgnoreBots    bool
+
+	// Rename detection of per-file changes: the similarity percentage a
+	// rename needs (0 = git's default of 50%), and whether copies are
+	// detected as well
+	RenameThreshold int
+	FindCopies      bool
+
+	// Co-author attribution applied by the author and temporal analyzers:
+	// whether the identities of Co-authored-by trailers are credited with
+	// the commit too, and how its lines are split between them
+	CoAuthors     bool
+	CoAuthorSplit string // SplitEven (default), SplitFull or SplitAuthor
+
+	// Date the analyzers and range filters use: DateAuthor (default) or
+	// DateCommitter
+	DateSource string
+
+	// How far an author date may run ahead of its committer date: with
+	// DateAuthor, commits committed more than this before Since aren't
+	// read (0 = one day)
+	MaxDateSkew time.Duration
+
+	// Time zone the analyzers bucket dates in, nil for the time zone each
+	// commit was made in
+	Location *time.Location
+
+	// Directory of the persistent commit cache, empty to read every
+	// commit from git
+	CacheDir string
+}
+
+// Dates of a commit selectable with LogOptions.DateSource
+const (
+	DateAuthor    = "author"    // when the change was originally written
+	DateCommitter = "committer" // when it was last committed, e.g. rebased or cherry-picked
+)
+
+// Line splits between the author and the co-authors of a commit
+const (
+	SplitEven   = "even"   // divided evenly, the remainder to the author
+	SplitFull   = "full"   // everyone is credited with every line
+	SplitAuthor = "author" // the author keeps the lines
+)
+
+// Object describes an object read by Backend.Objects
+type Object struct {
+	Type    string // blob, tree, commit or tag
+	Size    int64
+	Content []byte // nil when larger than the requested maximum
 }
 
 // BranchInfo represents branch information
@@ -0,0 +1,95 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+)
+
+// mirrorRefSpecs keep every branch and tag of the remote under the same
+// name, like a mirror, so the bare clone can be analyzed as the original
+var mirrorRefSpecs = []string{
+	"+refs/heads/*:refs/heads/*",
+	"+refs/tags/*:refs/tags/*",
+}
+
+// CloneBare creates a bare clone of url in dir with every branch and tag.
+// The git CLI is used unless native is set, then go-git. A non-empty
+// filter, such as blob:none, makes a partial clone whose missing objects
+// git fetches on demand; go-git can't read those.
+func CloneBare(ctx context.Context, url, dir, filter string, native bool) error {
+	if native {
+		if filter != "" {
+			return fmt.Errorf("%w: clone --filter", ErrUnsupported)
+		}
+
+		if _, err := gogit.PlainCloneContext(ctx, dir, true, &gogit.CloneOptions{URL: url}); err != nil {
+			return fmt.Errorf("failed to clone %s with go-git: %w", url, err)
+		}
+		return FetchBare(ctx, dir, true)
+	}
+
+	args := []string{"clone", "--bare", "--quiet"}
+	if filter != "" {
+		args = append(args, "--filter="+filter)
+	}
+
+	if err := runGit(ctx, "", append(args, url, dir)...); err != nil {
+		return err
+	}
+	return FetchBare(ctx, dir, false)
+}
+
+// FetchBare updates the branches and tags of the bare clone in dir from
+// its origin, dropping the ones deleted upstream
+func FetchBare(ctx context.Context, dir string, native bool) error {
+	if native {
+		repo, err := gogit.PlainOpen(dir)
+		if err != nil {
+			return fmt.Errorf("failed to open %s with go-git: %w", dir, err)
+		}
+
+		specs := make([]config.RefSpec, len(mirrorRefSpecs))
+		for i, spec := range mirrorRefSpecs {
+			specs[i] = config.RefSpec(spec)
+		}
+
+		err = repo.FetchContext(ctx, &gogit.FetchOptions{
+			RemoteName: "origin",
+			RefSpecs:   specs,
+			Prune:      true,
+			Force:      true,
+		})
+		if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+			return fmt.Errorf("failed to fetch %s with go-git: %w", dir, err)
+		}
+		return nil
+	}
+
+	args := append([]string{"fetch", "--quiet", "--prune", "origin"}, mirrorRefSpecs...)
+	return runGit(ctx, dir, args...)
+}
+
+// runGit runs a git command that isn't bound to a backend
+func runGit(ctx context.Context, dir string, args ...string) error {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		return fmt.Errorf("git binary not found: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, gitPath, args...)
+	cmd.Dir = dir
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("git %s: %w", args[0], ctxErr)
+		}
+		return fmt.Errorf("git %s failed: %w\nstderr: %s", args[0], err, out)
+	}
+
+	return nil
+}
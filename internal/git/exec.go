@@ -2,15 +2,20 @@ package git
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 // ExecBackend implements Backend using the git CLI
 type ExecBackend struct {
 	repoPath string
 	gitPath  string
+	ctx      context.Context
 }
 
 // NewExecBackend creates a new exec-based backend
@@ -30,19 +35,36 @@ func NewExecBackend(repoPath string) (*ExecBackend, error) {
 	return &ExecBackend{
 		repoPath: repoPath,
 		gitPath:  gitPath,
+		ctx:      context.Background(),
 	}, nil
 }
 
+// WithContext returns a copy of the backend bound to ctx
+func (b *ExecBackend) WithContext(ctx context.Context) Backend {
+	clone := *b
+	clone.ctx = ctx
+	return &clone
+}
+
 // runGit executes a git command and returns the output
 func (b *ExecBackend) runGit(args ...string) (string, error) {
+	return b.runGitInput("", args...)
+}
+
+// runGitInput executes a git command reading input on stdin
+func (b *ExecBackend) runGitInput(input string, args ...string) (string, error) {
 	var stdout, stderr bytes.Buffer
 
-	cmd := exec.Command(b.gitPath, args...)
+	cmd := exec.CommandContext(b.ctx, b.gitPath, args...)
 	cmd.Dir = b.repoPath
+	cmd.Stdin = strings.NewReader(input)
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
+		if ctxErr := b.ctx.Err(); ctxErr != nil {
+			return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), ctxErr)
+		}
 		return "", fmt.Errorf("git %s failed: %w\nstderr: %s", strings.Join(args, " "), err, stderr.String())
 	}
 
@@ -55,6 +77,84 @@ func (b *ExecBackend) Log(args ...string) (string, error) {
 	return b.runGit(fullArgs...)
 }
 
+// LogStream executes git log and streams its stdout
+func (b *ExecBackend) LogStream(args ...string) (io.ReadCloser, error) {
+	fullArgs := append([]string{"log"}, args...)
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(b.ctx, b.gitPath, fullArgs...)
+	cmd.Dir = b.repoPath
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("git %s failed: %w", strings.Join(fullArgs, " "), err)
+	}
+
+	return &cmdReader{ctx: b.ctx, stdout: stdout, cmd: cmd, stderr: &stderr, args: fullArgs}, nil
+}
+
+// cmdReader streams the stdout of a running git command. The exit status
+// is reported by Read once the output is exhausted.
+type cmdReader struct {
+	ctx    context.Context
+	stdout io.ReadCloser
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+	args   []string
+
+	once    sync.Once
+	waitErr error
+	done    bool
+}
+
+// Read reads from the command output
+func (r *cmdReader) Read(p []byte) (int, error) {
+	if r.done {
+		if err := r.wait(); err != nil {
+			return 0, err
+		}
+		return 0, io.EOF
+	}
+
+	n, err := r.stdout.Read(p)
+	if err == io.EOF {
+		r.done = true
+		if werr := r.wait(); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// Close stops the command if its output wasn't fully read
+func (r *cmdReader) Close() error {
+	if !r.done {
+		_ = r.cmd.Process.Kill()
+		_ = r.wait()
+		return nil
+	}
+	return r.wait()
+}
+
+func (r *cmdReader) wait() error {
+	r.once.Do(func() {
+		if err := r.cmd.Wait(); err != nil {
+			if ctxErr := r.ctx.Err(); ctxErr != nil {
+				r.waitErr = fmt.Errorf("git %s: %w", strings.Join(r.args, " "), ctxErr)
+				return
+			}
+			r.waitErr = fmt.Errorf("git %s failed: %w\nstderr: %s", strings.Join(r.args, " "), err, r.stderr.String())
+		}
+	})
+	return r.waitErr
+}
+
 // LogPretty executes git log with a custom pretty format
 func (b *ExecBackend) LogPretty(format string, args ...string) (string, error) {
 	fullArgs := append([]string{"log", "--pretty=format:" + format}, args...)
@@ -103,6 +203,87 @@ func (b *ExecBackend) Shortlog(args ...string) (string, error) {
 	return b.runGit(fullArgs...)
 }
 
+// Blame shows the commit that last changed each line of a file
+func (b *ExecBackend) Blame(args ...string) (string, error) {
+	fullArgs := append([]string{"blame"}, args...)
+	return b.runGit(fullArgs...)
+}
+
+// LsTree lists the contents of a tree object
+func (b *ExecBackend) LsTree(args ...string) (string, error) {
+	fullArgs := append([]string{"ls-tree"}, args...)
+	return b.runGit(fullArgs...)
+}
+
+// RevParse queries repository paths and state
+func (b *ExecBackend) RevParse(args ...string) (string, error) {
+	fullArgs := append([]string{"rev-parse"}, args...)
+	return b.runGit(fullArgs...)
+}
+
+// Objects reads objects with git cat-file. Sizes of every object come from
+// a single --batch-check run, contents of the small ones from --batch.
+func (b *ExecBackend) Objects(names []string, maxContent int64) (map[string]Object, error) {
+	objects := make(map[string]Object, len(names))
+	if len(names) == 0 {
+		return objects, nil
+	}
+
+	output, err := b.runGitInput(strings.Join(names, "\n")+"\n", "cat-file", "--batch-check=%(objecttype) %(objectsize)")
+	if err != nil {
+		return nil, err
+	}
+
+	var small []string
+
+	for i, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		if i >= len(names) {
+			break
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[1] == "missing" {
+			continue // "<name> missing"
+		}
+
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cat-file output %q", line)
+		}
+
+		objects[names[i]] = Object{Type: fields[0], Size: size}
+		if size <= maxContent {
+			small = append(small, names[i])
+		}
+	}
+
+	if len(small) == 0 {
+		return objects, nil
+	}
+
+	output, err = b.runGitInput(strings.Join(small, "\n")+"\n", "cat-file", "--batch=%(objectsize)")
+	if err != nil {
+		return nil, err
+	}
+
+	// Each object is "<size>\n<content>\n"
+	for _, name := range small {
+		header, rest, ok := strings.Cut(output, "\n")
+		size, err := strconv.Atoi(header)
+		if !ok || err != nil || size > len(rest) {
+			return nil, fmt.Errorf("invalid cat-file output for %s", name)
+		}
+
+		object := objects[name]
+		object.Content = []byte(rest[:size])
+		objects[name] = object
+
+		output = strings.TrimPrefix(rest[size:], "\n")
+	}
+
+	return objects, nil
+}
+
 // CurrentBranch returns the current branch name
 func (b *ExecBackend) CurrentBranch() (string, error) {
 	output, err := b.runGit("rev-parse", "--abbrev-ref", "HEAD")
@@ -117,16 +298,20 @@ func (b *ExecBackend) RootPath() string {
 	return b.repoPath
 }
 
+// logDateFormat is the date format of --since and --until, understood by
+// git and by the go-git backend
+const logDateFormat = "2006-01-02 15:04:05 -0700"
+
 // BuildLogArgs builds git log arguments from LogOptions
 func BuildLogArgs(opts *LogOptions) []string {
 	args := []string{}
 
-	// Date range
+	// Date range, inclusive and to the second
 	if !opts.Since.IsZero() {
-		args = append(args, fmt.Sprintf("--since=%s", opts.Since.Format("2006-01-02")))
+		args = append(args, fmt.Sprintf("--since=%s", opts.Since.Format(logDateFormat)))
 	}
 	if !opts.Until.IsZero() {
-		args = append(args, fmt.Sprintf("--until=%s", opts.Until.Format("2006-01-02")))
+		args = append(args, fmt.Sprintf("--until=%s", opts.Until.Format(logDateFormat)))
 	}
 
 	// Author filter
@@ -155,6 +340,12 @@ func BuildLogArgs(opts *LogOptions) []string {
 	if opts.Branch != "" {
 		args = append(args, opts.Branch)
 	}
+	if opts.Exclude != "" {
+		if opts.Branch == "" {
+			args = append(args, "HEAD")
+		}
+		args = append(args, "^"+opts.Exclude)
+	}
 
 	// Extra arguments
 	args = append(args, opts.ExtraArgs...)
@@ -167,3 +358,21 @@ func BuildLogArgs(opts *LogOptions) []string {
 
 	return args
 }
+
+// BuildDiffArgs converts the rename detection settings of LogOptions to
+// the git log arguments that apply to per-file changes
+func BuildDiffArgs(opts *LogOptions) []string {
+	var args []string
+
+	similarity := ""
+	if opts.RenameThreshold > 0 {
+		similarity = fmt.Sprintf("%d%%", opts.RenameThreshold)
+		args = append(args, "-M"+similarity)
+	}
+
+	if opts.FindCopies {
+		args = append(args, "-C"+similarity)
+	}
+
+	return args
+}
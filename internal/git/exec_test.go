@@ -1,6 +1,7 @@
 package git
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -147,7 +148,22 @@ func TestBuildLogArgs(t *testing.T) {
 				Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
 				Until: time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC),
 			},
-			want: []string{"--since=2024-01-01", "--until=2024-12-31"},
+			want: []string{"--since=2024-01-01 00:00:00 +0000", "--until=2024-12-31 23:59:59 +0000"},
+		},
+		{
+			name: "with precise times in a time zone",
+			opts: &LogOptions{
+				Since: time.Date(2024, 3, 1, 9, 30, 0, 0, time.FixedZone("", 2*60*60)),
+				Until: time.Date(2024, 3, 1, 17, 0, 0, 0, time.FixedZone("", 2*60*60)),
+			},
+			want: []string{"--since=2024-03-01 09:30:00 +0200", "--until=2024-03-01 17:00:00 +0200"},
+		},
+		{
+			name: "with midnight as until",
+			opts: &LogOptions{
+				Until: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			},
+			want: []string{"--until=2024-03-01 00:00:00 +0000"},
 		},
 		{
 			name: "with author",
@@ -184,6 +200,21 @@ func TestBuildLogArgs(t *testing.T) {
 			},
 			want: []string{"main"},
 		},
+		{
+			name: "with excluded branch",
+			opts: &LogOptions{
+				Branch:  "release",
+				Exclude: "main",
+			},
+			want: []string{"release", "^main"},
+		},
+		{
+			name: "with excluded branch only",
+			opts: &LogOptions{
+				Exclude: "main",
+			},
+			want: []string{"HEAD", "^main"},
+		},
 		{
 			name: "with pathspec",
 			opts: &LogOptions{
@@ -302,3 +333,24 @@ func BenchmarkBuildLogArgs(b *testing.B) {
 		_ = BuildLogArgs(opts)
 	}
 }
+
+func TestBuildDiffArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *LogOptions
+		want []string
+	}{
+		{"defaults", &LogOptions{}, nil},
+		{"threshold", &LogOptions{RenameThreshold: 80}, []string{"-M80%"}},
+		{"copies", &LogOptions{FindCopies: true}, []string{"-C"}},
+		{"copies with threshold", &LogOptions{RenameThreshold: 70, FindCopies: true}, []string{"-M70%", "-C70%"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildDiffArgs(tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildDiffArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
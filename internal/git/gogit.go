@@ -0,0 +1,1144 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// ErrUnsupported is returned when GoGitBackend is asked for a git feature
+// it does not emulate
+var ErrUnsupported = errors.New("unsupported by go-git backend")
+
+// GoGitBackend implements Backend natively with go-git, so no git binary
+// is required. It understands the subset of git arguments used by the
+// analyzers and rejects anything else with ErrUnsupported. --numstat
+// counts come from go-git's line diff rather than git's, so they can be a
+// few lines apart where a file was heavily rewritten.
+type GoGitBackend struct {
+	repoPath string
+	repo     *gogit.Repository
+	ctx      context.Context
+	mailmap  *Mailmap
+
+	// go-git repositories are not safe for concurrent use: calls, and the
+	// walks of streams between two writes, take turns on mu, shared with
+	// the copies made by WithContext
+	mu *sync.Mutex
+}
+
+// NewGoGitBackend creates a new go-git based backend
+func NewGoGitBackend(repoPath string) (*GoGitBackend, error) {
+	repo, err := openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository with go-git: %w", err)
+	}
+
+	return &GoGitBackend{
+		repoPath: repoPath,
+		repo:     repo,
+		ctx:      context.Background(),
+		mailmap:  loadMailmap(repo),
+		mu:       &sync.Mutex{},
+	}, nil
+}
+
+// openRepository opens the repository containing path. go-git only
+// searches parent directories for a .git entry, so git directories, bare
+// or not, are looked for here and opened as they are.
+func openRepository(path string) (*gogit.Repository, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, gogit.GitDirName)); err == nil {
+			break
+		}
+
+		if IsGitDir(dir) {
+			return gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{EnableDotGitCommonDir: true})
+		}
+
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	return gogit.PlainOpenWithOptions(abs, &gogit.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+}
+
+// loadMailmap reads .mailmap from the working tree, or from HEAD in bare
+// repositories like git's mailmap.blob default. A missing file yields an
+// empty mailmap.
+func loadMailmap(repo *gogit.Repository) *Mailmap {
+	if wt, err := repo.Worktree(); err == nil {
+		f, err := wt.Filesystem.Open(".mailmap")
+		if err != nil {
+			return ParseMailmap("")
+		}
+		defer f.Close()
+
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return ParseMailmap("")
+		}
+		return ParseMailmap(string(data))
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return ParseMailmap("")
+	}
+
+	c, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return ParseMailmap("")
+	}
+
+	file, err := c.File(".mailmap")
+	if err != nil {
+		return ParseMailmap("")
+	}
+
+	data, err := file.Contents()
+	if err != nil {
+		return ParseMailmap("")
+	}
+
+	return ParseMailmap(data)
+}
+
+// WithContext returns a copy of the backend bound to ctx
+func (b *GoGitBackend) WithContext(ctx context.Context) Backend {
+	clone := *b
+	clone.ctx = ctx
+	return &clone
+}
+
+// Log emulates git log with the given arguments
+func (b *GoGitBackend) Log(args ...string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, err := parseRevQuery(args)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := b.renderLog(b.ctx, &sb, q); err != nil {
+		return "", fmt.Errorf("log failed: %w", err)
+	}
+
+	return sb.String(), nil
+}
+
+// LogStream emulates git log, producing output while the history is walked
+func (b *GoGitBackend) LogStream(args ...string) (io.ReadCloser, error) {
+	q, err := parseRevQuery(args)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(b.ctx)
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		b.mu.Lock()
+		err := b.renderLog(ctx, &unlockedWriter{w: pw, mu: b.mu}, q)
+		b.mu.Unlock()
+
+		if err != nil {
+			err = fmt.Errorf("log failed: %w", err)
+		}
+		_ = pw.CloseWithError(err)
+	}()
+
+	return &pipeReader{PipeReader: pr, cancel: cancel, done: done}, nil
+}
+
+// unlockedWriter releases mu while writing, so the reader of a stream can
+// make other calls while the walk waits for it
+type unlockedWriter struct {
+	w  io.Writer
+	mu *sync.Mutex
+}
+
+func (u *unlockedWriter) Write(p []byte) (int, error) {
+	u.mu.Unlock()
+	defer u.mu.Lock()
+	return u.w.Write(p)
+}
+
+// pipeReader stops the producing walk when closed
+type pipeReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Close stops the walk and releases the pipe once the walk has returned
+func (r *pipeReader) Close() error {
+	r.cancel()
+	err := r.PipeReader.Close()
+	<-r.done
+	return err
+}
+
+// LogPretty emulates git log with a custom pretty format
+func (b *GoGitBackend) LogPretty(format string, args ...string) (string, error) {
+	return b.Log(append([]string{"--pretty=format:" + format}, args...)...)
+}
+
+// renderLog writes git log output for q to w
+func (b *GoGitBackend) renderLog(ctx context.Context, w io.Writer, q *revQuery) error {
+	if !validDateMode(q.dateMode) {
+		return fmt.Errorf("%w: --date=%s", ErrUnsupported, q.dateMode)
+	}
+
+	if q.graph && q.style != "oneline" {
+		return fmt.Errorf("%w: --graph without --oneline", ErrUnsupported)
+	}
+
+	fc := &formatContext{dateMode: q.dateMode, mailmap: b.mailmap, noAbbrev: q.noAbbrev}
+	if q.decorate || strings.Contains(q.format, "%d") || strings.Contains(q.format, "%D") {
+		decorations, err := b.decorations()
+		if err != nil {
+			return err
+		}
+		fc.decorations = decorations
+	}
+
+	term := q.lineTerm()
+	first := true
+
+	return b.walk(ctx, q, func(c *object.Commit, changes []fileChange) error {
+		var sb strings.Builder
+		hasDiff := len(changes) > 0
+
+		switch q.style {
+		case "format":
+			if !first {
+				sb.WriteString(term)
+			}
+			sb.WriteString(expandFormat(q.format, c, fc))
+			if hasDiff {
+				sb.WriteString("\n")
+			}
+		case "tformat":
+			sb.WriteString(expandFormat(q.format, c, fc) + term)
+			if hasDiff {
+				sb.WriteString("\n")
+			}
+		case "medium":
+			if !first {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(renderMedium(c, fc))
+			if hasDiff {
+				sb.WriteString("\n")
+			}
+		case "oneline":
+			if q.graph {
+				sb.WriteString("* ")
+			}
+			sb.WriteString(renderOneline(c, fc) + "\n")
+		}
+
+		renderChanges(&sb, q, changes)
+		first = false
+
+		_, err := io.WriteString(w, sb.String())
+		return err
+	})
+}
+
+// Branches emulates git branch
+func (b *GoGitBackend) Branches(args ...string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	local, remote := true, false
+
+	for _, arg := range args {
+		switch arg {
+		case "-a", "--all":
+			remote = true
+		case "-r", "--remotes":
+			local, remote = false, true
+		case "--list", "-l":
+		default:
+			return "", fmt.Errorf("%w: branch %s", ErrUnsupported, arg)
+		}
+	}
+
+	head, _ := b.repo.Head()
+
+	refs, err := b.repo.References()
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	detached := head != nil && !head.Name().IsBranch()
+
+	if detached && local {
+		lines = append(lines, "* (HEAD detached at "+abbrev(head.Hash())+")")
+	}
+
+	var names []string
+	symbolic := make(map[string]string)
+
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		switch {
+		case name.IsBranch() && local:
+			names = append(names, name.String())
+		case name.IsRemote() && remote:
+			names = append(names, name.String())
+			if ref.Type() == plumbing.SymbolicReference {
+				symbolic[name.String()] = ref.Target().Short()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		ref := plumbing.ReferenceName(name)
+		short := ref.Short()
+		if ref.IsRemote() && local {
+			short = "remotes/" + short
+		}
+
+		prefix := "  "
+		if head != nil && !detached && head.Name() == ref {
+			prefix = "* "
+		}
+
+		line := prefix + short
+		if target, ok := symbolic[name]; ok {
+			line += " -> " + target
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// Tags emulates git tag listing
+func (b *GoGitBackend) Tags(args ...string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var patterns []string
+
+	for _, arg := range args {
+		switch {
+		case arg == "-l" || arg == "--list":
+		case strings.HasPrefix(arg, "-"):
+			return "", fmt.Errorf("%w: tag %s", ErrUnsupported, arg)
+		default:
+			patterns = append(patterns, arg)
+		}
+	}
+
+	iter, err := b.repo.Tags()
+	if err != nil {
+		return "", err
+	}
+
+	var names []string
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		if len(patterns) == 0 {
+			names = append(names, name)
+			return nil
+		}
+		for _, p := range patterns {
+			if re, err := globToRegexp(p); err == nil && re.MatchString(name) {
+				names = append(names, name)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(names) == 0 {
+		return "", nil
+	}
+
+	sort.Strings(names)
+	return strings.Join(names, "\n") + "\n", nil
+}
+
+// Diff emulates git diff between two revisions. Only --numstat and
+// --name-only output are supported.
+func (b *GoGitBackend) Diff(args ...string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, err := parseRevQuery(args)
+	if err != nil {
+		return "", err
+	}
+
+	if !q.numstat && !q.nameOnly {
+		return "", fmt.Errorf("%w: diff without --numstat or --name-only", ErrUnsupported)
+	}
+
+	revs := append(append([]string{}, q.exclude...), q.include...)
+	if len(revs) != 2 {
+		return "", fmt.Errorf("%w: diff requires exactly two revisions", ErrUnsupported)
+	}
+
+	ctx := b.ctx
+	trees := make([]*object.Tree, 2)
+
+	for i, rev := range revs {
+		c, err := b.resolveCommit(rev)
+		if err != nil {
+			return "", err
+		}
+		if trees[i], err = c.Tree(); err != nil {
+			return "", err
+		}
+	}
+
+	changes, err := b.diffTrees(ctx, trees[0], trees[1], q, q.numstat)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	renderChanges(&sb, q, changes)
+
+	return sb.String(), nil
+}
+
+// Show emulates git show for "rev:path" blobs and for commits with
+// --no-patch / -s
+func (b *GoGitBackend) Show(args ...string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var rest []string
+	noPatch := false
+
+	for _, arg := range args {
+		switch {
+		case arg == "-s" || arg == "--no-patch":
+			noPatch = true
+		case !strings.HasPrefix(arg, "-") && strings.Contains(arg, ":"):
+			return b.showBlob(arg)
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	q, err := parseRevQuery(rest)
+	if err != nil {
+		return "", err
+	}
+
+	if !noPatch && !q.numstat && !q.nameOnly {
+		return "", fmt.Errorf("%w: show with patch output", ErrUnsupported)
+	}
+
+	if len(q.include) == 0 {
+		q.include = []string{"HEAD"}
+	}
+
+	var sb strings.Builder
+
+	for _, rev := range q.include {
+		single := *q
+		single.include = []string{rev}
+		single.maxCount = 1
+
+		if single.style == "format" {
+			single.style = "tformat"
+		}
+
+		if err := b.renderLog(b.ctx, &sb, &single); err != nil {
+			return "", err
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// showBlob returns the contents of a "rev:path" blob
+func (b *GoGitBackend) showBlob(spec string) (string, error) {
+	rev, path, _ := strings.Cut(spec, ":")
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	c, err := b.resolveCommit(rev)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := c.File(path)
+	if err != nil {
+		return "", fmt.Errorf("path '%s' does not exist in '%s': %w", path, rev, err)
+	}
+
+	return file.Contents()
+}
+
+// RevList emulates git rev-list
+func (b *GoGitBackend) RevList(args ...string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, err := parseRevQuery(args)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	count := 0
+
+	err = b.walk(b.ctx, q, func(c *object.Commit, _ []fileChange) error {
+		count++
+		if q.count {
+			return nil
+		}
+
+		sb.WriteString(c.Hash.String())
+		if q.parents {
+			for _, p := range c.ParentHashes {
+				sb.WriteString(" " + p.String())
+			}
+		}
+		sb.WriteString("\n")
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("rev-list failed: %w", err)
+	}
+
+	if q.count {
+		return strconv.Itoa(count) + "\n", nil
+	}
+
+	return sb.String(), nil
+}
+
+// ForEachRef emulates git for-each-ref
+func (b *GoGitBackend) ForEachRef(args ...string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	format := "%(objectname) %(objecttype)\t%(refname)"
+	limit := -1
+	var sortKeys, patterns []string
+
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--format="):
+			format = strings.TrimPrefix(arg, "--format=")
+		case strings.HasPrefix(arg, "--sort="):
+			sortKeys = append(sortKeys, strings.TrimPrefix(arg, "--sort="))
+		case strings.HasPrefix(arg, "--count="):
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "--count="))
+			if err != nil {
+				return "", fmt.Errorf("invalid --count: %w", err)
+			}
+			limit = n
+		case strings.HasPrefix(arg, "-"):
+			return "", fmt.Errorf("%w: for-each-ref %s", ErrUnsupported, arg)
+		default:
+			patterns = append(patterns, arg)
+		}
+	}
+
+	refs, err := b.matchingRefs(patterns)
+	if err != nil {
+		return "", err
+	}
+
+	if err := sortRefs(refs, sortKeys); err != nil {
+		return "", err
+	}
+
+	if limit >= 0 && limit < len(refs) {
+		refs = refs[:limit]
+	}
+
+	var sb strings.Builder
+	for _, r := range refs {
+		line, err := expandRefFormat(format, r)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	return sb.String(), nil
+}
+
+// matchingRefs collects the references selected by for-each-ref patterns
+func (b *GoGitBackend) matchingRefs(patterns []string) ([]*refAtoms, error) {
+	iter, err := b.repo.References()
+	if err != nil {
+		return nil, err
+	}
+
+	head, _ := b.repo.Head()
+
+	var result []*refAtoms
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().String()
+		if ref.Type() != plumbing.HashReference || name == "HEAD" || !matchRefPatterns(name, patterns) {
+			return nil
+		}
+
+		atoms := &refAtoms{
+			ref:    ref,
+			target: ref.Hash(),
+			isHead: head != nil && head.Name() == ref.Name(),
+		}
+
+		if tag, err := b.repo.TagObject(ref.Hash()); err == nil {
+			atoms.tag = tag
+			atoms.objType = "tag"
+		} else if c, err := b.repo.CommitObject(ref.Hash()); err == nil {
+			atoms.commit = c
+			atoms.objType = "commit"
+		}
+
+		result = append(result, atoms)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// matchRefPatterns matches for-each-ref patterns: a pattern selects refs
+// it is a path-component prefix of, or matches as a glob
+func matchRefPatterns(name string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, p := range patterns {
+		if strings.ContainsAny(p, "*?[") {
+			if re, err := globToRegexp(p); err == nil && re.MatchString(name) {
+				return true
+			}
+			continue
+		}
+
+		p = strings.TrimSuffix(p, "/")
+		if name == p || strings.HasPrefix(name, p+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Shortlog emulates git shortlog. Like git run from a terminal, it reads
+// HEAD when no revision is given.
+func (b *GoGitBackend) Shortlog(args ...string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	summary, numbered, email := false, false, false
+	var rest []string
+
+	for _, arg := range args {
+		switch arg {
+		case "-s", "--summary":
+			summary = true
+		case "-n", "--numbered":
+			numbered = true
+		case "-e", "--email":
+			email = true
+		case "-sn", "-ns":
+			summary, numbered = true, true
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	q, err := parseRevQuery(rest)
+	if err != nil {
+		return "", err
+	}
+
+	type group struct {
+		name     string
+		subjects []string
+	}
+
+	groups := make(map[string]*group)
+
+	err = b.walk(b.ctx, q, func(c *object.Commit, _ []fileChange) error {
+		// shortlog applies the mailmap by default
+		name, mail := b.mailmap.Resolve(c.Author.Name, c.Author.Email)
+
+		key := name
+		if email {
+			key += " <" + mail + ">"
+		}
+
+		g, ok := groups[key]
+		if !ok {
+			g = &group{name: key}
+			groups[key] = g
+		}
+		g.subjects = append(g.subjects, commitSubject(c.Message))
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("shortlog failed: %w", err)
+	}
+
+	sorted := make([]*group, 0, len(groups))
+	for _, g := range groups {
+		sorted = append(sorted, g)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if numbered && len(sorted[i].subjects) != len(sorted[j].subjects) {
+			return len(sorted[i].subjects) > len(sorted[j].subjects)
+		}
+		return sorted[i].name < sorted[j].name
+	})
+
+	var sb strings.Builder
+	for _, g := range sorted {
+		if summary {
+			fmt.Fprintf(&sb, "%6d\t%s\n", len(g.subjects), g.name)
+			continue
+		}
+
+		fmt.Fprintf(&sb, "%s (%d):\n", g.name, len(g.subjects))
+		for i := len(g.subjects) - 1; i >= 0; i-- {
+			sb.WriteString("      " + g.subjects[i] + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), nil
+}
+
+// Blame emulates git blame --line-porcelain [rev] [--] path. Only the
+// author headers and the blamed filename are emitted for each line.
+func (b *GoGitBackend) Blame(args ...string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	porcelain := false
+	var revs, paths []string
+	dashdash := false
+
+	for _, arg := range args {
+		switch {
+		case dashdash:
+			paths = append(paths, arg)
+		case arg == "--":
+			dashdash = true
+		case arg == "--line-porcelain":
+			porcelain = true
+		case strings.HasPrefix(arg, "-"):
+			return "", fmt.Errorf("%w: blame %s", ErrUnsupported, arg)
+		default:
+			revs = append(revs, arg)
+		}
+	}
+
+	if !porcelain {
+		return "", fmt.Errorf("%w: blame without --line-porcelain", ErrUnsupported)
+	}
+
+	if len(paths) == 0 && len(revs) > 0 {
+		paths = revs[len(revs)-1:]
+		revs = revs[:len(revs)-1]
+	}
+	if len(paths) != 1 || len(revs) > 1 {
+		return "", fmt.Errorf("blame needs exactly one revision and path")
+	}
+
+	rev := "HEAD"
+	if len(revs) == 1 {
+		rev = revs[0]
+	}
+
+	c, err := b.resolveCommit(rev)
+	if err != nil {
+		return "", err
+	}
+
+	if err := b.ctx.Err(); err != nil {
+		return "", err
+	}
+
+	result, err := gogit.Blame(c, paths[0])
+	if err != nil {
+		return "", fmt.Errorf("blame %s failed: %w", paths[0], err)
+	}
+
+	var sb strings.Builder
+	for i, line := range result.Lines {
+		// blame applies the mailmap by default
+		name, email := b.mailmap.Resolve(line.AuthorName, line.Author)
+
+		fmt.Fprintf(&sb, "%s %d %d\n", line.Hash, i+1, i+1)
+		fmt.Fprintf(&sb, "author %s\nauthor-mail <%s>\n", name, email)
+		fmt.Fprintf(&sb, "author-time %d\nauthor-tz %s\n", line.Date.Unix(), line.Date.Format("-0700"))
+		fmt.Fprintf(&sb, "filename %s\n\t%s\n", paths[0], line.Text)
+	}
+
+	return sb.String(), nil
+}
+
+// LsTree emulates git ls-tree [-r] [--name-only] [-z] <rev> [--] [path...]
+func (b *GoGitBackend) LsTree(args ...string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	recursive, nameOnly, nul := false, false, false
+	var operands []string
+
+	for _, arg := range args {
+		switch arg {
+		case "-r":
+			recursive = true
+		case "--name-only":
+			nameOnly = true
+		case "-z":
+			nul = true
+		case "--":
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return "", fmt.Errorf("%w: ls-tree %s", ErrUnsupported, arg)
+			}
+			operands = append(operands, arg)
+		}
+	}
+
+	if len(operands) == 0 {
+		return "", fmt.Errorf("ls-tree needs a tree-ish")
+	}
+
+	c, err := b.resolveCommit(operands[0])
+	if err != nil {
+		return "", err
+	}
+
+	tree, err := c.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to read tree: %w", err)
+	}
+
+	paths := operands[1:]
+	term := "\n"
+	if nul {
+		term = "\x00"
+	}
+
+	var sb strings.Builder
+	emit := func(name string, entry object.TreeEntry) {
+		if !lsTreeMatches(name, paths) {
+			return
+		}
+
+		if !nameOnly {
+			kind := "blob"
+			switch entry.Mode {
+			case filemode.Dir:
+				kind = "tree"
+			case filemode.Submodule:
+				kind = "commit"
+			}
+			fmt.Fprintf(&sb, "%06o %s %s\t", uint32(entry.Mode), kind, entry.Hash)
+		}
+
+		sb.WriteString(name + term)
+	}
+
+	if !recursive {
+		for _, entry := range tree.Entries {
+			emit(entry.Name, entry)
+		}
+		return sb.String(), nil
+	}
+
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to walk tree: %w", err)
+		}
+
+		if entry.Mode != filemode.Dir {
+			emit(name, entry)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// lsTreeMatches reports whether name is one of paths or lies below one
+func lsTreeMatches(name string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+
+	for _, p := range paths {
+		p = strings.TrimSuffix(p, "/")
+		if p == "" || p == "." || name == p || strings.HasPrefix(name, p+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RevParse emulates the git rev-parse options describing the repository
+// layout. Paths are always printed absolute.
+func (b *GoGitBackend) RevParse(args ...string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var sb strings.Builder
+
+	for _, arg := range args {
+		switch arg {
+		case "--path-format=absolute":
+		case "--is-bare-repository":
+			cfg, err := b.repo.Config()
+			if err != nil {
+				return "", fmt.Errorf("failed to read config: %w", err)
+			}
+			sb.WriteString(strconv.FormatBool(cfg.Core.IsBare) + "\n")
+		case "--git-dir", "--absolute-git-dir":
+			sb.WriteString(b.gitDir() + "\n")
+		case "--git-common-dir":
+			sb.WriteString(b.commonDir() + "\n")
+		case "--show-toplevel":
+			wt, err := b.repo.Worktree()
+			if err != nil {
+				return "", fmt.Errorf("this operation must be run in a work tree: %w", err)
+			}
+			sb.WriteString(wt.Filesystem.Root() + "\n")
+		default:
+			return "", fmt.Errorf("%w: rev-parse %s", ErrUnsupported, arg)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// gitDir returns the git directory of the repository or linked worktree
+func (b *GoGitBackend) gitDir() string {
+	if fs, ok := b.repo.Storer.(*filesystem.Storage); ok {
+		return fs.Filesystem().Root()
+	}
+	return ""
+}
+
+// commonDir returns the git directory shared by all worktrees
+func (b *GoGitBackend) commonDir() string {
+	dir := b.gitDir()
+
+	data, err := os.ReadFile(filepath.Join(dir, "commondir"))
+	if err != nil {
+		return dir
+	}
+
+	common := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(dir, common)
+	}
+
+	return filepath.Clean(common)
+}
+
+// CurrentBranch returns the current branch name, or "HEAD" when detached
+func (b *GoGitBackend) CurrentBranch() (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	head, err := b.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	if !head.Name().IsBranch() {
+		return "HEAD", nil
+	}
+
+	return head.Name().Short(), nil
+}
+
+// Objects reads objects from the object database
+func (b *GoGitBackend) Objects(names []string, maxContent int64) (map[string]Object, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	objects := make(map[string]Object, len(names))
+	if len(names) == 0 {
+		return objects, nil
+	}
+
+	for _, name := range names {
+		if err := b.ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		hash := plumbing.NewHash(name)
+		if !plumbing.IsHash(name) {
+			resolved, err := b.repo.ResolveRevision(plumbing.Revision(name))
+			if err != nil {
+				continue
+			}
+			hash = *resolved
+		}
+
+		obj, err := b.repo.Storer.EncodedObject(plumbing.AnyObject, hash)
+		if err != nil {
+			continue
+		}
+
+		object := Object{Type: obj.Type().String(), Size: obj.Size()}
+
+		if object.Size <= maxContent {
+			r, err := obj.Reader()
+			if err != nil {
+				return nil, err
+			}
+			object.Content, err = io.ReadAll(r)
+			r.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		objects[name] = object
+	}
+
+	return objects, nil
+}
+
+// RootPath returns the repository root path
+func (b *GoGitBackend) RootPath() string {
+	return b.repoPath
+}
+
+// decorations maps commits to their ref names as shown by --decorate
+func (b *GoGitBackend) decorations() (map[plumbing.Hash][]string, error) {
+	iter, err := b.repo.References()
+	if err != nil {
+		return nil, err
+	}
+
+	head, _ := b.repo.Head()
+
+	type decoration struct {
+		label string
+		rank  int
+	}
+
+	byCommit := make(map[plumbing.Hash][]decoration)
+
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+
+		name := ref.Name()
+		var d decoration
+
+		switch {
+		case name.IsTag():
+			d = decoration{label: "tag: " + name.Short(), rank: 1}
+		case name.IsBranch():
+			d = decoration{label: name.Short(), rank: 2}
+			if head != nil && head.Name() == name {
+				d = decoration{label: "HEAD -> " + name.Short(), rank: 0}
+			}
+		case name.IsRemote():
+			d = decoration{label: name.Short(), rank: 3}
+		default:
+			return nil
+		}
+
+		c, err := b.peelCommit(ref.Hash())
+		if err != nil {
+			return nil
+		}
+		byCommit[c.Hash] = append(byCommit[c.Hash], d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if head != nil && !head.Name().IsBranch() {
+		byCommit[head.Hash()] = append(byCommit[head.Hash()], decoration{label: "HEAD", rank: 0})
+	}
+
+	result := make(map[plumbing.Hash][]string, len(byCommit))
+	for hash, decs := range byCommit {
+		sort.Slice(decs, func(i, j int) bool {
+			if decs[i].rank != decs[j].rank {
+				return decs[i].rank < decs[j].rank
+			}
+			return decs[i].label < decs[j].label
+		})
+
+		labels := make([]string, len(decs))
+		for i, d := range decs {
+			labels[i] = d.label
+		}
+		result[hash] = labels
+	}
+
+	return result, nil
+}
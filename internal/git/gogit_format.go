@@ -0,0 +1,667 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Date layouts matching git's --date modes
+const (
+	gitDefaultDate = "Mon Jan 2 15:04:05 2006 -0700"
+	gitISODate     = "2006-01-02 15:04:05 -0700"
+	gitStrictDate  = "2006-01-02T15:04:05-07:00"
+	gitRFC2822Date = "Mon, 2 Jan 2006 15:04:05 -0700"
+	gitShortDate   = "2006-01-02"
+)
+
+// formatContext carries what placeholder expansion needs besides the commit
+type formatContext struct {
+	dateMode    string
+	decorations map[plumbing.Hash][]string
+	mailmap     *Mailmap
+	noAbbrev    bool // --no-abbrev: abbreviated placeholders show full hashes
+}
+
+// abbrev abbreviates h unless --no-abbrev was given
+func (fc *formatContext) abbrev(h plumbing.Hash) string {
+	if fc.noAbbrev {
+		return h.String()
+	}
+	return abbrev(h)
+}
+
+// expandFormat expands git pretty-format placeholders for a commit.
+// Unknown placeholders are copied through verbatim, as git does.
+func expandFormat(format string, c *object.Commit, fc *formatContext) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 >= len(format) {
+			sb.WriteByte(format[i])
+			continue
+		}
+
+		n, ok := expandPlaceholder(&sb, format[i+1:], c, fc)
+		if !ok {
+			sb.WriteByte('%')
+			continue
+		}
+		i += n
+	}
+
+	return sb.String()
+}
+
+// expandPlaceholder expands the placeholder at the start of spec and
+// returns the number of bytes consumed
+func expandPlaceholder(sb *strings.Builder, spec string, c *object.Commit, fc *formatContext) (int, bool) {
+	switch spec[0] {
+	case '%':
+		sb.WriteByte('%')
+		return 1, true
+	case 'n':
+		sb.WriteByte('\n')
+		return 1, true
+	case 'H':
+		sb.WriteString(c.Hash.String())
+		return 1, true
+	case 'h':
+		sb.WriteString(fc.abbrev(c.Hash))
+		return 1, true
+	case 'T':
+		sb.WriteString(c.TreeHash.String())
+		return 1, true
+	case 't':
+		sb.WriteString(fc.abbrev(c.TreeHash))
+		return 1, true
+	case 'P', 'p':
+		parents := make([]string, len(c.ParentHashes))
+		for i, p := range c.ParentHashes {
+			if spec[0] == 'P' {
+				parents[i] = p.String()
+			} else {
+				parents[i] = fc.abbrev(p)
+			}
+		}
+		sb.WriteString(strings.Join(parents, " "))
+		return 1, true
+	case 's':
+		sb.WriteString(commitSubject(c.Message))
+		return 1, true
+	case 'b':
+		sb.WriteString(commitBody(c.Message))
+		return 1, true
+	case 'B':
+		sb.WriteString(c.Message)
+		return 1, true
+	case 'd', 'D':
+		refs := fc.decorations[c.Hash]
+		if len(refs) > 0 {
+			if spec[0] == 'd' {
+				sb.WriteString(" (" + strings.Join(refs, ", ") + ")")
+			} else {
+				sb.WriteString(strings.Join(refs, ", "))
+			}
+		}
+		return 1, true
+	case 'x':
+		if len(spec) >= 3 {
+			if v, err := strconv.ParseUint(spec[1:3], 16, 8); err == nil {
+				sb.WriteByte(byte(v))
+				return 3, true
+			}
+		}
+		return 0, false
+	case 'a', 'c':
+		if len(spec) < 2 {
+			return 0, false
+		}
+
+		sig := c.Author
+		if spec[0] == 'c' {
+			sig = c.Committer
+		}
+
+		if spec[1] == 'N' || spec[1] == 'E' {
+			sig.Name, sig.Email = fc.mailmap.Resolve(sig.Name, sig.Email)
+		}
+
+		value, ok := expandSignature(spec[1], sig, fc.dateMode)
+		if !ok {
+			return 0, false
+		}
+		sb.WriteString(value)
+		return 2, true
+	}
+
+	return 0, false
+}
+
+// expandSignature expands the second letter of an author/committer placeholder
+func expandSignature(field byte, sig object.Signature, dateMode string) (string, bool) {
+	switch field {
+	case 'n', 'N':
+		return sig.Name, true
+	case 'e', 'E':
+		return sig.Email, true
+	case 'd':
+		return formatDate(sig.When, dateMode), true
+	case 'D':
+		return sig.When.Format(gitRFC2822Date), true
+	case 'i':
+		return sig.When.Format(gitISODate), true
+	case 'I':
+		return sig.When.Format(gitStrictDate), true
+	case 's':
+		return sig.When.Format(gitShortDate), true
+	case 't':
+		return strconv.FormatInt(sig.When.Unix(), 10), true
+	}
+
+	return "", false
+}
+
+// formatDate renders a time using a git --date mode
+func formatDate(t time.Time, mode string) string {
+	switch {
+	case mode == "" || mode == "default":
+		return t.Format(gitDefaultDate)
+	case mode == "local" || mode == "default-local":
+		return t.Local().Format(gitDefaultDate)
+	case mode == "short":
+		return t.Format(gitShortDate)
+	case mode == "iso" || mode == "iso8601":
+		return t.Format(gitISODate)
+	case mode == "iso-strict" || mode == "iso8601-strict":
+		return t.Format(gitStrictDate)
+	case mode == "rfc" || mode == "rfc2822":
+		return t.Format(gitRFC2822Date)
+	case mode == "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case mode == "raw":
+		return strconv.FormatInt(t.Unix(), 10) + " " + t.Format("-0700")
+	case strings.HasPrefix(mode, "format-local:"):
+		return strftime(t.Local(), strings.TrimPrefix(mode, "format-local:"))
+	case strings.HasPrefix(mode, "format:"):
+		return strftime(t, strings.TrimPrefix(mode, "format:"))
+	case strings.HasSuffix(mode, "-local"):
+		return formatDate(t.Local(), strings.TrimSuffix(mode, "-local"))
+	}
+
+	return t.Format(gitDefaultDate)
+}
+
+// validDateMode reports whether formatDate understands mode
+func validDateMode(mode string) bool {
+	mode = strings.TrimSuffix(mode, "-local")
+	switch mode {
+	case "", "default", "local", "short", "iso", "iso8601", "iso-strict", "iso8601-strict",
+		"rfc", "rfc2822", "unix", "raw", "format":
+		return true
+	}
+	return strings.HasPrefix(mode, "format:") || strings.HasPrefix(mode, "format-local:")
+}
+
+// strftime implements the strftime conversions commonly used with --date=format:
+func strftime(t time.Time, layout string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(layout); i++ {
+		if layout[i] != '%' || i+1 >= len(layout) {
+			sb.WriteByte(layout[i])
+			continue
+		}
+
+		i++
+		switch layout[i] {
+		case 'Y':
+			sb.WriteString(strconv.Itoa(t.Year()))
+		case 'y':
+			sb.WriteString(t.Format("06"))
+		case 'm':
+			sb.WriteString(t.Format("01"))
+		case 'd':
+			sb.WriteString(t.Format("02"))
+		case 'e':
+			sb.WriteString(fmt.Sprintf("%2d", t.Day()))
+		case 'H':
+			sb.WriteString(t.Format("15"))
+		case 'I':
+			sb.WriteString(t.Format("03"))
+		case 'M':
+			sb.WriteString(t.Format("04"))
+		case 'S':
+			sb.WriteString(t.Format("05"))
+		case 'p':
+			sb.WriteString(t.Format("PM"))
+		case 'A':
+			sb.WriteString(t.Weekday().String())
+		case 'a':
+			sb.WriteString(t.Format("Mon"))
+		case 'B':
+			sb.WriteString(t.Month().String())
+		case 'b', 'h':
+			sb.WriteString(t.Format("Jan"))
+		case 'j':
+			sb.WriteString(fmt.Sprintf("%03d", t.YearDay()))
+		case 'u':
+			wd := int(t.Weekday())
+			if wd == 0 {
+				wd = 7
+			}
+			sb.WriteString(strconv.Itoa(wd))
+		case 'w':
+			sb.WriteString(strconv.Itoa(int(t.Weekday())))
+		case 'z':
+			sb.WriteString(t.Format("-0700"))
+		case 'Z':
+			sb.WriteString(t.Format("MST"))
+		case 's':
+			sb.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'F':
+			sb.WriteString(t.Format(gitShortDate))
+		case 'T':
+			sb.WriteString(t.Format("15:04:05"))
+		case '%':
+			sb.WriteByte('%')
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(layout[i])
+		}
+	}
+
+	return sb.String()
+}
+
+// commitSubject returns the first paragraph of a message joined into one line
+func commitSubject(message string) string {
+	lines := strings.Split(message, "\n")
+	subject := make([]string, 0, 1)
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			if len(subject) > 0 {
+				break
+			}
+			continue
+		}
+		subject = append(subject, strings.TrimSpace(line))
+	}
+
+	return strings.Join(subject, " ")
+}
+
+// commitBody returns the message after the subject paragraph
+func commitBody(message string) string {
+	lines := strings.SplitAfter(message, "\n")
+	i := 0
+
+	// Skip leading blank lines, the subject paragraph and the blank lines after it
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+		i++
+	}
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+
+	return strings.Join(lines[i:], "")
+}
+
+// abbrev returns the short form of a hash
+func abbrev(h plumbing.Hash) string {
+	return h.String()[:7]
+}
+
+// renderMedium renders a commit in git's default "medium" format
+func renderMedium(c *object.Commit, fc *formatContext) string {
+	var sb strings.Builder
+
+	sb.WriteString("commit " + c.Hash.String())
+	if refs := fc.decorations[c.Hash]; len(refs) > 0 {
+		sb.WriteString(" (" + strings.Join(refs, ", ") + ")")
+	}
+	sb.WriteByte('\n')
+
+	if c.NumParents() > 1 {
+		parents := make([]string, len(c.ParentHashes))
+		for i, p := range c.ParentHashes {
+			parents[i] = abbrev(p)
+		}
+		sb.WriteString("Merge: " + strings.Join(parents, " ") + "\n")
+	}
+
+	// git log applies the mailmap to the built-in formats (log.mailmap)
+	name, email := fc.mailmap.Resolve(c.Author.Name, c.Author.Email)
+	sb.WriteString("Author: " + name + " <" + email + ">\n")
+	sb.WriteString("Date:   " + formatDate(c.Author.When, fc.dateMode) + "\n\n")
+
+	message := strings.TrimRight(c.Message, "\n")
+	for _, line := range strings.Split(message, "\n") {
+		sb.WriteString("    " + line + "\n")
+	}
+
+	return sb.String()
+}
+
+// renderOneline renders a commit in git's "oneline" format
+func renderOneline(c *object.Commit, fc *formatContext) string {
+	line := abbrev(c.Hash)
+	if refs := fc.decorations[c.Hash]; len(refs) > 0 {
+		line += " (" + strings.Join(refs, ", ") + ")"
+	}
+
+	return line + " " + commitSubject(c.Message)
+}
+
+// renderChanges renders raw entries followed by numstat or name-only
+// entries, one per terminator
+func renderChanges(sb *strings.Builder, q *revQuery, changes []fileChange) {
+	term := q.lineTerm()
+
+	if q.raw {
+		for _, fc := range changes {
+			renderRaw(sb, q, fc)
+		}
+	}
+
+	if !q.numstat && !q.nameOnly {
+		return
+	}
+
+	for _, fc := range changes {
+		if q.nameOnly && !q.numstat {
+			sb.WriteString(fc.Path() + term)
+			continue
+		}
+
+		if fc.Binary {
+			sb.WriteString("-\t-\t")
+		} else {
+			sb.WriteString(strconv.Itoa(fc.Additions) + "\t" + strconv.Itoa(fc.Deletions) + "\t")
+		}
+
+		renamed := fc.From != "" && fc.To != "" && fc.From != fc.To
+
+		switch {
+		case renamed && q.nulTerm:
+			sb.WriteString("\x00" + fc.From + "\x00" + fc.To + "\x00")
+		case renamed:
+			sb.WriteString(prettyRename(fc.From, fc.To) + term)
+		default:
+			sb.WriteString(fc.Path() + term)
+		}
+	}
+}
+
+// renderRaw renders a change like git's --raw output. go-git doesn't
+// report how similar a renamed file is, so inexact renames carry the
+// rename threshold as their score, the least similarity they can have.
+func renderRaw(sb *strings.Builder, q *revQuery, fc fileChange) {
+	hash := func(h plumbing.Hash) string {
+		if q.noAbbrev {
+			return h.String()
+		}
+		return abbrev(h)
+	}
+
+	status := "M"
+	switch {
+	case fc.From == "":
+		status = "A"
+	case fc.To == "":
+		status = "D"
+	case fc.From != fc.To && fc.FromHash == fc.ToHash:
+		status = "R100"
+	case fc.From != fc.To:
+		status = fmt.Sprintf("R%03d", q.renameScore)
+	}
+
+	fmt.Fprintf(sb, ":%06o %06o %s %s %s", uint32(fc.FromMode), uint32(fc.ToMode), hash(fc.FromHash), hash(fc.ToHash), status)
+
+	paths := []string{fc.Path()}
+	if status[0] == 'R' {
+		paths = []string{fc.From, fc.To}
+	}
+
+	if q.nulTerm {
+		sb.WriteString("\x00" + strings.Join(paths, "\x00") + "\x00")
+	} else {
+		sb.WriteString("\t" + strings.Join(paths, "\t") + "\n")
+	}
+}
+
+// prettyRename renders a rename like git's numstat, e.g. "dir/{a => b}.go".
+// It mirrors pprint_rename in git's diff.c, where the common prefix and
+// suffix may share a directory separator.
+func prettyRename(from, to string) string {
+	pfx := 0
+	for i := 0; i < len(from) && i < len(to) && from[i] == to[i]; i++ {
+		if from[i] == '/' {
+			pfx = i + 1
+		}
+	}
+
+	sfx := 0
+	for i, j := len(from)-1, len(to)-1; i >= pfx-1 && j >= pfx-1 && i >= 0 && j >= 0 && from[i] == to[j]; i, j = i-1, j-1 {
+		if from[i] == '/' {
+			sfx = len(from) - i
+		}
+	}
+
+	if pfx+sfx == 0 {
+		return from + " => " + to
+	}
+
+	fromMid := max(len(from)-pfx-sfx, 0)
+	toMid := max(len(to)-pfx-sfx, 0)
+
+	return from[:pfx] + "{" + from[pfx:pfx+fromMid] + " => " + to[pfx:pfx+toMid] + "}" + from[len(from)-sfx:]
+}
+
+// refAtoms holds the values for-each-ref can expand for a reference
+type refAtoms struct {
+	ref     *plumbing.Reference
+	target  plumbing.Hash // object the reference points to
+	objType string
+	commit  *object.Commit
+	tag     *object.Tag
+	isHead  bool
+}
+
+// creatorDate returns the tagger date for annotated tags and the committer
+// date otherwise
+func (r *refAtoms) creatorDate() (time.Time, bool) {
+	if r.tag != nil {
+		return r.tag.Tagger.When, true
+	}
+	if r.commit != nil {
+		return r.commit.Committer.When, true
+	}
+	return time.Time{}, false
+}
+
+// commitDate returns the author or committer date of the referenced commit
+func (r *refAtoms) commitDate(author bool) time.Time {
+	switch {
+	case r.commit == nil:
+		return time.Time{}
+	case author:
+		return r.commit.Author.When
+	default:
+		return r.commit.Committer.When
+	}
+}
+
+// expandRefFormat expands a for-each-ref --format string
+func expandRefFormat(format string, r *refAtoms) (string, error) {
+	var sb strings.Builder
+
+	for i := 0; i < len(format); i++ {
+		switch {
+		case strings.HasPrefix(format[i:], "%("):
+			end := strings.IndexByte(format[i:], ')')
+			if end < 0 {
+				return "", fmt.Errorf("malformed format string %q", format)
+			}
+			value, err := refAtom(format[i+2:i+end], r)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(value)
+			i += end
+		case strings.HasPrefix(format[i:], "%%"):
+			sb.WriteByte('%')
+			i++
+		case format[i] == '%' && i+2 < len(format):
+			if v, err := strconv.ParseUint(format[i+1:i+3], 16, 8); err == nil {
+				sb.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+			sb.WriteByte('%')
+		default:
+			sb.WriteByte(format[i])
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// refAtom expands a single %(atom[:modifier])
+func refAtom(atom string, r *refAtoms) (string, error) {
+	name, modifier, _ := strings.Cut(atom, ":")
+
+	sigDate := func(sig *object.Signature) string {
+		if sig == nil {
+			return ""
+		}
+		return formatDate(sig.When, modifier)
+	}
+
+	var author, committer *object.Signature
+	if r.commit != nil {
+		author, committer = &r.commit.Author, &r.commit.Committer
+	}
+
+	switch name {
+	case "refname":
+		switch modifier {
+		case "":
+			return r.ref.Name().String(), nil
+		case "short":
+			return r.ref.Name().Short(), nil
+		}
+	case "objectname":
+		switch modifier {
+		case "":
+			return r.target.String(), nil
+		case "short":
+			return abbrev(r.target), nil
+		}
+	case "objecttype":
+		return r.objType, nil
+	case "HEAD":
+		if r.isHead {
+			return "*", nil
+		}
+		return " ", nil
+	case "subject":
+		if r.tag != nil {
+			return commitSubject(r.tag.Message), nil
+		}
+		if r.commit != nil {
+			return commitSubject(r.commit.Message), nil
+		}
+		return "", nil
+	case "authorname":
+		if author != nil {
+			return author.Name, nil
+		}
+		return "", nil
+	case "authoremail":
+		if author != nil {
+			return "<" + author.Email + ">", nil
+		}
+		return "", nil
+	case "committername":
+		if committer != nil {
+			return committer.Name, nil
+		}
+		return "", nil
+	case "committeremail":
+		if committer != nil {
+			return "<" + committer.Email + ">", nil
+		}
+		return "", nil
+	case "authordate":
+		return sigDate(author), nil
+	case "committerdate":
+		return sigDate(committer), nil
+	case "taggerdate":
+		if r.tag != nil {
+			return formatDate(r.tag.Tagger.When, modifier), nil
+		}
+		return "", nil
+	case "creatordate":
+		if t, ok := r.creatorDate(); ok {
+			return formatDate(t, modifier), nil
+		}
+		return "", nil
+	}
+
+	return "", fmt.Errorf("%w: for-each-ref atom %%(%s)", ErrUnsupported, atom)
+}
+
+// sortRefs orders references by for-each-ref --sort keys; the last key
+// given is the primary one, as in git
+func sortRefs(refs []*refAtoms, keys []string) error {
+	if len(keys) == 0 {
+		keys = []string{"refname"}
+	}
+
+	for i := len(keys) - 1; i >= 0; i-- {
+		key := keys[i]
+		desc := strings.HasPrefix(key, "-")
+		key = strings.TrimPrefix(key, "-")
+
+		var less func(a, b *refAtoms) bool
+
+		switch key {
+		case "refname":
+			less = func(a, b *refAtoms) bool { return a.ref.Name().String() < b.ref.Name().String() }
+		case "objectname":
+			less = func(a, b *refAtoms) bool { return a.target.String() < b.target.String() }
+		case "creatordate", "taggerdate":
+			less = func(a, b *refAtoms) bool {
+				ta, _ := a.creatorDate()
+				tb, _ := b.creatorDate()
+				return ta.Before(tb)
+			}
+		case "committerdate", "authordate":
+			author := key == "authordate"
+			less = func(a, b *refAtoms) bool {
+				return a.commitDate(author).Before(b.commitDate(author))
+			}
+		default:
+			return fmt.Errorf("%w: sort key %s", ErrUnsupported, key)
+		}
+
+		sort.SliceStable(refs, func(x, y int) bool {
+			if desc {
+				return less(refs[y], refs[x])
+			}
+			return less(refs[x], refs[y])
+		})
+	}
+
+	return nil
+}
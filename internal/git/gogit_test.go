@@ -0,0 +1,394 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newParityFixture builds a small history with renames, binaries, tags,
+// branches and a merge, exercising most of what the analyzers ask for
+func newParityFixture(t *testing.T) *fixtureRepo {
+	t.Helper()
+
+	repo := newFixtureRepo(t)
+	day := time.Date(2024, 5, 6, 9, 30, 0, 0, time.FixedZone("", 2*3600))
+
+	repo.Write("README.md", "# fixture\n")
+	repo.Write("src/main.go", "package main\n\nfunc main() {}\n")
+	repo.Commit("Alice", "alice@example.com", "feat: initial import", day)
+	repo.Git(day, "tag", "v0.1.0")
+
+	repo.Write("src/main.go", "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(1) }\n")
+	repo.Write("logo.png", "\x89PNG\x00\x01\x02")
+	repo.Commit("Bob", "bob@example.com", "fix(main): print something\n\nLonger explanation\nacross lines.", day.Add(26*time.Hour))
+
+	repo.Git(day.Add(27*time.Hour), "checkout", "-q", "-b", "feature")
+	repo.Git(day.Add(27*time.Hour), "mv", "src/main.go", "src/app.go")
+	repo.Commit("Carol", "carol@example.com", "refactor: rename main", day.Add(28*time.Hour))
+
+	repo.Git(day.Add(29*time.Hour), "checkout", "-q", "main")
+	repo.Write("docs/guide.md", "guide\n")
+	repo.Commit("Alice", "alice@example.com", "docs: add guide", day.Add(30*time.Hour))
+	repo.Git(day.Add(31*time.Hour), "merge", "-q", "--no-ff", "-m", "Merge branch 'feature'", "feature")
+	repo.Git(day.Add(32*time.Hour), "tag", "-a", "v0.2.0", "-m", "second release")
+
+	// Left untracked: git reads the working tree copy
+	repo.Write(".mailmap", "Robert <robert@example.com> <bob@example.com>\nCarol C <carol@example.com>\n")
+
+	return repo
+}
+
+func TestGoGitBackendParity(t *testing.T) {
+	repo := newParityFixture(t)
+	exec := repo.backend()
+
+	gogit, err := NewGoGitBackend(repo.Dir)
+	if err != nil {
+		t.Fatalf("NewGoGitBackend() error = %v", err)
+	}
+
+	blobs := []string{
+		strings.TrimSpace(repo.Git(time.Time{}, "rev-parse", "HEAD:logo.png")),
+		strings.TrimSpace(repo.Git(time.Time{}, "rev-parse", "HEAD:README.md")),
+		strings.TrimSpace(repo.Git(time.Time{}, "rev-parse", "HEAD")),
+		strings.Repeat("0", 40),
+	}
+
+	type call struct {
+		name string
+		run  func(b Backend) (string, error)
+	}
+
+	logCalls := [][]string{
+		{"--pretty=format:%H|%an|%ae|%ad|%s", "--date=iso", "--numstat"},
+		{"--pretty=format:%H|%an|%ae|%ad|%s", "--date=iso", "--numstat", "--no-merges"},
+		{"--format=%h %P", "--numstat", "-z"},
+		{"--pretty=format:%h%x00%b%x1e", "--numstat", "-z"},
+		{"--pretty=format:%h", "--raw", "--numstat", "--all"},
+		{"--pretty=format:%h%x00", "--raw", "--no-abbrev", "--numstat", "-z", "--all"},
+		{"--pretty=format:%ad", "--date=short"},
+		{"--pretty=format:%ad", "--date=format:%Y-%m|%A|%H|%z"},
+		{"--pretty=format:%an|%ad", "--merges", "--date=format:%Y-%m"},
+		{"--pretty=format:%ae", "--", "src/main.go"},
+		{"--pretty=format:%h", "--", ":!docs"},
+		{"--pretty=format:%h", "v0.1.0..v0.2.0"},
+		{"--pretty=format:%h", "--author=bob@"},
+		{"--pretty=format:%h", "--max-count=2", "--reverse"},
+		{"--pretty=format:%h", "--since=2024-05-07 12:00:00", "--until=2024-05-07 17:00:00"},
+		{"--oneline", "--decorate", "--all"},
+		{"--numstat", "-n", "2"},
+		{"--skip=2", "-n", "2", "--date=iso-strict"},
+		{"--pretty=format:%aI%x00%cI%x00%B", "-z"},
+		{"--all", "--pretty=format:%h %d"},
+		{"--pretty=format:%an|%ae|%aN|%aE|%cN"},
+	}
+
+	var calls []call
+	for _, args := range logCalls {
+		calls = append(calls, call{"log " + joinArgs(args), func(b Backend) (string, error) { return b.Log(args...) }})
+	}
+
+	calls = append(calls,
+		call{"shortlog -s -n -e", func(b Backend) (string, error) { return b.Shortlog("-s", "-n", "-e", "HEAD") }},
+		call{"shortlog", func(b Backend) (string, error) { return b.Shortlog("HEAD") }},
+		call{"rev-list --count", func(b Backend) (string, error) { return b.RevList("--count", "HEAD") }},
+		call{"rev-list range", func(b Backend) (string, error) { return b.RevList("main..feature") }},
+		call{"rev-list --parents", func(b Backend) (string, error) { return b.RevList("--parents", "--all") }},
+		call{"for-each-ref heads", func(b Backend) (string, error) {
+			return b.ForEachRef("--format=%(refname:short)|%(committerdate:iso)|%(objectname:short)|%(authorname)", "refs/heads/")
+		}},
+		call{"for-each-ref tags", func(b Backend) (string, error) {
+			return b.ForEachRef("--sort=creatordate", "--format=%(refname:short)|%(creatordate:iso)|%(objecttype)", "refs/tags/")
+		}},
+		call{"branch -a", func(b Backend) (string, error) { return b.Branches("-a") }},
+		call{"tag", func(b Backend) (string, error) { return b.Tags() }},
+		call{"diff --numstat", func(b Backend) (string, error) { return b.Diff("--numstat", "v0.1.0", "v0.2.0") }},
+		call{"show blob", func(b Backend) (string, error) { return b.Show("v0.1.0:README.md") }},
+		call{"show -s", func(b Backend) (string, error) { return b.Show("-s", "--format=%H %s", "v0.1.0") }},
+		call{"current branch", func(b Backend) (string, error) { return b.CurrentBranch() }},
+		call{"rev-parse layout", func(b Backend) (string, error) {
+			return b.RevParse("--path-format=absolute", "--is-bare-repository", "--absolute-git-dir", "--git-common-dir", "--show-toplevel")
+		}},
+		call{"ls-tree", func(b Backend) (string, error) { return b.LsTree("HEAD") }},
+		call{"ls-tree -r", func(b Backend) (string, error) { return b.LsTree("-r", "HEAD") }},
+		call{"ls-tree -r --name-only -z", func(b Backend) (string, error) {
+			return b.LsTree("-r", "--name-only", "-z", "v0.1.0", "--", "src")
+		}},
+		call{"blame --line-porcelain", func(b Backend) (string, error) {
+			out, err := b.Blame("--line-porcelain", "HEAD~1", "--", "src/main.go")
+			return blameSubset(out), err
+		}},
+		call{"blame --line-porcelain renamed", func(b Backend) (string, error) {
+			out, err := b.Blame("--line-porcelain", "HEAD", "--", "src/app.go")
+			return blameSubset(out), err
+		}},
+		call{"log stream", func(b Backend) (string, error) {
+			return readStream(b, "--pretty=format:%x1e%H%x00%P%x00%aI%x00%s%x00", "-z", "--numstat", "--all")
+		}},
+		call{"cat-file", func(b Backend) (string, error) {
+			objects, err := b.Objects(blobs, 10)
+			return fmt.Sprintf("%q", objects), err
+		}},
+	)
+
+	for _, c := range calls {
+		t.Run(c.name, func(t *testing.T) {
+			want, err := c.run(exec)
+			if err != nil {
+				t.Fatalf("exec backend error = %v", err)
+			}
+
+			got, err := c.run(gogit)
+			if err != nil {
+				t.Fatalf("go-git backend error = %v", err)
+			}
+
+			if got != want {
+				t.Errorf("output mismatch\n got: %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+// blameSubset keeps the blame --line-porcelain fields emitted by the go-git
+// backend, except filename which go-git does not track across renames
+func blameSubset(out string) string {
+	var sb strings.Builder
+
+	for _, line := range strings.SplitAfter(out, "\n") {
+		key, _, _ := strings.Cut(line, " ")
+		switch {
+		case len(key) == 40:
+			fields := strings.Fields(line)
+			sb.WriteString(strings.Join(fields[:3], " ") + "\n")
+		case key == "author", key == "author-mail", key == "author-time", key == "author-tz",
+			strings.HasPrefix(line, "\t"):
+			sb.WriteString(line)
+		}
+	}
+
+	return sb.String()
+}
+
+func TestGoGitBackendUnsupported(t *testing.T) {
+	repo := newParityFixture(t)
+
+	gogit, err := NewGoGitBackend(repo.Dir)
+	if err != nil {
+		t.Fatalf("NewGoGitBackend() error = %v", err)
+	}
+
+	if _, err := gogit.Log("--cherry-pick"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Log(--cherry-pick) error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestNewGoGitBackendNotARepository(t *testing.T) {
+	if _, err := NewGoGitBackend(t.TempDir()); err == nil {
+		t.Error("NewGoGitBackend() on empty directory succeeded, want error")
+	}
+}
+
+func TestPrettyRename(t *testing.T) {
+	tests := []struct {
+		from, to, want string
+	}{
+		{"a.txt", "b.txt", "a.txt => b.txt"},
+		{"src/main.go", "src/app.go", "src/{main.go => app.go}"},
+		{"old/pkg/x.go", "new/pkg/x.go", "{old => new}/pkg/x.go"},
+		{"a/b/c.go", "a/c.go", "a/{b => }/c.go"},
+	}
+
+	for _, tt := range tests {
+		if got := prettyRename(tt.from, tt.to); got != tt.want {
+			t.Errorf("prettyRename(%q, %q) = %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestParseSimilarity(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"", 50, false},
+		{"90%", 90, false},
+		{"=75%", 75, false},
+		{"9", 90, false},
+		{"05", 5, false},
+		{"150%", 0, true},
+		{"abc", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSimilarity(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseSimilarity(%q) = %d, %v, want %d", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+// readStream drains LogStream into a string
+func readStream(b Backend, args ...string) (string, error) {
+	stream, err := b.LogStream(args...)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	data, err := io.ReadAll(stream)
+	return string(data), err
+}
+
+func TestLogStreamEarlyClose(t *testing.T) {
+	repo := newParityFixture(t)
+
+	gogit, err := NewGoGitBackend(repo.Dir)
+	if err != nil {
+		t.Fatalf("NewGoGitBackend() error = %v", err)
+	}
+
+	for name, b := range map[string]Backend{"exec": repo.backend(), "go-git": gogit} {
+		t.Run(name, func(t *testing.T) {
+			stream, err := b.LogStream("--oneline")
+			if err != nil {
+				t.Fatalf("LogStream() error = %v", err)
+			}
+
+			buf := make([]byte, 4)
+			if _, err := io.ReadFull(stream, buf); err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+
+			if err := stream.Close(); err != nil {
+				t.Errorf("Close() before EOF error = %v", err)
+			}
+		})
+	}
+}
+
+func TestLogStreamBadRevision(t *testing.T) {
+	repo := newParityFixture(t)
+
+	stream, err := repo.backend().LogStream("no-such-branch")
+	if err != nil {
+		t.Fatalf("LogStream() error = %v", err)
+	}
+	defer stream.Close()
+
+	if _, err := io.ReadAll(stream); err == nil {
+		t.Error("reading stream of unknown revision succeeded, want error")
+	}
+}
+
+func TestBackendWithContextCancelled(t *testing.T) {
+	repo := newParityFixture(t)
+
+	gogit, err := NewGoGitBackend(repo.Dir)
+	if err != nil {
+		t.Fatalf("NewGoGitBackend() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for name, b := range map[string]Backend{"exec": repo.backend(), "go-git": gogit} {
+		t.Run(name, func(t *testing.T) {
+			bound := b.WithContext(ctx)
+
+			if _, err := bound.Log("--numstat"); !errors.Is(err, context.Canceled) {
+				t.Errorf("Log() error = %v, want context.Canceled", err)
+			}
+
+			if _, err := readStream(bound, "--numstat"); !errors.Is(err, context.Canceled) {
+				t.Errorf("LogStream() error = %v, want context.Canceled", err)
+			}
+
+			// The original backend must stay usable
+			if _, err := b.Log("-n", "1"); err != nil {
+				t.Errorf("unbound Log() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestGoGitBackendConcurrentUse(t *testing.T) {
+	repo := newParityFixture(t)
+	// Lookups in a packfile index fill a shared cache, which is where
+	// unsynchronized access shows up
+	repo.Git(time.Time{}, "gc", "-q")
+
+	b, err := NewGoGitBackend(repo.Dir)
+	if err != nil {
+		t.Fatalf("NewGoGitBackend() error = %v", err)
+	}
+
+	want, err := b.Log("--numstat")
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+
+	// Calls made while reading a stream must neither deadlock nor race
+	// with its walk
+	stream, err := b.LogStream("--numstat")
+	if err != nil {
+		t.Fatalf("LogStream() error = %v", err)
+	}
+
+	var got strings.Builder
+	buf := make([]byte, 4096)
+	for {
+		n, err := stream.Read(buf)
+		got.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+
+		if _, err := b.Show("v0.1.0:README.md"); err != nil {
+			t.Fatalf("Show() during stream error = %v", err)
+		}
+	}
+	stream.Close()
+
+	if got.String() != want {
+		t.Errorf("stream = %q, want %q", got.String(), want)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = b.WithContext(context.Background()).Blame("--line-porcelain", "HEAD", "--", "src/app.go")
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("concurrent Blame() error = %v", err)
+		}
+	}
+}
+
+func joinArgs(args []string) string {
+	out := ""
+	for i, a := range args {
+		if i > 0 {
+			out += " "
+		}
+		out += a
+	}
+	return out
+}
@@ -0,0 +1,857 @@
+package git
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+)
+
+// revQuery holds the subset of git log / rev-list arguments that
+// GoGitBackend understands. Anything else is rejected with ErrUnsupported
+// rather than silently producing different results than the git CLI.
+type revQuery struct {
+	format               string // pretty format placeholders, empty for a built-in style
+	style                string // "format", "tformat", "medium" or "oneline"
+	dateMode             string
+	numstat              bool
+	nameOnly             bool
+	raw                  bool
+	noAbbrev             bool
+	nulTerm              bool
+	noMerges             bool
+	merges               bool
+	maxCount             int
+	skip                 int
+	since                time.Time
+	until                time.Time
+	authors              []*regexp.Regexp
+	all                  bool
+	reverse              bool
+	count                bool
+	parents              bool
+	decorate             bool
+	graph                bool
+	firstParent          bool
+	simplifyByDecoration bool
+	renameScore          int // similarity percentage for rename detection, 0 disables it
+	include              []string
+	exclude              []string
+	paths                pathSpec
+}
+
+// parseRevQuery parses git log / rev-list style arguments
+func parseRevQuery(args []string) (*revQuery, error) {
+	q := &revQuery{style: "medium", maxCount: -1, renameScore: defaultRenameScore}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			q.paths = newPathSpec(args[i+1:])
+			break
+		}
+
+		value := func(prefix string) string { return strings.TrimPrefix(arg, prefix) }
+
+		switch {
+		case strings.HasPrefix(arg, "--pretty=") || strings.HasPrefix(arg, "--format="):
+			if err := q.setPretty(arg); err != nil {
+				return nil, err
+			}
+		case arg == "--pretty":
+			q.style = "medium"
+		case arg == "--oneline":
+			q.style = "oneline"
+		case strings.HasPrefix(arg, "--date="):
+			q.dateMode = value("--date=")
+		case arg == "--numstat":
+			q.numstat = true
+		case arg == "--name-only":
+			q.nameOnly = true
+		case arg == "--raw":
+			q.raw = true
+		case arg == "--no-abbrev":
+			q.noAbbrev = true
+		case arg == "-z":
+			q.nulTerm = true
+		case arg == "--no-merges":
+			q.noMerges = true
+		case arg == "--merges":
+			q.merges = true
+		case arg == "--all":
+			q.all = true
+		case arg == "--reverse":
+			q.reverse = true
+		case arg == "--count":
+			q.count = true
+		case arg == "--parents":
+			q.parents = true
+		case arg == "--decorate":
+			q.decorate = true
+		case arg == "--graph":
+			q.graph = true
+		case arg == "--first-parent":
+			q.firstParent = true
+		case arg == "--simplify-by-decoration":
+			q.simplifyByDecoration = true
+		case arg == "--no-renames":
+			q.renameScore = 0
+		case strings.HasPrefix(arg, "-M"), strings.HasPrefix(arg, "--find-renames"):
+			score, err := parseSimilarity(strings.TrimPrefix(strings.TrimPrefix(arg, "-M"), "--find-renames"))
+			if err != nil {
+				return nil, err
+			}
+			q.renameScore = score
+		case arg == "--no-color", arg == "--date-order", arg == "--no-decorate",
+			arg == "--use-mailmap", arg == "--topo-order":
+			// Accepted for compatibility, no effect on the output we produce
+		case strings.HasPrefix(arg, "--max-count="):
+			n, err := strconv.Atoi(value("--max-count="))
+			if err != nil {
+				return nil, fmt.Errorf("invalid --max-count: %w", err)
+			}
+			q.maxCount = n
+		case arg == "-n":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("-n requires a value")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid -n: %w", err)
+			}
+			q.maxCount = n
+			i++
+		case strings.HasPrefix(arg, "--skip="):
+			n, err := strconv.Atoi(value("--skip="))
+			if err != nil {
+				return nil, fmt.Errorf("invalid --skip: %w", err)
+			}
+			q.skip = n
+		case strings.HasPrefix(arg, "--since="), strings.HasPrefix(arg, "--after="):
+			t, err := parseApproxDate(arg[strings.Index(arg, "=")+1:])
+			if err != nil {
+				return nil, err
+			}
+			q.since = t
+		case strings.HasPrefix(arg, "--until="), strings.HasPrefix(arg, "--before="):
+			t, err := parseApproxDate(arg[strings.Index(arg, "=")+1:])
+			if err != nil {
+				return nil, err
+			}
+			q.until = t
+		case strings.HasPrefix(arg, "--author="):
+			re, err := regexp.Compile(value("--author="))
+			if err != nil {
+				return nil, fmt.Errorf("invalid --author pattern: %w", err)
+			}
+			q.authors = append(q.authors, re)
+		case len(arg) > 1 && arg[0] == '-' && isDigits(arg[1:]):
+			q.maxCount, _ = strconv.Atoi(arg[1:])
+		case strings.HasPrefix(arg, "-"):
+			return nil, fmt.Errorf("%w: %s", ErrUnsupported, arg)
+		case strings.Contains(arg, "..."):
+			return nil, fmt.Errorf("%w: symmetric difference %s", ErrUnsupported, arg)
+		case strings.Contains(arg, ".."):
+			parts := strings.SplitN(arg, "..", 2)
+			q.exclude = append(q.exclude, orHead(parts[0]))
+			q.include = append(q.include, orHead(parts[1]))
+		case strings.HasPrefix(arg, "^"):
+			q.exclude = append(q.exclude, arg[1:])
+		default:
+			q.include = append(q.include, arg)
+		}
+	}
+
+	return q, nil
+}
+
+// setPretty handles --pretty= and --format= arguments
+func (q *revQuery) setPretty(arg string) error {
+	isFormat := strings.HasPrefix(arg, "--format=")
+	spec := arg[strings.Index(arg, "=")+1:]
+
+	switch {
+	case strings.HasPrefix(spec, "format:"):
+		q.style, q.format = "format", strings.TrimPrefix(spec, "format:")
+	case strings.HasPrefix(spec, "tformat:"):
+		q.style, q.format = "tformat", strings.TrimPrefix(spec, "tformat:")
+	case spec == "oneline":
+		q.style = "oneline"
+	case spec == "medium":
+		q.style = "medium"
+	case isFormat || strings.Contains(spec, "%"):
+		q.style, q.format = "tformat", spec
+	default:
+		return fmt.Errorf("%w: --pretty=%s", ErrUnsupported, spec)
+	}
+
+	return nil
+}
+
+// lineTerm returns the record terminator selected by -z
+func (q *revQuery) lineTerm() string {
+	if q.nulTerm {
+		return "\x00"
+	}
+	return "\n"
+}
+
+// defaultRenameScore is the similarity git requires of renames by default
+const defaultRenameScore = 50
+
+// parseSimilarity parses the optional value of -M or --find-renames=: a
+// percentage like "90%", or digits read as a fraction like git does, so
+// "9" and "90" both mean 90%
+func parseSimilarity(value string) (int, error) {
+	value = strings.TrimPrefix(value, "=")
+	if value == "" {
+		return defaultRenameScore, nil
+	}
+
+	if pct, ok := strings.CutSuffix(value, "%"); ok {
+		n, err := strconv.Atoi(pct)
+		if err != nil || n < 0 || n > 100 {
+			return 0, fmt.Errorf("invalid rename similarity %q", value)
+		}
+		return n, nil
+	}
+
+	if !isDigits(value) {
+		return 0, fmt.Errorf("invalid rename similarity %q", value)
+	}
+
+	fraction, _ := strconv.ParseFloat("0."+value, 64)
+	return int(fraction * 100), nil
+}
+
+func orHead(rev string) string {
+	if rev == "" {
+		return "HEAD"
+	}
+	return rev
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseApproxDate parses the date formats produced by BuildLogArgs and
+// the common absolute forms accepted by git
+func parseApproxDate(s string) (time.Time, error) {
+	if strings.HasPrefix(s, "@") {
+		secs, err := strconv.ParseInt(s[1:], 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q: %w", s, err)
+		}
+		return time.Unix(secs, 0), nil
+	}
+
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05 -0700",
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05",
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	// git's approxidate fills in the current time of day for bare dates
+	if d, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		now := time.Now()
+		return time.Date(d.Year(), d.Month(), d.Day(), now.Hour(), now.Minute(), now.Second(), 0, time.Local), nil
+	}
+
+	return time.Time{}, fmt.Errorf("%w: date %q", ErrUnsupported, s)
+}
+
+// pathSpec is a minimal implementation of git pathspecs supporting literal
+// prefixes, globs and the exclude magic (":!", ":^", ":(exclude)")
+type pathSpec struct {
+	include []string
+	exclude []string
+}
+
+func newPathSpec(specs []string) pathSpec {
+	var ps pathSpec
+
+	for _, spec := range specs {
+		switch {
+		case strings.HasPrefix(spec, ":!"), strings.HasPrefix(spec, ":^"):
+			ps.exclude = append(ps.exclude, spec[2:])
+		case strings.HasPrefix(spec, ":(exclude)"):
+			ps.exclude = append(ps.exclude, strings.TrimPrefix(spec, ":(exclude)"))
+		case strings.HasPrefix(spec, ":(top)"):
+			ps.include = append(ps.include, strings.TrimPrefix(spec, ":(top)"))
+		case strings.HasPrefix(spec, ":/"):
+			ps.include = append(ps.include, spec[2:])
+		default:
+			ps.include = append(ps.include, spec)
+		}
+	}
+
+	return ps
+}
+
+// MatchPathSpec reports whether a repository-relative path is selected by
+// specs, for commands like ls-tree that do not support pathspec magic
+func MatchPathSpec(specs []string, name string) bool {
+	return newPathSpec(specs).matches(name)
+}
+
+// empty reports whether the pathspec limits nothing
+func (p pathSpec) empty() bool {
+	return len(p.include) == 0 && len(p.exclude) == 0
+}
+
+// matches reports whether a repository-relative path is selected
+func (p pathSpec) matches(name string) bool {
+	for _, pattern := range p.exclude {
+		if matchPathPattern(pattern, name) {
+			return false
+		}
+	}
+
+	if len(p.include) == 0 {
+		return true
+	}
+
+	for _, pattern := range p.include {
+		if matchPathPattern(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPathPattern matches a single pathspec element. Like git, a
+// wildcard also matches across directory separators.
+func matchPathPattern(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if pattern == "" || pattern == "." {
+		return true
+	}
+
+	if strings.ContainsAny(pattern, "*?[") {
+		re, err := globToRegexp(pattern)
+		if err != nil {
+			return false
+		}
+		return re.MatchString(name)
+	}
+
+	pattern = strings.TrimSuffix(pattern, "/")
+	return name == pattern || strings.HasPrefix(name, pattern+"/")
+}
+
+// globToRegexp converts an fnmatch-style pattern without FNM_PATHNAME
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString("(/.*)?$")
+	return regexp.Compile(sb.String())
+}
+
+// fileChange represents a single entry of numstat or raw output
+type fileChange struct {
+	From      string
+	To        string
+	FromMode  filemode.FileMode
+	ToMode    filemode.FileMode
+	FromHash  plumbing.Hash
+	ToHash    plumbing.Hash
+	Additions int
+	Deletions int
+	Binary    bool
+}
+
+// Path returns the post-image path, or the pre-image path for deletions
+func (f fileChange) Path() string {
+	if f.To != "" {
+		return f.To
+	}
+	return f.From
+}
+
+// commitHeap orders commits by committer date, newest first, like git's
+// default revision walk
+type commitHeap []*object.Commit
+
+func (h commitHeap) Len() int { return len(h) }
+func (h commitHeap) Less(i, j int) bool {
+	return h[i].Committer.When.After(h[j].Committer.When)
+}
+func (h commitHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *commitHeap) Push(x any)   { *h = append(*h, x.(*object.Commit)) }
+func (h *commitHeap) Pop() any {
+	old := *h
+	n := len(old)
+	c := old[n-1]
+	*h = old[:n-1]
+	return c
+}
+
+// walk visits the commits selected by q in git log order. changes holds
+// the diff against the first parent when q needs it (numstat, name-only
+// or a pathspec); it is always empty for merge commits.
+func (b *GoGitBackend) walk(ctx context.Context, q *revQuery, fn func(c *object.Commit, changes []fileChange) error) error {
+	starts, err := b.startCommits(q)
+	if err != nil {
+		return err
+	}
+
+	hidden, err := b.hiddenCommits(ctx, q.exclude)
+	if err != nil {
+		return err
+	}
+
+	var decorated map[plumbing.Hash]bool
+	if q.simplifyByDecoration {
+		decorated, err = b.decoratedCommits()
+		if err != nil {
+			return err
+		}
+	}
+
+	wantChanges := q.numstat || q.nameOnly || q.raw || !q.paths.empty()
+
+	seen := make(map[plumbing.Hash]bool)
+	queue := &commitHeap{}
+
+	for _, c := range starts {
+		if !seen[c.Hash] && !hidden[c.Hash] {
+			seen[c.Hash] = true
+			heap.Push(queue, c)
+		}
+	}
+
+	var collected []*object.Commit
+	var collectedChanges [][]fileChange
+
+	skipped, emitted := 0, 0
+
+	for queue.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		c := heap.Pop(queue).(*object.Commit)
+
+		for i, parentHash := range c.ParentHashes {
+			if q.firstParent && i > 0 {
+				break
+			}
+			if seen[parentHash] || hidden[parentHash] {
+				continue
+			}
+			seen[parentHash] = true
+
+			parent, err := b.repo.CommitObject(parentHash)
+			if err != nil {
+				// Shallow clones end at commits whose parents are missing
+				if err == plumbing.ErrObjectNotFound {
+					continue
+				}
+				return err
+			}
+			heap.Push(queue, parent)
+		}
+
+		if !q.selects(c, b.mailmap) {
+			continue
+		}
+
+		if decorated != nil && !decorated[c.Hash] {
+			continue
+		}
+
+		var changes []fileChange
+		if wantChanges {
+			changes, err = b.commitChanges(ctx, c, q)
+			if err != nil {
+				return err
+			}
+
+			if !q.paths.empty() && len(changes) == 0 {
+				continue
+			}
+
+			if c.NumParents() > 1 || !(q.numstat || q.nameOnly || q.raw) {
+				changes = nil
+			}
+		}
+
+		if skipped < q.skip {
+			skipped++
+			continue
+		}
+
+		if q.maxCount >= 0 && emitted >= q.maxCount {
+			break
+		}
+		emitted++
+
+		if q.reverse {
+			collected = append(collected, c)
+			collectedChanges = append(collectedChanges, changes)
+			continue
+		}
+
+		if err := fn(c, changes); err != nil {
+			return err
+		}
+	}
+
+	for i := len(collected) - 1; i >= 0; i-- {
+		if err := fn(collected[i], collectedChanges[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// selects applies the commit-level filters of q. Like git with
+// log.mailmap, --author matches the mailmapped identity.
+func (q *revQuery) selects(c *object.Commit, mailmap *Mailmap) bool {
+	if q.noMerges && c.NumParents() > 1 {
+		return false
+	}
+
+	if q.merges && c.NumParents() < 2 {
+		return false
+	}
+
+	if !q.since.IsZero() && c.Committer.When.Before(q.since) {
+		return false
+	}
+
+	if !q.until.IsZero() && c.Committer.When.After(q.until) {
+		return false
+	}
+
+	if len(q.authors) > 0 {
+		name, email := mailmap.Resolve(c.Author.Name, c.Author.Email)
+		ident := name + " <" + email + ">"
+		matched := false
+		for _, re := range q.authors {
+			if re.MatchString(ident) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// startCommits resolves the positive revisions of q
+func (b *GoGitBackend) startCommits(q *revQuery) ([]*object.Commit, error) {
+	revs := q.include
+	if len(revs) == 0 && !q.all {
+		revs = []string{"HEAD"}
+	}
+
+	commits := make([]*object.Commit, 0, len(revs))
+
+	for _, rev := range revs {
+		c, err := b.resolveCommit(rev)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, c)
+	}
+
+	if q.all {
+		refs, err := b.repo.References()
+		if err != nil {
+			return nil, err
+		}
+
+		err = refs.ForEach(func(ref *plumbing.Reference) error {
+			if ref.Type() != plumbing.HashReference {
+				return nil
+			}
+			if c, err := b.peelCommit(ref.Hash()); err == nil {
+				commits = append(commits, c)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if head, err := b.resolveCommit("HEAD"); err == nil {
+			commits = append(commits, head)
+		}
+	}
+
+	return commits, nil
+}
+
+// hiddenCommits returns every commit reachable from the given revisions
+func (b *GoGitBackend) hiddenCommits(ctx context.Context, revs []string) (map[plumbing.Hash]bool, error) {
+	hidden := make(map[plumbing.Hash]bool)
+
+	for _, rev := range revs {
+		c, err := b.resolveCommit(rev)
+		if err != nil {
+			return nil, err
+		}
+
+		stack := []*object.Commit{c}
+		for len(stack) > 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			cur := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			if hidden[cur.Hash] {
+				continue
+			}
+			hidden[cur.Hash] = true
+
+			for _, parentHash := range cur.ParentHashes {
+				if hidden[parentHash] {
+					continue
+				}
+				parent, err := b.repo.CommitObject(parentHash)
+				if err != nil {
+					continue
+				}
+				stack = append(stack, parent)
+			}
+		}
+	}
+
+	return hidden, nil
+}
+
+// decoratedCommits returns the commits pointed to by any branch or tag
+func (b *GoGitBackend) decoratedCommits() (map[plumbing.Hash]bool, error) {
+	decorations, err := b.decorations()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[plumbing.Hash]bool, len(decorations))
+	for hash := range decorations {
+		result[hash] = true
+	}
+
+	return result, nil
+}
+
+// resolveCommit resolves a revision to a commit, peeling tags
+func (b *GoGitBackend) resolveCommit(rev string) (*object.Commit, error) {
+	hash, err := b.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("bad revision '%s': %w", rev, err)
+	}
+
+	return b.peelCommit(*hash)
+}
+
+// peelCommit follows annotated tags until a commit is reached
+func (b *GoGitBackend) peelCommit(hash plumbing.Hash) (*object.Commit, error) {
+	for {
+		tag, err := b.repo.TagObject(hash)
+		if err != nil {
+			break
+		}
+		hash = tag.Target
+	}
+
+	return b.repo.CommitObject(hash)
+}
+
+// commitChanges diffs a commit against its parents, restricted to q.paths.
+// For merges the result is non-empty only if the commit differs from every
+// parent, which approximates git's TREESAME history simplification.
+func (b *GoGitBackend) commitChanges(ctx context.Context, c *object.Commit, q *revQuery) ([]fileChange, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.NumParents() == 0 {
+		return b.diffTrees(ctx, nil, tree, q, q.numstat)
+	}
+
+	var changes []fileChange
+
+	for i, parentHash := range c.ParentHashes {
+		parent, err := b.repo.CommitObject(parentHash)
+		if err != nil {
+			return nil, err
+		}
+
+		parentTree, err := parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+
+		diff, err := b.diffTrees(ctx, parentTree, tree, q, q.numstat && i == 0)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(diff) == 0 {
+			return nil, nil
+		}
+
+		if i == 0 {
+			changes = diff
+		}
+	}
+
+	return changes, nil
+}
+
+// diffTrees computes the file changes between two trees, restricted to
+// q.paths and detecting renames as q asks. Line counts are only computed
+// when withStats is set, since they require blob contents.
+func (b *GoGitBackend) diffTrees(ctx context.Context, from, to *object.Tree, q *revQuery, withStats bool) ([]fileChange, error) {
+	paths := q.paths
+	opts := &object.DiffTreeOptions{DetectRenames: q.renameScore > 0, RenameScore: uint(q.renameScore)}
+
+	changes, err := object.DiffTreeWithOptions(ctx, from, to, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]fileChange, 0, len(changes))
+
+	for _, change := range changes {
+		fc := fileChange{
+			From:     change.From.Name,
+			To:       change.To.Name,
+			FromMode: change.From.TreeEntry.Mode,
+			ToMode:   change.To.TreeEntry.Mode,
+			FromHash: change.From.TreeEntry.Hash,
+			ToHash:   change.To.TreeEntry.Hash,
+		}
+
+		action, err := change.Action()
+		if err != nil {
+			return nil, err
+		}
+		if action == merkletrie.Insert {
+			fc.From = ""
+		}
+		if action == merkletrie.Delete {
+			fc.To = ""
+		}
+
+		if !paths.empty() && !paths.matches(fc.Path()) && (fc.From == "" || !paths.matches(fc.From)) {
+			continue
+		}
+
+		if withStats {
+			if err := fillLineStats(ctx, change, &fc); err != nil {
+				return nil, err
+			}
+		}
+
+		result = append(result, fc)
+	}
+
+	return result, nil
+}
+
+// fillLineStats counts added and deleted lines of a change
+func fillLineStats(ctx context.Context, change *object.Change, fc *fileChange) error {
+	if change.From.TreeEntry.Mode == filemode.Submodule || change.To.TreeEntry.Mode == filemode.Submodule {
+		fc.Additions, fc.Deletions = 1, 1
+		if change.From.Name == "" {
+			fc.Deletions = 0
+		}
+		if change.To.Name == "" {
+			fc.Additions = 0
+		}
+		return nil
+	}
+
+	patch, err := change.PatchContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, fp := range patch.FilePatches() {
+		if fp.IsBinary() {
+			fc.Binary = true
+			continue
+		}
+
+		for _, chunk := range fp.Chunks() {
+			content := chunk.Content()
+			if content == "" {
+				continue
+			}
+
+			lines := strings.Count(content, "\n")
+			if !strings.HasSuffix(content, "\n") {
+				lines++
+			}
+
+			switch chunk.Type() {
+			case fdiff.Add:
+				fc.Additions += lines
+			case fdiff.Delete:
+				fc.Deletions += lines
+			}
+		}
+	}
+
+	return nil
+}
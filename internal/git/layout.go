@@ -0,0 +1,99 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Layout describes where a repository lives on disk
+type Layout struct {
+	WorkTree  string // top level of the working tree, empty when bare
+	GitDir    string // git directory of the repository or linked worktree
+	CommonDir string // git directory shared by all worktrees
+	Bare      bool
+}
+
+// Linked reports whether the working tree is a linked worktree
+func (l *Layout) Linked() bool {
+	return l.GitDir != l.CommonDir
+}
+
+// Root returns the directory git commands should run in: the top level of
+// the working tree, or the git directory of a bare repository
+func (l *Layout) Root() string {
+	if l.Bare {
+		return l.GitDir
+	}
+	return l.WorkTree
+}
+
+// Locate resolves the layout of the repository containing the backend
+// path, which may be a working tree or any directory inside it, a linked
+// worktree, a bare repository or a git directory
+func Locate(backend Backend) (*Layout, error) {
+	output, err := backend.RevParse("--is-bare-repository", "--absolute-git-dir", "--git-common-dir")
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Split(strings.TrimSpace(output), "\n")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("unexpected rev-parse output: %q", output)
+	}
+
+	layout := &Layout{
+		Bare:      fields[0] == "true",
+		GitDir:    realPath(fields[1]),
+		CommonDir: fields[2],
+	}
+
+	// git prints the common dir relative to the working directory
+	if !filepath.IsAbs(layout.CommonDir) {
+		layout.CommonDir = filepath.Join(backend.RootPath(), layout.CommonDir)
+	}
+	layout.CommonDir = realPath(layout.CommonDir)
+
+	if layout.Bare {
+		return layout, nil
+	}
+
+	top, err := backend.RevParse("--show-toplevel")
+	switch {
+	case err == nil:
+		layout.WorkTree = realPath(strings.TrimSpace(top))
+	case filepath.Base(layout.GitDir) == ".git":
+		// Opened from inside the git directory of a regular repository
+		layout.WorkTree = filepath.Dir(layout.GitDir)
+	default:
+		return nil, err
+	}
+
+	return layout, nil
+}
+
+// IsGitDir reports whether dir looks like a git directory: a bare
+// repository or the .git directory of a working tree
+func IsGitDir(dir string) bool {
+	if info, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil || info.IsDir() {
+		return false
+	}
+
+	for _, sub := range []string{"objects", "refs"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// realPath cleans path and resolves symlinks when possible, so paths
+// reported by git and go-git compare equal
+func realPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
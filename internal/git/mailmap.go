@@ -0,0 +1,125 @@
+package git
+
+import (
+	"strings"
+)
+
+// Mailmap maps commit identities to canonical ones following the rules of
+// gitmailmap(5). Names and emails are matched case-insensitively.
+type Mailmap struct {
+	entries map[string]*mailmapEntry // keyed by lowercased commit email
+}
+
+// mailmapEntry holds the replacements for a single commit email
+type mailmapEntry struct {
+	name  string
+	email string
+	names map[string]mailmapIdentity // keyed by lowercased commit name
+}
+
+type mailmapIdentity struct {
+	name  string
+	email string
+}
+
+// ParseMailmap parses the contents of a .mailmap file. Malformed lines are
+// ignored, as git does.
+func ParseMailmap(data string) *Mailmap {
+	m := &Mailmap{entries: make(map[string]*mailmapEntry)}
+
+	for _, line := range strings.Split(data, "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		name1, email1, rest, ok := parseMailmapIdent(line)
+		if !ok {
+			continue
+		}
+
+		name2, email2, _, ok := parseMailmapIdent(rest)
+		if !ok {
+			// "Proper Name <commit@email>"
+			m.add(name1, "", "", email1)
+			continue
+		}
+
+		m.add(name1, email1, name2, email2)
+	}
+
+	return m
+}
+
+// parseMailmapIdent reads an optional name followed by <email>
+func parseMailmapIdent(s string) (name, email, rest string, ok bool) {
+	open := strings.IndexByte(s, '<')
+	if open < 0 {
+		return "", "", "", false
+	}
+
+	end := strings.IndexByte(s[open:], '>')
+	if end < 0 {
+		return "", "", "", false
+	}
+
+	name = strings.TrimSpace(s[:open])
+	email = strings.TrimSpace(s[open+1 : open+end])
+	return name, email, s[open+end+1:], true
+}
+
+// add records a mapping of commit name/email to a proper name/email
+func (m *Mailmap) add(properName, properEmail, commitName, commitEmail string) {
+	key := strings.ToLower(commitEmail)
+
+	entry, ok := m.entries[key]
+	if !ok {
+		entry = &mailmapEntry{}
+		m.entries[key] = entry
+	}
+
+	if commitName == "" {
+		if properName != "" {
+			entry.name = properName
+		}
+		if properEmail != "" {
+			entry.email = properEmail
+		}
+		return
+	}
+
+	if entry.names == nil {
+		entry.names = make(map[string]mailmapIdentity)
+	}
+	entry.names[strings.ToLower(commitName)] = mailmapIdentity{name: properName, email: properEmail}
+}
+
+// Resolve returns the canonical name and email for a commit identity
+func (m *Mailmap) Resolve(name, email string) (string, string) {
+	if m == nil {
+		return name, email
+	}
+
+	entry, ok := m.entries[strings.ToLower(email)]
+	if !ok {
+		return name, email
+	}
+
+	if ident, ok := entry.names[strings.ToLower(name)]; ok {
+		if ident.name != "" {
+			name = ident.name
+		}
+		if ident.email != "" {
+			email = ident.email
+		}
+		return name, email
+	}
+
+	if entry.name != "" {
+		name = entry.name
+	}
+	if entry.email != "" {
+		email = entry.email
+	}
+
+	return name, email
+}
@@ -0,0 +1,37 @@
+package git
+
+import "testing"
+
+func TestMailmapResolve(t *testing.T) {
+	mailmap := ParseMailmap(`# team mailmap
+Jane Doe <jane@example.com>
+<joe@example.com> <joe@old.example.com>
+Joe Proper <joe@example.com> <JOE@laptop.local>
+Ann Smith <ann@example.com> ann <shared@example.com>
+Bob Shared <bob@example.com> Bob <shared@example.com>
+not a valid line
+`)
+
+	tests := []struct {
+		name, email         string
+		wantName, wantEmail string
+	}{
+		{"jane", "jane@example.com", "Jane Doe", "jane@example.com"},
+		{"jane", "JANE@example.com", "Jane Doe", "JANE@example.com"},
+		{"Joe", "joe@old.example.com", "Joe", "joe@example.com"},
+		{"joe", "joe@laptop.local", "Joe Proper", "joe@example.com"},
+		{"Ann", "shared@example.com", "Ann Smith", "ann@example.com"},
+		{"bob", "shared@example.com", "Bob Shared", "bob@example.com"},
+		{"Eve", "shared@example.com", "Eve", "shared@example.com"},
+		{"Unknown", "unknown@example.com", "Unknown", "unknown@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" "+tt.email, func(t *testing.T) {
+			name, email := mailmap.Resolve(tt.name, tt.email)
+			if name != tt.wantName || email != tt.wantEmail {
+				t.Errorf("Resolve() = %q <%s>, want %q <%s>", name, email, tt.wantName, tt.wantEmail)
+			}
+		})
+	}
+}
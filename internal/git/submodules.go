@@ -0,0 +1,64 @@
+package git
+
+import (
+	"strings"
+)
+
+// SubmoduleConfig is a submodule entry of .gitmodules
+type SubmoduleConfig struct {
+	Name   string
+	Path   string
+	URL    string
+	Branch string
+}
+
+// ParseGitmodules parses the contents of a .gitmodules file, keeping the
+// order of the entries. Entries without a path are dropped.
+func ParseGitmodules(data string) []SubmoduleConfig {
+	var result []SubmoduleConfig
+	var current *SubmoduleConfig
+
+	flush := func() {
+		if current != nil && current.Path != "" {
+			result = append(result, *current)
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			flush()
+
+			section := strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+			kind, name, ok := strings.Cut(section, " ")
+			if ok && strings.EqualFold(kind, "submodule") {
+				current = &SubmoduleConfig{Name: strings.Trim(strings.TrimSpace(name), `"`)}
+			}
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, "=")
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "path":
+			current.Path = value
+		case "url":
+			current.URL = value
+		case "branch":
+			current.Branch = value
+		}
+	}
+
+	flush()
+	return result
+}
@@ -0,0 +1,32 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGitmodules(t *testing.T) {
+	got := ParseGitmodules(`# vendored libraries
+[submodule "lib/core"]
+	path = lib/core
+	url = https://example.com/core.git
+	branch = main
+[core]
+	path = ignored
+[submodule "docs"]
+	; no path, dropped
+	url = https://example.com/docs.git
+[submodule "theme"]
+	path = "web/theme"
+	URL=../theme.git
+`)
+
+	want := []SubmoduleConfig{
+		{Name: "lib/core", Path: "lib/core", URL: "https://example.com/core.git", Branch: "main"},
+		{Name: "theme", Path: "web/theme", URL: "../theme.git"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseGitmodules() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+// Package gittest builds throwaway repositories for tests, so results can
+// be asserted exactly instead of only sanity-checked
+package gittest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Repo is a repository in a temporary directory, removed with the test
+type Repo struct {
+	t   testing.TB
+	Dir string
+}
+
+// New initializes an empty repository with a main branch, skipping the
+// test when git isn't installed
+func New(t testing.TB) *Repo {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	r := &Repo{t: t, Dir: t.TempDir()}
+	r.Git(time.Time{}, "init", "-q", "-b", "main")
+
+	return r
+}
+
+// Git runs a git command inside the repository with a deterministic
+// identity, authored and committed at date (2024-01-01 noon UTC if zero)
+func (r *Repo) Git(date time.Time, args ...string) string {
+	r.t.Helper()
+
+	if date.IsZero() {
+		date = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	stamp := date.Format(time.RFC3339)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=Fixture Author",
+		"GIT_AUTHOR_EMAIL=author@example.com",
+		"GIT_COMMITTER_NAME=Fixture Committer",
+		"GIT_COMMITTER_EMAIL=committer@example.com",
+		"GIT_COMMITTER_DATE="+stamp,
+		"GIT_AUTHOR_DATE="+stamp,
+	)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+
+	return string(out)
+}
+
+// Write creates or overwrites a file in the working tree
+func (r *Repo) Write(path, content string) {
+	r.t.Helper()
+
+	full := filepath.Join(r.Dir, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		r.t.Fatal(err)
+	}
+
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// Commit stages everything and commits it as the given author
+func (r *Repo) Commit(author, email, message string, date time.Time) {
+	r.t.Helper()
+
+	r.Git(date, "add", "-A")
+	r.Git(date, "commit", "-q", "--allow-empty", "--author", author+" <"+email+">", "-m", message)
+}
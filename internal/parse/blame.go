@@ -0,0 +1,85 @@
+package parse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlameLine is one line of git blame --line-porcelain output
+type BlameLine struct {
+	Hash   string
+	Author string
+	Email  string
+	Date   time.Time
+	Text   string
+}
+
+// ParseBlamePorcelain parses git blame --line-porcelain output. Every line
+// of the file is preceded by its full commit header, so the output can be
+// read without tracking previously seen commits.
+func ParseBlamePorcelain(output string) ([]BlameLine, error) {
+	lines := make([]BlameLine, 0)
+	var current *BlameLine
+	var tz string
+
+	for _, raw := range strings.Split(output, "\n") {
+		if current == nil {
+			if raw == "" {
+				continue
+			}
+
+			hash, _, _ := strings.Cut(raw, " ")
+			if len(hash) < 40 {
+				return nil, fmt.Errorf("invalid blame header: %q", raw)
+			}
+
+			current = &BlameLine{Hash: hash}
+			tz = ""
+			continue
+		}
+
+		// The content line ends the entry
+		if text, ok := strings.CutPrefix(raw, "\t"); ok {
+			current.Text = text
+			if tz != "" {
+				current.Date = current.Date.In(parseTimezone(tz))
+			}
+			lines = append(lines, *current)
+			current = nil
+			continue
+		}
+
+		key, value, _ := strings.Cut(raw, " ")
+		switch key {
+		case "author":
+			current.Author = value
+		case "author-mail":
+			current.Email = strings.TrimSuffix(strings.TrimPrefix(value, "<"), ">")
+		case "author-time":
+			sec, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid author-time %q: %w", value, err)
+			}
+			current.Date = time.Unix(sec, 0)
+		case "author-tz":
+			tz = value
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("truncated blame entry for %s", current.Hash)
+	}
+
+	return lines, nil
+}
+
+// parseTimezone converts a +hhmm offset to a fixed zone
+func parseTimezone(tz string) *time.Location {
+	t, err := time.Parse("-0700", tz)
+	if err != nil {
+		return time.UTC
+	}
+	return t.Location()
+}
@@ -0,0 +1,81 @@
+package parse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBlamePorcelain(t *testing.T) {
+	const hash1 = "37723967f24015a7eaa6f2475528f06ea788adb7"
+	const hash2 = "6e5a9863c55edb05439635e4ee9d6cadcb61c2ef"
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []BlameLine
+		wantErr bool
+	}{
+		{
+			name:  "empty file",
+			input: "",
+			want:  []BlameLine{},
+		},
+		{
+			name: "two commits",
+			input: hash1 + " 1 1 2\n" +
+				"author Alice\nauthor-mail <alice@example.com>\nauthor-time 1714980600\nauthor-tz +0200\n" +
+				"committer Alice\ncommitter-mail <alice@example.com>\ncommitter-time 1714980600\ncommitter-tz +0200\n" +
+				"summary feat: initial import\nboundary\nfilename src/main.go\n\tpackage main\n" +
+				hash1 + " 2 2\n" +
+				"author Alice\nauthor-mail <alice@example.com>\nauthor-time 1714980600\nauthor-tz +0200\n" +
+				"summary feat: initial import\nfilename src/main.go\n\t\n" +
+				hash2 + " 3 3 1\n" +
+				"author Bob\nauthor-mail <bob@example.com>\nauthor-time 1715074200\nauthor-tz -0500\n" +
+				"summary fix\nprevious " + hash1 + " src/main.go\nfilename src/main.go\n\t\tfunc main() {}\n",
+			want: []BlameLine{
+				{Hash: hash1, Author: "Alice", Email: "alice@example.com", Date: time.Unix(1714980600, 0), Text: "package main"},
+				{Hash: hash1, Author: "Alice", Email: "alice@example.com", Date: time.Unix(1714980600, 0), Text: ""},
+				{Hash: hash2, Author: "Bob", Email: "bob@example.com", Date: time.Unix(1715074200, 0), Text: "\tfunc main() {}"},
+			},
+		},
+		{
+			name:    "truncated entry",
+			input:   hash1 + " 1 1 1\nauthor Alice\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid header",
+			input:   "not a blame header\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBlamePorcelain(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseBlamePorcelain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseBlamePorcelain() = %+v, want %+v", got, tt.want)
+			}
+
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if g.Hash != w.Hash || g.Author != w.Author || g.Email != w.Email || g.Text != w.Text || !g.Date.Equal(w.Date) {
+					t.Errorf("line %d = %+v, want %+v", i, g, w)
+				}
+			}
+
+			if len(got) == 3 {
+				if _, offset := got[2].Date.Zone(); offset != -5*3600 {
+					t.Errorf("line 2 zone offset = %d, want -0500", offset)
+				}
+			}
+		})
+	}
+}
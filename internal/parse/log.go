@@ -1,23 +1,37 @@
 package parse
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// ErrParseError is returned when git output can't be parsed
+var ErrParseError = errors.New("failed to parse git output")
+
+// malformed returns an ErrParseError describing the offending output
+func malformed(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrParseError, fmt.Sprintf(format, args...))
+}
+
 // CommitInfo represents parsed commit information
 type CommitInfo struct {
-	Hash      string
-	Author    string
-	Email     string
-	Date      time.Time
-	Subject   string
-	Body      string
-	Additions int
-	Deletions int
-	Files     []string
+	Hash       string
+	Parents    []string
+	Author     string
+	Email      string
+	Date       time.Time // date the analyses use: AuthorDate, or CommitDate when selected
+	AuthorDate time.Time
+	CommitDate time.Time
+	Subject    string
+	Body       string    // message after the subject paragraph
+	Trailers   []Trailer // trailer block ending the body
+	Additions  int
+	Deletions  int
+	Files      []string
+	Changes    []FileStats
 }
 
 // AuthorInfo represents parsed author information
@@ -27,92 +41,18 @@ type AuthorInfo struct {
 	Count int
 }
 
-// ParseCommitLog parses git log output with custom format
-// Expected format: hash|author|email|date|subject
-// When --numstat is used, subsequent lines contain: additions\tdeletions\tfilename
+// ParseCommitLog parses the output of git log run with StreamArgs. Records
+// that can't be parsed are reported as ErrParseError.
 func ParseCommitLog(output string) ([]CommitInfo, error) {
-	if output == "" {
-		return []CommitInfo{}, nil
-	}
-
-	lines := strings.Split(output, "\n")
 	commits := make([]CommitInfo, 0)
-	var currentCommit *CommitInfo
 
-	for i := 0; i < len(lines); i++ {
-		line := lines[i]
-
-		// Empty line - finalize current commit if exists
-		if strings.TrimSpace(line) == "" {
-			if currentCommit != nil {
-				commits = append(commits, *currentCommit)
-				currentCommit = nil
-			}
-			continue
-		}
-
-		// Check if this is a commit header line (contains |)
-		if strings.Contains(line, "|") {
-			parts := strings.Split(line, "|")
-			if len(parts) >= 5 {
-				// Finalize previous commit if exists
-				if currentCommit != nil {
-					commits = append(commits, *currentCommit)
-				}
-
-				// Parse date
-				date, err := time.Parse("2006-01-02 15:04:05 -0700", parts[3])
-				if err != nil {
-					// Try alternative formats
-					date, err = time.Parse(time.RFC3339, parts[3])
-					if err != nil {
-						// Try ISO format
-						date, _ = time.Parse("2006-01-02T15:04:05-07:00", parts[3])
-					}
-				}
-
-				// Create new commit
-				currentCommit = &CommitInfo{
-					Hash:      parts[0],
-					Author:    parts[1],
-					Email:     parts[2],
-					Date:      date,
-					Subject:   parts[4],
-					Additions: 0,
-					Deletions: 0,
-					Files:     make([]string, 0),
-				}
-			}
-			continue
-		}
-
-		// Check if this is a numstat line (contains tabs)
-		if currentCommit != nil && strings.Contains(line, "\t") {
-			parts := strings.Split(line, "\t")
-			if len(parts) >= 3 {
-				// Parse additions and deletions
-				additions, err1 := strconv.Atoi(parts[0])
-				deletions, err2 := strconv.Atoi(parts[1])
-
-				// Handle binary files (marked as "-")
-				if err1 != nil {
-					additions = 0
-				}
-				if err2 != nil {
-					deletions = 0
-				}
-
-				// Accumulate stats
-				currentCommit.Additions += additions
-				currentCommit.Deletions += deletions
-				currentCommit.Files = append(currentCommit.Files, parts[2])
-			}
-		}
+	scanner := NewCommitScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		commits = append(commits, scanner.Commit())
 	}
 
-	// Don't forget the last commit
-	if currentCommit != nil {
-		commits = append(commits, *currentCommit)
+	if err := scanner.Err(); err != nil {
+		return nil, err
 	}
 
 	return commits, nil
@@ -237,8 +177,22 @@ func ParseNumstat(output string) ([]FileStats, error) {
 // FileStats represents file change statistics
 type FileStats struct {
 	File      string
+	OldFile   string // source path of a rename or copy, empty otherwise
+	Copied    bool   // OldFile was copied rather than renamed
 	Additions int
 	Deletions int
+	Binary    bool
+
+	// Blobs before and after the change, empty when the file didn't exist
+	OldBlob string
+	NewBlob string
+
+	// Size of the file after the change and the bytes it gained, filled
+	// in for binary files and Git LFS pointers only. For LFS pointers
+	// they are the sizes of the stored objects rather than the pointers.
+	Size      int64
+	SizeDelta int64
+	LFS       bool
 }
 
 // ParseBranches parses git branch output
@@ -1,6 +1,7 @@
 package parse
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -21,25 +22,23 @@ func TestParseCommitLog(t *testing.T) {
 		},
 		{
 			name:    "single commit",
-			input:   "abc123|John Doe|john@example.com|2024-01-01 10:00:00 +0000|Initial commit",
+			input:   "\x1eabc123\x00\x00John Doe\x00john@example.com\x002024-01-01T10:00:00Z\x002024-01-01T10:00:00Z\x00Initial commit\x00\x00",
 			want:    1,
 			wantErr: false,
 		},
 		{
 			name: "multiple commits",
-			input: `abc123|John Doe|john@example.com|2024-01-01 10:00:00 +0000|Initial commit
-def456|Jane Smith|jane@example.com|2024-01-02 11:00:00 +0000|Add feature
-ghi789|Bob Johnson|bob@example.com|2024-01-03 12:00:00 +0000|Fix bug`,
+			input: "\x1eabc123\x00\x00John Doe\x00john@example.com\x002024-01-01T10:00:00Z\x002024-01-01T10:00:00Z\x00Initial commit\x00\x00" +
+				"\x1edef456\x00abc123\x00Jane Smith\x00jane@example.com\x002024-01-02T11:00:00Z\x002024-01-02T11:00:00Z\x00Add feature\x00\x00" +
+				"\x1eghi789\x00def456\x00Bob Johnson\x00bob@example.com\x002024-01-03T12:00:00Z\x002024-01-03T12:00:00Z\x00fix: a|b handling\x00\x00",
 			want:    3,
 			wantErr: false,
 		},
 		{
-			name: "malformed line",
-			input: `abc123|John Doe|john@example.com|2024-01-01 10:00:00 +0000|Initial commit
-invalid line
-def456|Jane Smith|jane@example.com|2024-01-02 11:00:00 +0000|Add feature`,
-			want:    2, // Should skip invalid line
-			wantErr: false,
+			name: "malformed record",
+			input: "\x1eabc123\x00\x00John Doe\x00john@example.com\x002024-01-01T10:00:00Z\x002024-01-01T10:00:00Z\x00Initial commit\x00\x00" +
+				"\x1einvalid record",
+			wantErr: true,
 		},
 	}
 
@@ -51,6 +50,13 @@ def456|Jane Smith|jane@example.com|2024-01-02 11:00:00 +0000|Add feature`,
 				return
 			}
 
+			if tt.wantErr {
+				if !errors.Is(err, ErrParseError) {
+					t.Errorf("ParseCommitLog() error = %v, want ErrParseError", err)
+				}
+				return
+			}
+
 			if len(got) != tt.want {
 				t.Errorf("ParseCommitLog() returned %d commits, want %d", len(got), tt.want)
 			}
@@ -458,9 +464,9 @@ func TestParseHour(t *testing.T) {
 
 // Benchmark tests
 func BenchmarkParseCommitLog(b *testing.B) {
-	input := `abc123|John Doe|john@example.com|2024-01-01 10:00:00 +0000|Initial commit
-def456|Jane Smith|jane@example.com|2024-01-02 11:00:00 +0000|Add feature
-ghi789|Bob Johnson|bob@example.com|2024-01-03 12:00:00 +0000|Fix bug`
+	input := "\x1eabc123\x00\x00John Doe\x00john@example.com\x002024-01-01T10:00:00Z\x002024-01-01T10:00:00Z\x00Initial commit\x00\x00" +
+		"\x1edef456\x00abc123\x00Jane Smith\x00jane@example.com\x002024-01-02T11:00:00Z\x002024-01-02T11:00:00Z\x00Add feature\x00\x00" +
+		"\x1eghi789\x00def456\x00Bob Johnson\x00bob@example.com\x002024-01-03T12:00:00Z\x002024-01-03T12:00:00Z\x00Fix bug\x00\x00"
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -0,0 +1,235 @@
+package parse
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StreamFormat is the pretty format read by CommitScanner. It must be
+// used as "--pretty=format:" together with -z, optionally with --numstat.
+// Each record starts with a record separator so commits can be split
+// without buffering the whole output, and fields are NUL-terminated so
+// messages and paths may contain anything else. Author identities respect
+// .mailmap.
+const StreamFormat = "%x1e%H%x00%P%x00%aN%x00%aE%x00%aI%x00%cI%x00%s%x00%b%x00"
+
+// StreamArgs returns the git log arguments matching StreamFormat. Per-file
+// changes are only requested when numstat is set, as they require diffs.
+// They come with --raw entries, which carry the blobs of every change.
+func StreamArgs(numstat bool) []string {
+	args := []string{"--pretty=format:" + StreamFormat, "-z"}
+	if numstat {
+		args = append(args, "--raw", "--no-abbrev", "--numstat")
+	}
+	return args
+}
+
+const (
+	recordSep    = '\x1e'
+	streamFields = 8
+)
+
+// CommitScanner reads commits one at a time from git log output produced
+// with StreamArgs. Memory use is bounded by the size of a single commit.
+type CommitScanner struct {
+	r       *bufio.Reader
+	commit  CommitInfo
+	records int
+	err     error
+}
+
+// NewCommitScanner creates a scanner reading from r
+func NewCommitScanner(r io.Reader) *CommitScanner {
+	return &CommitScanner{r: bufio.NewReaderSize(r, 64*1024)}
+}
+
+// Scan advances to the next commit, returning false at the end of the
+// output or on error
+func (s *CommitScanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+
+	for {
+		record, err := s.r.ReadBytes(recordSep)
+		if err != nil && err != io.EOF {
+			s.err = err
+			return false
+		}
+
+		record = bytes.TrimSuffix(record, []byte{recordSep})
+		if len(record) > 0 {
+			s.records++
+
+			commit, perr := parseStreamRecord(record)
+			if perr != nil {
+				s.err = fmt.Errorf("record %d: %w", s.records, perr)
+				return false
+			}
+			s.commit = commit
+			return true
+		}
+
+		if err == io.EOF {
+			return false
+		}
+	}
+}
+
+// Commit returns the commit read by the last call to Scan
+func (s *CommitScanner) Commit() CommitInfo {
+	return s.commit
+}
+
+// Err returns the first error encountered by Scan. Records that can't be
+// parsed are reported as ErrParseError.
+func (s *CommitScanner) Err() error {
+	return s.err
+}
+
+// parseStreamRecord parses a single StreamFormat record and its numstat
+// entries
+func parseStreamRecord(record []byte) (CommitInfo, error) {
+	fields := strings.SplitN(string(record), "\x00", streamFields+1)
+	if len(fields) < streamFields {
+		return CommitInfo{}, malformed("truncated commit record %q", record)
+	}
+
+	if fields[0] == "" {
+		return CommitInfo{}, malformed("commit record without hash: %q", record)
+	}
+
+	date, err := time.Parse(time.RFC3339, fields[4])
+	if err != nil {
+		return CommitInfo{}, malformed("commit %s: invalid date %q", fields[0], fields[4])
+	}
+
+	committed, err := time.Parse(time.RFC3339, fields[5])
+	if err != nil {
+		return CommitInfo{}, malformed("commit %s: invalid committer date %q", fields[0], fields[5])
+	}
+
+	commit := CommitInfo{
+		Hash:       fields[0],
+		Parents:    strings.Fields(fields[1]),
+		Author:     fields[2],
+		Email:      fields[3],
+		Date:       date,
+		AuthorDate: date,
+		CommitDate: committed,
+		Subject:    fields[6],
+		Body:       strings.TrimRight(fields[7], "\n"),
+	}
+	commit.Trailers = ParseTrailers(commit.Body)
+
+	if len(fields) > streamFields {
+		changes, err := parseNumstatZ(fields[streamFields])
+		if err != nil {
+			return CommitInfo{}, fmt.Errorf("commit %s: %w", commit.Hash, err)
+		}
+
+		commit.Changes = changes
+		for _, change := range changes {
+			commit.Files = append(commit.Files, change.File)
+			commit.Additions += change.Additions
+			commit.Deletions += change.Deletions
+		}
+	}
+
+	return commit, nil
+}
+
+// parseNumstatZ parses the NUL-terminated entries of --numstat -z. Renames
+// are written as "add\tdel\t\x00old\x00new\x00". Entries of --raw, which
+// precede them, give the blobs of each change and tell copies from renames.
+func parseNumstatZ(data string) ([]FileStats, error) {
+	tokens := strings.Split(strings.TrimPrefix(data, "\n"), "\x00")
+	var stats []FileStats
+
+	copies := make(map[string]string) // destination -> source
+	blobs := make(map[string][2]string)
+
+	for i := 0; i < len(tokens); i++ {
+		token := strings.TrimPrefix(tokens[i], "\n")
+		if token == "" {
+			continue
+		}
+
+		// Raw entries: ":mode mode hash hash status\x00path\x00[path\x00]"
+		if strings.HasPrefix(token, ":") {
+			fields := strings.Fields(token)
+			if len(fields) != 5 || i+1 >= len(tokens) {
+				return nil, malformed("raw entry %q", token)
+			}
+
+			status := fields[4]
+			blob := [2]string{rawBlob(fields[2]), rawBlob(fields[3])}
+
+			if status[0] != 'R' && status[0] != 'C' {
+				blobs[tokens[i+1]] = blob
+				i++
+				continue
+			}
+
+			if i+2 >= len(tokens) {
+				return nil, malformed("truncated raw entry %q", token)
+			}
+			if status[0] == 'C' {
+				copies[tokens[i+2]] = tokens[i+1]
+			}
+			blobs[tokens[i+2]] = blob
+			i += 2
+			continue
+		}
+
+		parts := strings.SplitN(token, "\t", 3)
+		if len(parts) != 3 {
+			return nil, malformed("numstat entry %q", token)
+		}
+
+		stat := FileStats{File: parts[2]}
+
+		if parts[2] == "" {
+			if i+2 >= len(tokens) {
+				return nil, malformed("truncated rename entry %q", token)
+			}
+			stat.OldFile, stat.File = tokens[i+1], tokens[i+2]
+			stat.Copied = copies[stat.File] == stat.OldFile
+			i += 2
+		}
+
+		if blob, ok := blobs[stat.File]; ok {
+			stat.OldBlob, stat.NewBlob = blob[0], blob[1]
+		}
+
+		// Binary files are reported as "-"
+		if parts[0] == "-" && parts[1] == "-" {
+			stat.Binary = true
+		} else {
+			additions, err1 := strconv.Atoi(parts[0])
+			deletions, err2 := strconv.Atoi(parts[1])
+			if err1 != nil || err2 != nil {
+				return nil, malformed("numstat counts %q", token)
+			}
+			stat.Additions, stat.Deletions = additions, deletions
+		}
+
+		stats = append(stats, stat)
+	}
+
+	return stats, nil
+}
+
+// rawBlob returns the blob of a --raw entry, empty for the null hash of a
+// side that doesn't exist
+func rawBlob(hash string) string {
+	if strings.Trim(hash, "0") == "" {
+		return ""
+	}
+	return hash
+}
@@ -0,0 +1,132 @@
+package parse
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommitScanner(t *testing.T) {
+	date := time.Date(2024, 1, 2, 10, 0, 0, 0, time.FixedZone("", 2*3600))
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []CommitInfo
+		wantErr bool
+	}{
+		{
+			name:  "empty input",
+			input: "",
+			want:  nil,
+		},
+		{
+			name:  "commit without changes",
+			input: "\x1eabc\x00\x00Jane\x00jane@example.com\x002024-01-02T10:00:00+02:00\x002024-01-02T10:00:00+02:00\x00Initial\x00",
+			want: []CommitInfo{
+				{Hash: "abc", Parents: []string{}, Author: "Jane", Email: "jane@example.com", Date: date, AuthorDate: date, CommitDate: date, Subject: "Initial"},
+			},
+		},
+		{
+			name: "numstat with rename and binary",
+			input: "\x1edef\x00abc\x00Jane\x00jane@example.com\x002024-01-02T10:00:00+02:00\x002024-01-02T10:00:00+02:00\x00Move\x00\x00\n" +
+				"3\t1\tmain.go\x00-\t-\tlogo.png\x001\t0\t\x00old.go\x00new.go\x00" +
+				"\x00\x1eabc\x00\x00Bob\x00bob@example.com\x002024-01-02T10:00:00+02:00\x002024-01-02T10:00:00+02:00\x00Initial\x00",
+			want: []CommitInfo{
+				{
+					Hash: "def", Parents: []string{"abc"}, Author: "Jane", Email: "jane@example.com",
+					Date: date, AuthorDate: date, CommitDate: date, Subject: "Move", Additions: 4, Deletions: 1,
+					Files: []string{"main.go", "logo.png", "new.go"},
+					Changes: []FileStats{
+						{File: "main.go", Additions: 3, Deletions: 1},
+						{File: "logo.png", Binary: true},
+						{File: "new.go", OldFile: "old.go", Additions: 1},
+					},
+				},
+				{Hash: "abc", Parents: []string{}, Author: "Bob", Email: "bob@example.com", Date: date, AuthorDate: date, CommitDate: date, Subject: "Initial"},
+			},
+		},
+		{
+			name:  "merge commit",
+			input: "\x1em1\x00p1 p2\x00Jane\x00jane@example.com\x002024-01-02T10:00:00+02:00\x002024-01-02T10:00:00+02:00\x00Merge | branch\x00",
+			want: []CommitInfo{
+				{Hash: "m1", Parents: []string{"p1", "p2"}, Author: "Jane", Email: "jane@example.com", Date: date, AuthorDate: date, CommitDate: date, Subject: "Merge | branch"},
+			},
+		},
+		{
+			name: "pipes and newlines in messages and paths",
+			input: "\x1eabc\x00\x00Jane | Doe\x00jane@example.com\x002024-01-02T10:00:00+02:00\x002024-01-02T10:00:00+02:00\x00fix: a|b handling\x00" +
+				"Split on | no more.\n\nabc|def|ghi|jkl|mno\n\x00\n2\t0\tdocs/a|b.md\x00\x00",
+			want: []CommitInfo{
+				{
+					Hash: "abc", Parents: []string{}, Author: "Jane | Doe", Email: "jane@example.com", Date: date, AuthorDate: date, CommitDate: date,
+					Subject: "fix: a|b handling", Body: "Split on | no more.\n\nabc|def|ghi|jkl|mno",
+					Additions: 2, Files: []string{"docs/a|b.md"}, Changes: []FileStats{{File: "docs/a|b.md", Additions: 2}},
+				},
+			},
+		},
+		{
+			name: "raw entries with blobs, copies and renames",
+			input: "\x1eabc\x00\x00Jane\x00jane@example.com\x002024-01-02T10:00:00+02:00\x002024-01-02T10:00:00+02:00\x00Copy\x00\x00\n" +
+				":100644 100644 96cc558 b991fe9 C098\x00a.txt\x00b.txt\x00" +
+				":100644 100644 96cc558 96cc558 R100\x00a.txt\x00c.txt\x00" +
+				":100644 100644 1111111 2222222 M\x00d.txt\x00" +
+				":000000 100644 0000000 3333333 A\x00e.bin\x00" +
+				":100644 000000 4444444 0000000 D\x00f.bin\x00" +
+				"1\t0\t\x00a.txt\x00b.txt\x000\t0\t\x00a.txt\x00c.txt\x002\t2\td.txt\x00-\t-\te.bin\x00-\t-\tf.bin\x00",
+			want: []CommitInfo{
+				{
+					Hash: "abc", Parents: []string{}, Author: "Jane", Email: "jane@example.com", Date: date, AuthorDate: date, CommitDate: date,
+					Subject: "Copy", Additions: 3, Deletions: 2, Files: []string{"b.txt", "c.txt", "d.txt", "e.bin", "f.bin"},
+					Changes: []FileStats{
+						{File: "b.txt", OldFile: "a.txt", Copied: true, Additions: 1, OldBlob: "96cc558", NewBlob: "b991fe9"},
+						{File: "c.txt", OldFile: "a.txt", OldBlob: "96cc558", NewBlob: "96cc558"},
+						{File: "d.txt", Additions: 2, Deletions: 2, OldBlob: "1111111", NewBlob: "2222222"},
+						{File: "e.bin", Binary: true, NewBlob: "3333333"},
+						{File: "f.bin", Binary: true, OldBlob: "4444444"},
+					},
+				},
+			},
+		},
+		{
+			name:    "invalid date",
+			input:   "\x1eabc\x00\x00Jane\x00jane@example.com\x00yesterday\x00yesterday\x00Initial\x00",
+			wantErr: true,
+		},
+		{
+			name:    "malformed numstat",
+			input:   "\x1eabc\x00\x00Jane\x00jane@example.com\x002024-01-02T10:00:00+02:00\x002024-01-02T10:00:00+02:00\x00Initial\x00\x00\nx\ty\tmain.go\x00",
+			wantErr: true,
+		},
+		{
+			name:    "truncated record",
+			input:   "\x1eabc\x00Jane",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewCommitScanner(strings.NewReader(tt.input))
+
+			var got []CommitInfo
+			for scanner.Scan() {
+				got = append(got, scanner.Commit())
+			}
+
+			if (scanner.Err() != nil) != tt.wantErr {
+				t.Fatalf("Err() = %v, wantErr %v", scanner.Err(), tt.wantErr)
+			}
+
+			if tt.wantErr && !errors.Is(scanner.Err(), ErrParseError) {
+				t.Errorf("Err() = %v, want ErrParseError", scanner.Err())
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commits = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
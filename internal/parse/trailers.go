@@ -0,0 +1,113 @@
+package parse
+
+import (
+	"strings"
+)
+
+// Well-known trailer keys
+const (
+	TrailerCoAuthoredBy = "Co-authored-by"
+	TrailerSignedOffBy  = "Signed-off-by"
+	TrailerReviewedBy   = "Reviewed-by"
+	TrailerReportedBy   = "Reported-by"
+)
+
+// Trailer is a "Key: value" line of the trailer block ending a commit
+// message. Values of the form "Name <email>" are split into Name and
+// Email, which are empty otherwise.
+type Trailer struct {
+	Key   string
+	Value string
+	Name  string
+	Email string
+}
+
+// Is reports whether the trailer has the given key, ignoring case like git
+func (t Trailer) Is(key string) bool {
+	return strings.EqualFold(t.Key, key)
+}
+
+// ParseTrailers returns the trailers of a commit message body. Like git
+// interpret-trailers, the trailer block is the last paragraph, in which
+// every line must be a trailer or a continuation of one, unless a quarter
+// of its lines are trailers and one of them is a Signed-off-by or a
+// cherry-pick note.
+func ParseTrailers(body string) []Trailer {
+	body = strings.TrimRight(body, "\n")
+	if i := strings.LastIndex(body, "\n\n"); i >= 0 {
+		body = body[i+2:]
+	}
+
+	lines := strings.Split(body, "\n")
+
+	var trailers []Trailer
+	other, known := 0, false
+
+	for _, line := range lines {
+		// Continuation of a folded value
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(trailers) > 0 {
+			last := &trailers[len(trailers)-1]
+			last.Value += " " + strings.TrimSpace(line)
+			continue
+		}
+
+		if strings.HasPrefix(line, "(cherry picked from commit ") {
+			known = true
+			continue
+		}
+
+		trailer, ok := parseTrailer(line)
+		if !ok {
+			other++
+			continue
+		}
+
+		if trailer.Is(TrailerSignedOffBy) {
+			known = true
+		}
+		trailers = append(trailers, trailer)
+	}
+
+	if other > 0 && (!known || len(trailers)*3 < other) {
+		return nil
+	}
+
+	for i := range trailers {
+		trailers[i].Name, trailers[i].Email = ParseIdentity(trailers[i].Value)
+	}
+
+	return trailers
+}
+
+// parseTrailer parses a single "Key: value" line. Keys consist of letters,
+// digits and dashes.
+func parseTrailer(line string) (Trailer, bool) {
+	key, value, ok := strings.Cut(line, ":")
+	if !ok || key == "" {
+		return Trailer{}, false
+	}
+
+	for _, c := range key {
+		if !(c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return Trailer{}, false
+		}
+	}
+
+	return Trailer{Key: key, Value: strings.TrimSpace(value)}, true
+}
+
+// ParseIdentity splits "Name <email>", returning empty strings for values
+// of any other form
+func ParseIdentity(value string) (string, string) {
+	open := strings.LastIndexByte(value, '<')
+	if open < 0 || !strings.HasSuffix(value, ">") {
+		return "", ""
+	}
+
+	email := strings.TrimSpace(value[open+1 : len(value)-1])
+	if email == "" {
+		return "", ""
+	}
+
+	return strings.TrimSpace(value[:open]), email
+}
@@ -0,0 +1,68 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTrailers(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []Trailer
+	}{
+		{
+			name: "no body",
+			body: "",
+			want: nil,
+		},
+		{
+			name: "trailers only",
+			body: "Co-authored-by: Bob Smith <bob@example.com>\nReviewed-by: Carol <carol@example.com>",
+			want: []Trailer{
+				{Key: "Co-authored-by", Value: "Bob Smith <bob@example.com>", Name: "Bob Smith", Email: "bob@example.com"},
+				{Key: "Reviewed-by", Value: "Carol <carol@example.com>", Name: "Carol", Email: "carol@example.com"},
+			},
+		},
+		{
+			name: "after a paragraph",
+			body: "Explain the change.\n\nFixes: #12\nco-authored-by: Dan <dan@example.com>\n",
+			want: []Trailer{
+				{Key: "Fixes", Value: "#12"},
+				{Key: "co-authored-by", Value: "Dan <dan@example.com>", Name: "Dan", Email: "dan@example.com"},
+			},
+		},
+		{
+			name: "folded value",
+			body: "Reported-by: Erin\n  <erin@example.com>",
+			want: []Trailer{
+				{Key: "Reported-by", Value: "Erin <erin@example.com>", Name: "Erin", Email: "erin@example.com"},
+			},
+		},
+		{
+			name: "prose in the last paragraph",
+			body: "Note: this is prose\nthat merely starts like a trailer.",
+			want: nil,
+		},
+		{
+			name: "sign-off among other lines",
+			body: "Some text here\nSigned-off-by: Frank <frank@example.com>",
+			want: []Trailer{
+				{Key: "Signed-off-by", Value: "Frank <frank@example.com>", Name: "Frank", Email: "frank@example.com"},
+			},
+		},
+		{
+			name: "trailers not in the last paragraph",
+			body: "Reviewed-by: Carol <carol@example.com>\n\nMore explanation.",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseTrailers(tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTrailers() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,10 +3,8 @@ package stats
 import (
 	"fmt"
 	"os/exec"
-	"strings"
 
 	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
 )
 
 // FindGit looks for the 'git' binary on the system's PATH.
@@ -56,34 +54,3 @@ func OpenRepo(path string) (*git.Repository, error) {
 
 	return repo, nil
 }
-
-// CloneWithLocalGit A new function to demonstrate how you might use a local git binary.
-// This is not part of the original code but shows a more robust approach.
-func CloneWithLocalGit(url string, dir string) error {
-	gitPath, err := FindGit()
-	if err != nil {
-		// Local git binary not found, try cloning with go-git
-		// The clone options are kept minimal to avoid go-git trying to use an
-		// external process, which was causing the test to fail.
-		cloneOpts := &git.CloneOptions{
-			URL:      url,
-			Progress: nil,
-		}
-
-		// Only add HTTP auth if it's an HTTP/S URL.
-		if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
-			cloneOpts.Auth = &http.BasicAuth{}
-		}
-
-		if _, err := git.PlainClone(dir, false, cloneOpts); err != nil {
-			return fmt.Errorf("failed to clone with go-git: %w", err)
-		}
-	} else {
-		// Local git binary found, use it to clone
-		if err := exec.Command(gitPath, "clone", url, dir).Run(); err != nil {
-			return fmt.Errorf("failed to clone with local git: %w", err)
-		}
-	}
-
-	return nil
-}
@@ -7,7 +7,6 @@ import (
 	"testing"
 
 	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 // Helper function to create a dummy git repository for testing
@@ -100,30 +99,3 @@ func TestOpenRepo(t *testing.T) {
 		}
 	})
 }
-
-func TestCloneWithLocalGit(t *testing.T) {
-	// This test uses a dummy remote repository created locally.
-	// You might want to use a known public repository for more reliable testing.
-	// Example: "[https://github.com/go-git/go-git.git](https://github.com/go-git/go-git.git)"
-
-	// Create a dummy "remote" repository
-	remotePath := createTestRepo(t)
-	remoteRepo, _ := git.PlainOpen(remotePath)
-	wt, _ := remoteRepo.Worktree()
-	file, _ := os.Create(filepath.Join(remotePath, "README.md"))
-	_ = file.Close()
-	_, _ = wt.Add("README.md")
-	_, _ = wt.Commit("initial commit", &git.CommitOptions{Author: &object.Signature{}})
-
-	// Case 1: Local git binary found, should clone using exec.Command
-	t.Run("CloneWithLocalGit", func(t *testing.T) {
-		cloneDir := filepath.Join(t.TempDir(), "local-clone")
-		if err := CloneWithLocalGit(remotePath, cloneDir); err != nil {
-			t.Errorf("CloneWithLocalGit() failed with error: %v", err)
-		}
-
-		if _, err := os.Stat(filepath.Join(cloneDir, "README.md")); os.IsNotExist(err) {
-			t.Error("file from cloned repo not found")
-		}
-	})
-}
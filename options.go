@@ -1,13 +1,84 @@
 package git_nerds
 
-import "time"
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/git"
+)
+
+// Backend names accepted by Options.Backend
+const (
+	// BackendAuto uses the git CLI when a git binary is on the PATH and
+	// falls back to go-git otherwise
+	BackendAuto = "auto"
+
+	// BackendExec always uses the git CLI
+	BackendExec = "exec"
+
+	// BackendGoGit uses the native go-git implementation, no git binary
+	// needed. Its line diff is not git's, so added and deleted line counts
+	// of heavily edited files can differ from the git CLI by a few lines.
+	BackendGoGit = "go-git"
+)
+
+// Line splits accepted by Options.CoAuthorSplit
+const (
+	// CoAuthorSplitEven divides the lines of a commit evenly between its
+	// author and co-authors
+	CoAuthorSplitEven = git.SplitEven
+
+	// CoAuthorSplitFull credits everyone with every line
+	CoAuthorSplitFull = git.SplitFull
+
+	// CoAuthorSplitAuthor leaves the lines to the author, co-authors only
+	// get the commit
+	CoAuthorSplitAuthor = git.SplitAuthor
+)
+
+// Commit dates accepted by Options.DateSource
+const (
+	// DateSourceAuthor uses the date a change was originally written
+	DateSourceAuthor = git.DateAuthor
+
+	// DateSourceCommitter uses the date a change was last committed, which
+	// rebases, cherry-picks and amends move to when the work landed
+	DateSourceCommitter = git.DateCommitter
+)
+
+// Time zones accepted by Options.Timezone besides IANA names such as
+// "Europe/Berlin"
+const (
+	// TimezoneAuthorLocal buckets every commit in the time zone it was made
+	// in, as recorded by git
+	TimezoneAuthorLocal = "author-local"
+
+	// TimezoneUTC buckets every commit in UTC
+	TimezoneUTC = "UTC"
+)
 
 // Options configures repository analysis behavior
 type Options struct {
-	// Time range filters
+	// Time range filters, inclusive and precise to the second: an Until
+	// at midnight stops at that instant, not at the end of its day
 	Since time.Time
 	Until time.Time
 
+	// Commit date used by the time range, every temporal breakdown,
+	// calendars, heatmaps, changelogs, branch ages and submodule updates:
+	// DateSourceAuthor (default) or DateSourceCommitter. git can only bound
+	// the walk by committer date, so a time range on author dates reads the
+	// history committed since Since minus MaxDateSkew, and misses commits
+	// whose author date runs further ahead (0 = one day).
+	DateSource  string
+	MaxDateSkew time.Duration
+
+	// Time zone of the hour, weekday, day, month and year breakdowns,
+	// calendars, heatmaps and punchcards: TimezoneAuthorLocal (default),
+	// TimezoneUTC, "Local" or an IANA name such as "America/New_York"
+	Timezone string
+
 	// Branch filtering
 	Branch string
 
@@ -15,23 +86,76 @@ type Options struct {
 	// Use gitignore-style patterns, e.g., ":!vendor", ":!*.generated.go"
 	PathSpec []string
 
-	// Author filtering (regex patterns)
+	// Author filtering (regex patterns matched against name and email)
 	// Example: []string{"bot@.*", ".*\\[bot\\]"}
 	IgnoreAuthors []string
 
+	// Also ignore well-known bots: dependabot, renovate, github-actions
+	// and any other "[bot]" account
+	IgnoreBots bool
+
+	// Identity unification on top of .mailmap, which is always honoured.
+	// Keys are emails or names (case-insensitive), values canonical emails.
+	// Example: map[string]string{"jane@personal.dev": "jane@corp.com"}
+	IdentityAliases map[string]string
+
+	// Merge GitHub noreply addresses (123+login@users.noreply.github.com)
+	// into the regular address used under the same name or login
+	MergeNoreplyEmails bool
+
+	// Credit the people named by Co-authored-by trailers along with the
+	// author in author and temporal statistics. CoAuthorSplit decides how
+	// the lines of a commit are shared: CoAuthorSplitEven (default),
+	// CoAuthorSplitFull or CoAuthorSplitAuthor.
+	AttributeCoAuthors bool
+	CoAuthorSplit      string
+
 	// Merge commit handling
 	IncludeMerges bool // if false, excludes merge commits
 	OnlyMerges    bool // if true, shows only merge commits
 
 	// Result limiting
-	Limit int // limit number of results (0 = no limit)
+	Limit int // limit number of commits analyzed, counted after IgnoreAuthors and IgnoreBots (0 = no limit)
 
 	// Sorting options
 	SortBy    string // "name", "commits", "lines", etc.
 	SortOrder string // "asc" or "desc"
 
+	// Open every initialized submodule and merge its author, temporal and
+	// file statistics into DetailedStats. Bare repositories with submodules
+	// fail with ErrBareRepository.
+	RecurseSubmodules bool
+
+	// Rename detection of per-file statistics: the similarity percentage a
+	// rename needs (0 = git's default of 50%), and whether copies are
+	// detected too (git CLI only). Files and FileHistory follow renamed
+	// files across their old paths.
+	RenameThreshold int
+	DetectCopies    bool
+
 	// Additional git log options
 	LogOptions []string
+
+	// Git implementation: BackendAuto (default), BackendExec or BackendGoGit
+	Backend string
+
+	// Keep parsed commits in a persistent cache so later runs only read
+	// new history from git. Stored in nerds-cache inside the git directory,
+	// or below CommitCacheDir when set. Ignored when PathSpec or
+	// LogOptions are used.
+	CommitCache    bool
+	CommitCacheDir string
+
+	// Clone cache of OpenURL, defaults to git-nerds in the user cache
+	// directory. Clones unused for longer than CacheMaxAge are removed
+	// (0 = keep forever).
+	CacheDir    string
+	CacheMaxAge time.Duration
+
+	// Partial clone filter of OpenURL, such as "blob:none" to download file
+	// contents only when line statistics or blame need them. Needs the git
+	// binary; empty clones everything.
+	CloneFilter string
 }
 
 // DefaultOptions returns sensible default options
@@ -48,11 +172,72 @@ func DefaultOptions() *Options {
 		SortBy:        "commits",
 		SortOrder:     "desc",
 		LogOptions:    []string{},
+		Backend:       BackendAuto,
 	}
 }
 
 // Validate checks if options are valid
 func (o *Options) Validate() error {
-	// Add validation logic here if needed
+	switch o.Backend {
+	case "", BackendAuto, BackendExec, BackendGoGit:
+	default:
+		return fmt.Errorf("unknown backend %q", o.Backend)
+	}
+
+	for alias, canonical := range o.IdentityAliases {
+		if alias == "" || canonical == "" {
+			return fmt.Errorf("identity alias %q -> %q must not be empty", alias, canonical)
+		}
+	}
+
+	switch o.CoAuthorSplit {
+	case "", CoAuthorSplitEven, CoAuthorSplitFull, CoAuthorSplitAuthor:
+	default:
+		return fmt.Errorf("unknown co-author split %q", o.CoAuthorSplit)
+	}
+
+	switch o.DateSource {
+	case "", DateSourceAuthor, DateSourceCommitter:
+	default:
+		return fmt.Errorf("unknown date source %q", o.DateSource)
+	}
+
+	if _, err := o.location(); err != nil {
+		return err
+	}
+
+	if o.RenameThreshold < 0 || o.RenameThreshold > 100 {
+		return fmt.Errorf("rename threshold %d must be between 0 and 100", o.RenameThreshold)
+	}
+
+	if o.MaxDateSkew < 0 {
+		return fmt.Errorf("max date skew %v must not be negative", o.MaxDateSkew)
+	}
+
+	if o.CacheMaxAge < 0 {
+		return fmt.Errorf("cache max age %v must not be negative", o.CacheMaxAge)
+	}
+
+	for _, pattern := range o.IgnoreAuthors {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid ignore author pattern %q: %w", pattern, err)
+		}
+	}
+
 	return nil
 }
+
+// location returns the time zone named by Timezone, nil for author-local
+func (o *Options) location() (*time.Location, error) {
+	switch o.Timezone {
+	case "", TimezoneAuthorLocal:
+		return nil, nil
+	}
+
+	loc, err := time.LoadLocation(o.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", o.Timezone, err)
+	}
+
+	return loc, nil
+}
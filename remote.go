@@ -0,0 +1,205 @@
+package git_nerds
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	git2 "github.com/inovacc/git-nerds/internal/git"
+)
+
+// OpenURL opens a remote repository. A bare clone is kept in
+// Options.CacheDir and fetched again whenever the URL is reopened, so only
+// new history is downloaded. Any URL git understands works, including
+// file:// URLs and local paths. When Options.CacheMaxAge is set, clones
+// not used for longer are removed from the cache.
+func OpenURL(url string, opts ...*Options) (*Repository, error) {
+	return OpenURLContext(context.Background(), url, opts...)
+}
+
+// OpenURLContext is OpenURL with the clone or fetch bound to ctx. An
+// interrupted clone is discarded. The returned repository isn't bound to
+// ctx, see Repository.WithContext.
+func OpenURLContext(ctx context.Context, url string, opts ...*Options) (*Repository, error) {
+	var options *Options
+	if len(opts) > 0 && opts[0] != nil {
+		options = opts[0]
+	} else {
+		options = DefaultOptions()
+	}
+
+	if err := options.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOptions, err)
+	}
+
+	cacheDir, err := options.cacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	// Local remotes are cached by absolute path
+	if info, err := os.Stat(url); err == nil && info.IsDir() {
+		if url, err = filepath.Abs(url); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+		}
+	}
+
+	native := useGoGit(options.Backend)
+	if options.CloneFilter != "" && native {
+		return nil, fmt.Errorf("%w: clone filter %s needs the git binary", ErrInvalidOptions, options.CloneFilter)
+	}
+
+	// Partial clones are kept apart from full ones, whose objects they lack
+	key := url
+	if options.CloneFilter != "" {
+		key += "\x00" + options.CloneFilter
+	}
+	dir := filepath.Join(cacheDir, cacheName(key))
+
+	if _, err := os.Stat(dir); err == nil {
+		if err := git2.FetchBare(ctx, dir, native); err != nil {
+			return nil, remoteError(ctx, err)
+		}
+	} else {
+		// Clone next to the final location and move it in place once
+		// complete, so an interrupted clone is never picked up
+		tmp, err := os.MkdirTemp(cacheDir, ".clone-")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+		}
+		defer os.RemoveAll(tmp)
+
+		if err := git2.CloneBare(ctx, url, tmp, options.CloneFilter, native); err != nil {
+			return nil, remoteError(ctx, err)
+		}
+
+		if err := os.Rename(tmp, dir); err != nil && !os.IsExist(err) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+		}
+	}
+
+	// The modification time of a clone records when it was last used
+	now := time.Now()
+	if err := os.Chtimes(dir, now, now); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	if options.CacheMaxAge > 0 {
+		if err := PruneCache(cacheDir, options.CacheMaxAge); err != nil {
+			return nil, err
+		}
+	}
+
+	return Open(dir, options)
+}
+
+// remoteError wraps the error of a clone or fetch in ErrGitCommandFailed,
+// or in the error of ctx when it was cancelled
+func remoteError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%w: %v", ctxErr, err)
+	}
+	return fmt.Errorf("%w: %v", ErrGitCommandFailed, err)
+}
+
+// PruneCache removes the clones of OpenURL in dir that weren't used for
+// longer than maxAge, along with clones left behind by interrupted runs
+// that are older than that. Other entries of dir are left alone.
+func PruneCache(dir string, maxAge time.Duration) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+
+	for _, entry := range entries {
+		// Only touch what OpenURL created
+		if !entry.IsDir() || !isCacheName(entry.Name()) && !strings.HasPrefix(entry.Name(), ".clone-") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to prune %s: %w", entry.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// cacheDir returns the directory holding the clones of OpenURL
+func (o *Options) cacheDir() (string, error) {
+	if o.CacheDir != "" {
+		return o.CacheDir, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: no cache directory: %v", ErrInvalidOptions, err)
+	}
+
+	return filepath.Join(dir, "git-nerds"), nil
+}
+
+// cacheName names the clone of url after the repository, with a hash of
+// the full URL to tell apart repositories sharing a name. Anything after a
+// NUL in url only changes the hash.
+func cacheName(url string) string {
+	sum := sha256.Sum256([]byte(url))
+
+	url, _, _ = strings.Cut(url, "\x00")
+	name := strings.TrimSuffix(path.Base(filepath.ToSlash(strings.TrimRight(url, "/"))), ".git")
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '-'
+		}
+	}, name)
+
+	return name + "-" + hex.EncodeToString(sum[:8]) + ".git"
+}
+
+// isCacheName reports whether name has the form given by cacheName
+func isCacheName(name string) bool {
+	name, ok := strings.CutSuffix(name, ".git")
+	if !ok || len(name) < 17 || name[len(name)-17] != '-' {
+		return false
+	}
+
+	_, err := hex.DecodeString(name[len(name)-16:])
+	return err == nil
+}
+
+// useGoGit reports whether backend kind resolves to go-git
+func useGoGit(kind string) bool {
+	switch kind {
+	case BackendGoGit:
+		return true
+	case BackendExec:
+		return false
+	default:
+		_, err := exec.LookPath("git")
+		return err != nil
+	}
+}
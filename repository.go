@@ -1,23 +1,35 @@
 package git_nerds
 
 import (
+	"context"
 	"fmt"
+	"iter"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
 	"time"
 
 	analysis2 "github.com/inovacc/git-nerds/internal/analysis"
 	git2 "github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/parse"
 )
 
 // Repository provides access to Git repository statistics and analysis
 type Repository struct {
-	path    string
-	options *Options
-	backend git2.Backend
+	path     string
+	gitDir   string
+	bare     bool
+	worktree bool
+	options  *Options
+	backend  git2.Backend
+	ctx      context.Context // set by WithContext, passed on to submodules
+	cacheDir string          // commit cache, empty when disabled
 }
 
-// Open opens a Git repository at the specified path
+// Open opens the Git repository containing path. The path may be a working
+// tree or any directory inside it, a linked worktree, a bare repository or
+// a git directory.
 func Open(path string, opts ...*Options) (*Repository, error) {
 	// Resolve absolute path
 	absPath, err := filepath.Abs(path)
@@ -30,16 +42,6 @@ func Open(path string, opts ...*Options) (*Repository, error) {
 		return nil, fmt.Errorf("%w: %s", ErrInvalidPath, absPath)
 	}
 
-	// Check if it's a git repository
-	gitDir := filepath.Join(absPath, ".git")
-	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
-		// Maybe it's the .git directory itself
-		if filepath.Base(absPath) != ".git" {
-			return nil, fmt.Errorf("%w: %s", ErrNotARepository, absPath)
-		}
-		absPath = filepath.Dir(absPath)
-	}
-
 	// Use provided options or defaults
 	var options *Options
 	if len(opts) > 0 && opts[0] != nil {
@@ -54,23 +56,118 @@ func Open(path string, opts ...*Options) (*Repository, error) {
 	}
 
 	// Create backend
-	backend, err := git2.NewExecBackend(absPath)
+	backend, err := newBackend(absPath, options.Backend)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Repository{
-		path:    absPath,
-		options: options,
-		backend: backend,
-	}, nil
+	// Find the repository around the path
+	layout, err := git2.Locate(backend)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrNotARepository, absPath, err)
+	}
+
+	// Run git from the repository root so tree paths resolve
+	if root := layout.Root(); root != absPath {
+		if backend, err = newBackend(root, options.Backend); err != nil {
+			return nil, err
+		}
+	}
+
+	repo := &Repository{
+		path:     layout.Root(),
+		gitDir:   layout.GitDir,
+		bare:     layout.Bare,
+		worktree: layout.Linked(),
+		options:  options,
+		backend:  backend,
+	}
+
+	// Worktrees share the cache of their repository
+	if options.CommitCache {
+		repo.cacheDir = filepath.Join(layout.CommonDir, "nerds-cache")
+		if options.CommitCacheDir != "" {
+			repo.cacheDir = filepath.Join(options.CommitCacheDir, cacheName(layout.CommonDir))
+		}
+	}
+
+	return repo, nil
+}
+
+// newBackend creates the git backend selected by kind
+func newBackend(path, kind string) (git2.Backend, error) {
+	switch kind {
+	case BackendExec:
+		backend, err := git2.NewExecBackend(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrGitNotFound, err)
+		}
+		return backend, nil
+
+	case BackendGoGit:
+		backend, err := git2.NewGoGitBackend(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotARepository, err)
+		}
+		return backend, nil
+
+	default:
+		// Prefer the git CLI and fall back to go-git only when it isn't
+		// installed: a broken git binary is reported, not papered over
+		if _, err := exec.LookPath("git"); err != nil {
+			return newBackend(path, BackendGoGit)
+		}
+		return newBackend(path, BackendExec)
+	}
 }
 
-// Path returns the repository path
+// Path returns the repository path: the top level of the working tree, or
+// the git directory of a bare repository
 func (r *Repository) Path() string {
 	return r.path
 }
 
+// GitDir returns the git directory. For a linked worktree this is its own
+// directory below the main repository's .git/worktrees.
+func (r *Repository) GitDir() string {
+	return r.gitDir
+}
+
+// IsBare reports whether the repository has no working tree
+func (r *Repository) IsBare() bool {
+	return r.bare
+}
+
+// IsWorktree reports whether the repository was opened through a linked
+// worktree
+func (r *Repository) IsWorktree() bool {
+	return r.worktree
+}
+
+// WorkTree returns the top level of the working tree, or ErrBareRepository
+func (r *Repository) WorkTree() (string, error) {
+	if r.bare {
+		return "", fmt.Errorf("%w: %s", ErrBareRepository, r.path)
+	}
+	return r.path, nil
+}
+
+// WithContext returns a shallow copy of the repository whose git commands
+// are bound to ctx. When ctx is cancelled or its deadline passes, running
+// commands are stopped and methods return an error wrapping ctx.Err(), so
+// errors.Is(err, context.Canceled) or context.DeadlineExceeded can be used
+// to tell cancellation apart from git failures.
+func (r *Repository) WithContext(ctx context.Context) *Repository {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	clone := *r
+	clone.ctx = ctx
+	clone.backend = r.backend.WithContext(ctx)
+	return &clone
+}
+
 // Options returns the repository options
 func (r *Repository) Options() *Options {
 	return r.options
@@ -78,49 +175,121 @@ func (r *Repository) Options() *Options {
 
 // toLogOptions converts Options to git.LogOptions
 func (r *Repository) toLogOptions() *git2.LogOptions {
+	split := r.options.CoAuthorSplit
+	if split == "" {
+		split = CoAuthorSplitEven
+	}
+
+	// Checked by Validate when the repository was opened
+	location, _ := r.options.location()
+
 	return &git2.LogOptions{
-		Since:         r.options.Since,
-		Until:         r.options.Until,
-		Author:        "",
-		Format:        "",
-		Branch:        r.options.Branch,
-		PathSpec:      r.options.PathSpec,
-		NoMerges:      !r.options.IncludeMerges && !r.options.OnlyMerges,
-		MergesOnly:    r.options.OnlyMerges,
-		Limit:         r.options.Limit,
+		Since:      r.options.Since,
+		Until:      r.options.Until,
+		Author:     "",
+		Format:     "",
+		Branch:     r.options.Branch,
+		PathSpec:   r.options.PathSpec,
+		NoMerges:   !r.options.IncludeMerges && !r.options.OnlyMerges,
+		MergesOnly: r.options.OnlyMerges,
+		Limit:      r.options.Limit,
+		ExtraArgs:  r.options.LogOptions,
+
+		IdentityAliases: r.options.IdentityAliases,
+		MergeNoreply:    r.options.MergeNoreplyEmails,
+
 		IgnoreAuthors: r.options.IgnoreAuthors,
-		ExtraArgs:     r.options.LogOptions,
+		IgnoreBots:    r.options.IgnoreBots,
+
+		RenameThreshold: r.options.RenameThreshold,
+		FindCopies:      r.options.DetectCopies,
+
+		CoAuthors:     r.options.AttributeCoAuthors,
+		CoAuthorSplit: split,
+
+		DateSource:  r.options.DateSource,
+		MaxDateSkew: r.options.MaxDateSkew,
+		Location:    location,
+
+		CacheDir: r.cacheDir,
 	}
 }
 
-// DetailedStats returns comprehensive repository statistics
+// DetailedStats returns comprehensive repository statistics. With
+// Options.RecurseSubmodules the author, temporal and file statistics of
+// every initialized submodule are merged in.
 func (r *Repository) DetailedStats() (*Stats, error) {
 	logOpts := r.toLogOptions()
 
-	// Create analyzers
-	authorAnalyzer := analysis2.NewAuthorAnalyzer(r.backend, logOpts)
-	branchAnalyzer := analysis2.NewBranchAnalyzer(r.backend, logOpts)
-
-	// Get author details
-	authors, err := authorAnalyzer.DetailedAuthorStats()
+	branches, err := r.branches(logOpts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get author stats: %w", err)
+		return nil, err
 	}
 
-	// Get branch details
-	branches, err := branchAnalyzer.DetailedBranchInfo()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get branch info: %w", err)
+	stats, _, err := r.collectStats(logOpts, branches)
+	return stats, err
+}
+
+// activity records the days with commits, overall and per author email, so
+// the statistics of several repositories can be merged
+type activity struct {
+	days    map[string]struct{}
+	authors map[string]map[string]struct{}
+}
+
+// add merges the days of other into a
+func (a *activity) add(other *activity) {
+	for day := range other.days {
+		a.days[day] = struct{}{}
+	}
+
+	for email, days := range other.authors {
+		if a.authors[email] == nil {
+			a.authors[email] = make(map[string]struct{}, len(days))
+		}
+		for day := range days {
+			a.authors[email][day] = struct{}{}
+		}
 	}
+}
+
+// collectStats builds the statistics of the commits selected by logOpts,
+// listing branches, and returns them along with the days they were made,
+// used to merge several repositories. Submodules are left out of branch
+// comparisons.
+func (r *Repository) collectStats(logOpts *git2.LogOptions, branches []Branch) (*Stats, *activity, error) {
+	// Collect author, temporal, file and merge data in a single walk
+	authors := analysis2.NewAuthorAggregator().In(logOpts.Location)
+	temporal := analysis2.NewTemporalAggregator().In(logOpts.Location)
+	files := analysis2.NewFileAggregator()
+	merges := analysis2.NewMergeAggregator().In(logOpts.Location)
+
+	if logOpts.CoAuthors {
+		authors.CreditCoAuthors(logOpts.CoAuthorSplit)
+	}
+
+	engine := analysis2.NewEngine(r.backend, logOpts)
+	if err := engine.Run(authors, temporal, files, merges); err != nil {
+		return nil, nil, fmt.Errorf("failed to collect stats: %w", err)
+	}
+
+	authorDetails := authors.Results()
+	fileDetails := files.Results()
 
-	// Calculate totals
 	stats := &Stats{
-		TotalAuthors: len(authors),
-		Authors:      make([]Author, len(authors)),
-		Branches:     make([]Branch, len(branches)),
+		TotalCommits:  temporal.Commits,
+		TotalMerges:   merges.Results().TotalMerges,
+		TotalAuthors:  len(authorDetails),
+		TotalFiles:    len(fileDetails),
+		FirstCommitAt: temporal.FirstCommit,
+		LastCommitAt:  temporal.LastCommit,
+		ActiveDays:    temporal.ActiveDays(),
+		Authors:       make([]Author, len(authorDetails)),
+		Files:         toFiles(fileDetails),
+		Branches:      slices.Clone(branches),
 	}
 
-	for i, a := range authors {
+	for i, a := range authorDetails {
 		stats.Authors[i] = Author{
 			Name:         a.Name,
 			Email:        a.Email,
@@ -133,20 +302,48 @@ func (r *Repository) DetailedStats() (*Stats, error) {
 			LastCommit:   a.LastCommit,
 			ActiveDays:   a.ActiveDays,
 		}
-		stats.TotalCommits += a.Commits
-		stats.LinesAdded += a.LinesAdded
-		stats.LinesDeleted += a.LinesDeleted
+	}
+
+	// Counted per file, as co-authors may be credited with the same lines
+	for _, f := range fileDetails {
+		stats.LinesAdded += f.Additions
+		stats.LinesDeleted += f.Deletions
 	}
 
 	stats.LinesChanged = stats.LinesAdded + stats.LinesDeleted
 
-	if len(authors) > 0 {
-		stats.FirstCommitAt = authors[len(authors)-1].FirstCommit
-		stats.LastCommitAt = authors[0].LastCommit
+	active := &activity{
+		days:    make(map[string]struct{}, len(temporal.ByDay)),
+		authors: authors.Days(),
 	}
+	for day := range temporal.ByDay {
+		active.days[day] = struct{}{}
+	}
+
+	if r.options.RecurseSubmodules && logOpts.Exclude == "" {
+		if err := r.mergeSubmoduleStats(stats, active); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	countAssets(stats)
+
+	return stats, active, nil
+}
+
+// branches lists the branches of the repository for Stats. They don't
+// depend on the commits selected, so one listing serves every selection.
+func (r *Repository) branches(logOpts *git2.LogOptions) ([]Branch, error) {
+	analyzer := analysis2.NewBranchAnalyzer(r.backend, logOpts)
 
+	branches, err := analyzer.DetailedBranchInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch info: %w", err)
+	}
+
+	result := make([]Branch, len(branches))
 	for i, b := range branches {
-		stats.Branches[i] = Branch{
+		result[i] = Branch{
 			Name:      b.Name,
 			Hash:      b.Hash,
 			UpdatedAt: b.LastCommit,
@@ -155,13 +352,373 @@ func (r *Repository) DetailedStats() (*Stats, error) {
 		}
 	}
 
-	return stats, nil
+	return result, nil
+}
+
+// Commits streams the commits selected by the repository options, newest
+// first. Commits are parsed one at a time from a single git log run, so
+// memory use does not grow with history size. Breaking out of the loop or
+// cancelling ctx stops git.
+func (r *Repository) Commits(ctx context.Context) iter.Seq2[Commit, error] {
+	return func(yield func(Commit, error) bool) {
+		for c, err := range analysis2.Commits(r.backend.WithContext(ctx), r.toLogOptions()) {
+			if err != nil {
+				yield(Commit{}, err)
+				return
+			}
+
+			if !yield(toCommit(c), nil) {
+				return
+			}
+		}
+	}
+}
+
+// DateDrift returns the selected commits whose author and committer dates
+// are more than threshold apart, newest first. Rebases, cherry-picks and
+// amended commits keep their author date, so these commits landed long
+// after they were written, or the other way round with skewed clocks.
+func (r *Repository) DateDrift(threshold time.Duration) ([]Commit, error) {
+	analyzer := analysis2.NewTemporalAnalyzer(r.backend, r.toLogOptions())
+
+	commits, err := analyzer.DateDrift(threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Commit, len(commits))
+	for i, c := range commits {
+		result[i] = toCommit(c)
+	}
+
+	return result, nil
+}
+
+// toCommit converts a parsed commit to the public type
+func toCommit(c parse.CommitInfo) Commit {
+	return Commit{
+		Hash:       c.Hash,
+		Author:     c.Author,
+		Email:      c.Email,
+		Date:       c.Date,
+		AuthorDate: c.AuthorDate,
+		CommitDate: c.CommitDate,
+		Message:    c.Subject,
+		Body:       c.Body,
+		Trailers:   toTrailers(c.Trailers),
+		Files:      c.Files,
+		Additions:  c.Additions,
+		Deletions:  c.Deletions,
+	}
+}
+
+// toTrailers converts parsed trailers to the public type
+func toTrailers(trailers []parse.Trailer) []Trailer {
+	if len(trailers) == 0 {
+		return nil
+	}
+
+	result := make([]Trailer, len(trailers))
+	for i, t := range trailers {
+		result[i] = Trailer{Key: t.Key, Value: t.Value, Name: t.Name, Email: t.Email}
+	}
+
+	return result
+}
+
+// Files returns change statistics for every file touched by the selected
+// commits, most frequently changed first. Authors are listed by email,
+// most recent first.
+func (r *Repository) Files() ([]File, error) {
+	analyzer := analysis2.NewFileAnalyzer(r.backend, r.toLogOptions())
+
+	files, err := analyzer.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	return toFiles(files), nil
 }
 
-// StatsByBranch returns statistics for a specific branch
+// TopFiles returns the N most frequently changed files
+func (r *Repository) TopFiles(n int) ([]File, error) {
+	analyzer := analysis2.NewFileAnalyzer(r.backend, r.toLogOptions())
+
+	files, err := analyzer.TopFiles(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return toFiles(files), nil
+}
+
+// FileHistory returns the commits that changed a file, newest first,
+// following it across renames like git log --follow. PathSpec is ignored
+// and Limit caps the number of revisions. Renames are followed even when
+// made by ignored authors or after Until.
+func (r *Repository) FileHistory(path string) ([]FileRevision, error) {
+	analyzer := analysis2.NewFileAnalyzer(r.backend, r.toLogOptions())
+
+	history, err := analyzer.History(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]FileRevision, len(history))
+	for i, rev := range history {
+		result[i] = FileRevision{
+			Hash:      rev.Hash,
+			Author:    rev.Author,
+			Email:     rev.Email,
+			Date:      rev.Date,
+			Message:   rev.Subject,
+			Path:      rev.Path,
+			OldPath:   rev.OldPath,
+			Additions: rev.Additions,
+			Deletions: rev.Deletions,
+			Binary:    rev.Binary,
+		}
+	}
+
+	return result, nil
+}
+
+// toFiles converts analyzer file details to public files
+func toFiles(files []analysis2.FileDetails) []File {
+	result := make([]File, len(files))
+	for i, f := range files {
+		result[i] = File{
+			Path:          f.Path,
+			PreviousPaths: f.PreviousPaths,
+			Changes:       f.Changes,
+			Additions:     f.Additions,
+			Deletions:     f.Deletions,
+			Authors:       f.Authors,
+			LastModified:  f.LastModified,
+			Binary:        f.Binary,
+			LFS:           f.LFS,
+			Size:          f.Size,
+			SizeDelta:     f.SizeDelta,
+			BinaryChanges: f.BinaryChanges,
+			LFSChanges:    f.LFSChanges,
+		}
+	}
+
+	return result
+}
+
+// countAssets totals the binary and LFS activity of the files in stats
+func countAssets(stats *Stats) {
+	stats.BinaryFiles, stats.BinaryChanges, stats.BinaryBytes = 0, 0, 0
+	stats.LFSFiles, stats.LFSChanges, stats.LFSBytes = 0, 0, 0
+
+	for _, f := range stats.Files {
+		stats.BinaryChanges += f.BinaryChanges
+		stats.LFSChanges += f.LFSChanges
+
+		switch {
+		case f.LFS:
+			stats.LFSFiles++
+			stats.LFSBytes += f.SizeDelta
+		case f.Binary:
+			stats.BinaryFiles++
+			stats.BinaryBytes += f.SizeDelta
+		}
+	}
+}
+
+// Ownership attributes the lines surviving in a file, or in every file
+// below a directory, to the authors who last changed them. A path missing
+// from the tree returns ErrInvalidPath.
+func (r *Repository) Ownership(path string) (*Ownership, error) {
+	analyzer := analysis2.NewOwnershipAnalyzer(r.backend, r.toLogOptions())
+
+	details, err := analyzer.Ownership(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := toOwnership(*details)
+	return &result, nil
+}
+
+// OwnershipReport blames every file in the tree and reports ownership per
+// file, per directory and for the whole repository
+func (r *Repository) OwnershipReport() (*OwnershipReport, error) {
+	analyzer := analysis2.NewOwnershipAnalyzer(r.backend, r.toLogOptions())
+
+	report, err := analyzer.Report()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &OwnershipReport{
+		Total:       toOwnership(report.Total),
+		Directories: make([]Ownership, len(report.Directories)),
+		Files:       make([]Ownership, len(report.Files)),
+	}
+
+	for i, d := range report.Directories {
+		result.Directories[i] = toOwnership(d)
+	}
+	for i, f := range report.Files {
+		result.Files[i] = toOwnership(f)
+	}
+
+	return result, nil
+}
+
+// toOwnership converts analyzer ownership details to the public type
+func toOwnership(details analysis2.OwnershipDetails) Ownership {
+	result := Ownership{
+		Path:       details.Path,
+		Lines:      details.Lines,
+		Owners:     make([]Owner, len(details.Owners)),
+		Age:        make([]AgeBucket, len(details.Age)),
+		AverageAge: details.AverageAge,
+	}
+
+	for i, o := range details.Owners {
+		result.Owners[i] = Owner{
+			Name:    o.Name,
+			Email:   o.Email,
+			Lines:   o.Lines,
+			Percent: o.Percent,
+		}
+	}
+
+	for i, a := range details.Age {
+		result.Age[i] = AgeBucket{
+			Label:   a.Label,
+			MaxAge:  a.MaxAge,
+			Lines:   a.Lines,
+			Percent: a.Percent,
+		}
+	}
+
+	return result
+}
+
+// BusFactor computes, for the repository and per directory, how many
+// authors would have to leave before more than half of the files have no
+// one left who knows them. Knowledge comes from recency-weighted changes.
+func (r *Repository) BusFactor() (*BusFactorReport, error) {
+	analyzer := analysis2.NewBusFactorAnalyzer(r.backend, r.toLogOptions())
+
+	report, err := analyzer.BusFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BusFactorReport{
+		Total:       toBusFactor(report.Total),
+		Directories: make([]BusFactor, len(report.Directories)),
+		AtRisk:      report.AtRisk,
+	}
+
+	for i, d := range report.Directories {
+		result.Directories[i] = toBusFactor(d)
+	}
+
+	return result, nil
+}
+
+// toBusFactor converts analyzer bus factor details to the public type
+func toBusFactor(details analysis2.BusFactorDetails) BusFactor {
+	return BusFactor{
+		Path:       details.Path,
+		Factor:     details.Factor,
+		Files:      details.Files,
+		KeyAuthors: details.KeyAuthors,
+	}
+}
+
+// StatsByBranch returns the statistics of the commits reachable from
+// branch, as DetailedStats would with Options.Branch set to it. It returns
+// ErrInvalidBranch when branch names no commit.
 func (r *Repository) StatsByBranch(branch string) (*Stats, error) {
-	// TODO: Implement branch-specific stats
-	return &Stats{}, fmt.Errorf("not implemented yet")
+	if err := r.checkBranch(branch); err != nil {
+		return nil, err
+	}
+
+	logOpts := r.toLogOptions()
+	logOpts.Branch = branch
+
+	branches, err := r.branches(logOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, _, err := r.collectStats(logOpts, branches)
+	return stats, err
+}
+
+// CompareBranches returns the statistics of the commits unique to each of
+// two branches: those of b..a in OnlyA and those of a..b in OnlyB, e.g. to
+// review a release branch against main. The other options still apply to
+// both sides, while Ahead and Behind count every commit like git rev-list.
+func (r *Repository) CompareBranches(a, b string) (*BranchComparison, error) {
+	for _, branch := range []string{a, b} {
+		if err := r.checkBranch(branch); err != nil {
+			return nil, err
+		}
+	}
+
+	logOpts := r.toLogOptions()
+	analyzer := analysis2.NewBranchAnalyzer(r.backend, logOpts)
+
+	counts, err := analyzer.CompareBranches(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BranchComparison{
+		A:      a,
+		B:      b,
+		Ahead:  counts.Ahead,
+		Behind: counts.Behind,
+	}
+
+	// Both sides list the same branches
+	branches, err := r.branches(logOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	sides := []struct {
+		branch, exclude string
+		stats           *Stats
+	}{
+		{a, b, &result.OnlyA},
+		{b, a, &result.OnlyB},
+	}
+
+	for _, side := range sides {
+		opts := *logOpts
+		opts.Branch, opts.Exclude = side.branch, side.exclude
+
+		stats, _, err := r.collectStats(&opts, branches)
+		if err != nil {
+			return nil, err
+		}
+		*side.stats = *stats
+	}
+
+	return result, nil
+}
+
+// checkBranch returns ErrInvalidBranch unless branch names a commit
+func (r *Repository) checkBranch(branch string) error {
+	if branch == "" {
+		return fmt.Errorf("%w: empty name", ErrInvalidBranch)
+	}
+
+	analyzer := analysis2.NewBranchAnalyzer(r.backend, r.toLogOptions())
+	if _, err := analyzer.Resolve(branch); err != nil {
+		return fmt.Errorf("%w %q: %w", ErrInvalidBranch, branch, err)
+	}
+
+	return nil
 }
 
 // Contributors returns all contributors
@@ -217,6 +774,43 @@ func (r *Repository) CommitsPerAuthor() (map[string]int, error) {
 	return analyzer.CommitsPerAuthor()
 }
 
+// Trailers counts the commits carrying trailers of key, such as
+// TrailerReviewedBy or TrailerSignedOffBy, per identity or per value for
+// trailers that don't name anyone. Keys match case-insensitively.
+func (r *Repository) Trailers(key string) ([]TrailerCount, error) {
+	analyzer := analysis2.NewAuthorAnalyzer(r.backend, r.toLogOptions())
+
+	counts, err := analyzer.Trailers(key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TrailerCount, len(counts))
+	for i, c := range counts {
+		result[i] = TrailerCount{Name: c.Name, Email: c.Email, Value: c.Value, Commits: c.Commits}
+	}
+
+	return result, nil
+}
+
+// TopReviewers returns the n people most often named by Reviewed-by
+// trailers
+func (r *Repository) TopReviewers(n int) ([]TrailerCount, error) {
+	reviewers, err := r.Trailers(TrailerReviewedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if n < 0 {
+		n = 0
+	}
+	if n < len(reviewers) {
+		reviewers = reviewers[:n]
+	}
+
+	return reviewers, nil
+}
+
 // SuggestReviewers suggests reviewers for a file based on history
 func (r *Repository) SuggestReviewers(file string) ([]string, error) {
 	logOpts := r.toLogOptions()
@@ -259,28 +853,47 @@ func (r *Repository) CommitsByHour() (map[int]int, error) {
 	return analyzer.CommitsByHour()
 }
 
-// CommitsByTimezone returns commits grouped by timezone
+// CommitsByTimezone returns commits grouped by the UTC offset they were
+// made at, whatever Options.Timezone is
 func (r *Repository) CommitsByTimezone() (map[string]int, error) {
 	logOpts := r.toLogOptions()
 	analyzer := analysis2.NewTemporalAnalyzer(r.backend, logOpts)
 	return analyzer.CommitsByTimezone()
 }
 
-// BranchTree returns the branch tree structure
+// BranchTree returns the topology of the local branches: every branch
+// hangs off the branch it forked from, with its fork point and the
+// commits it is ahead and behind. The tree is rooted at Options.Branch,
+// or else main, master or the current branch. Tree.Render draws it.
 func (r *Repository) BranchTree() (*Tree, error) {
 	logOpts := r.toLogOptions()
 	analyzer := analysis2.NewBranchAnalyzer(r.backend, logOpts)
 
-	treeOutput, err := analyzer.BranchTree()
+	graph, err := analyzer.BranchGraph(r.options.Branch)
 	if err != nil {
 		return nil, err
 	}
 
-	// Return a simple tree with the output
-	return &Tree{
-		Root:     r.path,
-		Branches: []TreeNode{{Name: treeOutput}},
-	}, nil
+	tree := &Tree{
+		Root:     graph.Root,
+		Branches: make([]TreeNode, len(graph.Branches)),
+	}
+
+	for i, b := range graph.Branches {
+		tree.Branches[i] = TreeNode{
+			Name:      b.Name,
+			Hash:      b.Hash,
+			UpdatedAt: b.LastCommit,
+			Parent:    b.Parent,
+			Children:  b.Children,
+			ForkPoint: b.ForkPoint,
+			Commits:   b.Commits,
+			Ahead:     b.Ahead,
+			Behind:    b.Behind,
+		}
+	}
+
+	return tree, nil
 }
 
 // BranchesByDate returns branches sorted by date
@@ -307,34 +920,77 @@ func (r *Repository) BranchesByDate() ([]Branch, error) {
 	return result, nil
 }
 
-// CommitsCalendar returns a calendar heatmap of commits
+// CommitsCalendar returns a calendar heatmap of commits from
+// Options.Since to Options.Until, defaulting to the current year up to
+// today. A non-empty author keeps the commits of one identity, given as a
+// name, an email or "Name <email>"; emails are resolved through .mailmap
+// and the identity options, so any address of a person works.
 func (r *Repository) CommitsCalendar(author string) (*Calendar, error) {
+	now := time.Now()
+	if location, _ := r.options.location(); location != nil {
+		now = now.In(location)
+	}
+
+	from, to := r.options.Since, r.options.Until
+	if from.IsZero() {
+		from = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+	}
+	if to.IsZero() {
+		to = now
+	}
+
+	return r.CommitsCalendarRange(from, to, author)
+}
+
+// CommitsCalendarYear returns the calendar heatmap of a whole year, see
+// CommitsCalendar for author
+func (r *Repository) CommitsCalendarYear(year int, author string) (*Calendar, error) {
+	location, _ := r.options.location()
+	if location == nil {
+		location = time.Local
+	}
+
+	from := time.Date(year, 1, 1, 0, 0, 0, 0, location)
+	return r.CommitsCalendarRange(from, from.AddDate(1, 0, -1), author)
+}
+
+// CommitsCalendarRange returns the calendar heatmap of the days from from
+// to to inclusive, which may span several years, laid out in whole months.
+// See CommitsCalendar for author.
+func (r *Repository) CommitsCalendarRange(from, to time.Time, author string) (*Calendar, error) {
 	logOpts := r.toLogOptions()
 	analyzer := analysis2.NewTemporalAnalyzer(r.backend, logOpts)
 
-	year := time.Now().Year()
-	calData, err := analyzer.GenerateCalendar(year, author)
+	calData, err := analyzer.GenerateCalendarRange(from, to, author)
 	if err != nil {
 		return nil, err
 	}
 
 	// Convert to public type
 	result := &Calendar{
-		Year:   calData.Year,
-		Months: make([]MonthData, len(calData.Months)),
+		Year:      calData.Year,
+		From:      calData.From,
+		To:        calData.To,
+		Months:    make([]MonthData, len(calData.Months)),
+		Total:     calData.Total,
+		Max:       calData.Max,
+		Quartiles: calData.Quartiles,
 	}
 
 	for i, m := range calData.Months {
 		result.Months[i] = MonthData{
+			Year:  m.Year,
 			Month: int(m.Month),
-			Days:  make(map[int]int),
+			Days:  m.Days,
+			Weeks: m.WeekMatrix,
 		}
 	}
 
 	return result, nil
 }
 
-// CommitsHeatmap returns a heatmap of commits for the last N days
+// CommitsHeatmap returns a heatmap of commits for the last N days, every
+// day included, along with their breakdown by hour
 func (r *Repository) CommitsHeatmap(days int) (*Heatmap, error) {
 	logOpts := r.toLogOptions()
 	analyzer := analysis2.NewTemporalAnalyzer(r.backend, logOpts)
@@ -347,7 +1003,11 @@ func (r *Repository) CommitsHeatmap(days int) (*Heatmap, error) {
 	// Convert to public type
 	result := &Heatmap{
 		Days:  make([]HeatmapDay, len(heatData.Days)),
-		Hours: make([]HeatmapHour, 0),
+		Hours: make([]HeatmapHour, 24),
+	}
+
+	for hour := range result.Hours {
+		result.Hours[hour].Hour = hour
 	}
 
 	for i, d := range heatData.Days {
@@ -355,19 +1015,87 @@ func (r *Repository) CommitsHeatmap(days int) (*Heatmap, error) {
 			Date:    d.Date,
 			Commits: d.CommitCount,
 		}
+
+		for hour, count := range d.HourlyBreakdown {
+			result.Days[i].Hours[hour] = count
+			result.Hours[hour].Commits += count
+		}
 	}
 
 	return result, nil
 }
 
-// Changelogs generates changelogs
+// Punchcard returns the commits, and optionally the changed lines, by
+// weekday and hour of the day, e.g. to check whether a team works
+// sustainable hours
+func (r *Repository) Punchcard(filter PunchcardFilter) (*Punchcard, error) {
+	logOpts := r.toLogOptions()
+	logOpts.PathSpec = append(append([]string{}, logOpts.PathSpec...), filter.Paths...)
+
+	analyzer := analysis2.NewTemporalAnalyzer(r.backend, logOpts)
+
+	data, err := analyzer.Punchcard(filter.Author, filter.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Punchcard{
+		Commits: data.Commits,
+		Lines:   data.Lines,
+		Total:   data.Total,
+	}, nil
+}
+
+// Changelogs generates changelogs grouped by tag, newest first.
+// Commits after the latest tag are reported under the "Unreleased" version.
+// Options.Limit caps the commits of the whole changelog, leaving out the
+// older versions once it is reached.
 func (r *Repository) Changelogs() ([]Changelog, error) {
-	// TODO: Implement changelog generation
-	return nil, fmt.Errorf("not implemented yet")
+	logOpts := r.toLogOptions()
+	analyzer := analysis2.NewChangelogAnalyzer(r.backend, logOpts)
+
+	versions, err := analyzer.Changelogs()
+	if err != nil {
+		return nil, err
+	}
+
+	return toChangelogs(versions, ""), nil
 }
 
 // ChangelogsByAuthor generates changelogs for a specific author
 func (r *Repository) ChangelogsByAuthor(author string) ([]Changelog, error) {
-	// TODO: Implement author-specific changelogs
-	return nil, fmt.Errorf("not implemented yet")
+	logOpts := r.toLogOptions()
+	analyzer := analysis2.NewChangelogAnalyzer(r.backend, logOpts)
+
+	versions, err := analyzer.ChangelogsByAuthor(author)
+	if err != nil {
+		return nil, err
+	}
+
+	return toChangelogs(versions, author), nil
+}
+
+// toChangelogs converts analyzer versions to public changelogs
+func toChangelogs(versions []analysis2.ChangelogVersion, author string) []Changelog {
+	result := make([]Changelog, len(versions))
+	for i, v := range versions {
+		result[i] = Changelog{
+			Version: v.Version,
+			Date:    v.Date,
+			Author:  author,
+			Changes: make([]ChangeEntry, len(v.Changes)),
+		}
+
+		for j, c := range v.Changes {
+			result[i].Changes[j] = ChangeEntry{
+				Type:     c.Type,
+				Scope:    c.Scope,
+				Message:  c.Message,
+				Hash:     c.Hash,
+				Breaking: c.Breaking,
+			}
+		}
+	}
+
+	return result
 }
@@ -1,10 +1,20 @@
 package git_nerds
 
 import (
+	"context"
+	"errors"
+	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+
+	git2 "github.com/inovacc/git-nerds/internal/git"
+	"github.com/inovacc/git-nerds/internal/gittest"
 )
 
 func TestOpen(t *testing.T) {
@@ -383,6 +393,1404 @@ func TestExportCSV(t *testing.T) {
 	}
 }
 
+func TestOpenBackends(t *testing.T) {
+	fixture := gittest.New(t)
+	day := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	fixture.Write("main.go", "package main\n")
+	fixture.Commit("Alice", "alice@example.com", "feat: start", day)
+	fixture.Write("main.go", "package main\n\nfunc main() {}\n")
+	fixture.Commit("Bob", "bob@example.com", "feat: main", day.AddDate(0, 0, 1))
+
+	var results []*Stats
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		repo, err := Open(fixture.Dir, &Options{Backend: backend})
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", backend, err)
+		}
+
+		stats, err := repo.DetailedStats()
+		if err != nil {
+			t.Fatalf("DetailedStats(%s) error = %v", backend, err)
+		}
+
+		results = append(results, stats)
+	}
+
+	exec, gogit := results[0], results[1]
+	if exec.TotalCommits != 2 || gogit.TotalCommits != exec.TotalCommits {
+		t.Errorf("TotalCommits exec=%d go-git=%d, want 2", exec.TotalCommits, gogit.TotalCommits)
+	}
+
+	if gogit.LinesAdded != exec.LinesAdded || gogit.LinesDeleted != exec.LinesDeleted {
+		t.Errorf("lines differ: exec=+%d/-%d go-git=+%d/-%d",
+			exec.LinesAdded, exec.LinesDeleted, gogit.LinesAdded, gogit.LinesDeleted)
+	}
+
+	for _, stats := range results {
+		if stats.TotalFiles != 1 || len(stats.Files) != 1 || stats.Files[0].Changes != 2 {
+			t.Errorf("TotalFiles = %d, Files = %+v, want main.go changed twice", stats.TotalFiles, stats.Files)
+		}
+
+		if stats.ActiveDays != 2 || !stats.FirstCommitAt.Equal(day) {
+			t.Errorf("ActiveDays = %d, FirstCommitAt = %v, want 2 and %v", stats.ActiveDays, stats.FirstCommitAt, day)
+		}
+	}
+}
+
+func TestCommits(t *testing.T) {
+	fixture := gittest.New(t)
+	day := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	fixture.Write("main.go", "package main\n")
+	fixture.Commit("Alice", "alice@example.com", "feat: start", day)
+	fixture.Write("main.go", "package main\n\nfunc main() {}\n")
+	fixture.Write("README.md", "# demo\n")
+	fixture.Commit("Bob", "bob@example.com", "docs: readme | intro\n\nCovers a|b|c|d|e.\nSecond line.", day.AddDate(0, 0, 1))
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			repo, err := Open(fixture.Dir, &Options{Backend: backend})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			var commits []Commit
+			for c, err := range repo.Commits(context.Background()) {
+				if err != nil {
+					t.Fatalf("Commits() error = %v", err)
+				}
+				commits = append(commits, c)
+			}
+
+			if len(commits) != 2 {
+				t.Fatalf("Commits() yielded %d commits, want 2", len(commits))
+			}
+
+			latest := commits[0]
+			if latest.Author != "Bob" || latest.Message != "docs: readme | intro" || !latest.Date.Equal(day.AddDate(0, 0, 1)) {
+				t.Errorf("latest commit = %+v", latest)
+			}
+
+			if latest.Body != "Covers a|b|c|d|e.\nSecond line." {
+				t.Errorf("latest commit body = %q", latest.Body)
+			}
+
+			if latest.Additions != 3 || latest.Deletions != 0 || len(latest.Files) != 2 {
+				t.Errorf("latest commit changes = +%d/-%d %v, want +3/-0 over 2 files",
+					latest.Additions, latest.Deletions, latest.Files)
+			}
+
+			// Breaking out early must stop the underlying walk cleanly
+			for range repo.Commits(context.Background()) {
+				break
+			}
+		})
+	}
+}
+
+func TestFileHistory(t *testing.T) {
+	fixture := gittest.New(t)
+	day := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+	content := "package main\n\nfunc a() {}\nfunc b() {}\nfunc c() {}\n"
+
+	fixture.Write("main.go", content)
+	fixture.Commit("Alice", "alice@example.com", "feat: start", day)
+	// git only looks for copy sources among the files a commit modifies
+	fixture.Write("app.go", content+"func d() {}\n")
+	fixture.Write("main.go", content+"func e() {}\n")
+	fixture.Commit("Bob", "bob@example.com", "feat: copy", day.AddDate(0, 0, 1))
+	fixture.Git(day, "mv", "main.go", "cmd.go")
+	fixture.Commit("Carol", "carol@example.com", "refactor: move", day.AddDate(0, 0, 2))
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			repo, err := Open(fixture.Dir, &Options{Backend: backend})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			history, err := repo.FileHistory("cmd.go")
+			if err != nil || len(history) != 3 || history[0].OldPath != "main.go" || history[2].Author != "Alice" {
+				t.Errorf("FileHistory() = %+v, %v, want the move and the commits to main.go", history, err)
+			}
+
+			files, err := repo.Files()
+			if err != nil {
+				t.Fatalf("Files() error = %v", err)
+			}
+			for _, f := range files {
+				if f.Path == "cmd.go" && (f.Changes != 3 || len(f.PreviousPaths) != 1) {
+					t.Errorf("cmd.go = %+v, want 3 changes renamed from main.go", f)
+				}
+			}
+		})
+	}
+
+	// Copies are followed as well when detected
+	repo, err := Open(fixture.Dir, &Options{Backend: BackendExec, DetectCopies: true})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	history, err := repo.FileHistory("app.go")
+	if err != nil || len(history) != 2 || history[0].OldPath != "main.go" {
+		t.Errorf("FileHistory() with copies = %+v, %v, want the copy and its source", history, err)
+	}
+
+	if _, err := Open(fixture.Dir, &Options{RenameThreshold: 101}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Open() with threshold 101 error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestBinaryStats(t *testing.T) {
+	fixture := gittest.New(t)
+	day := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+	pointer := "version https://git-lfs.github.com/spec/v1\noid sha256:" + strings.Repeat("a", 64) + "\nsize 4096\n"
+
+	fixture.Write(".gitattributes", "*.wav filter=lfs diff=lfs merge=lfs -text\n")
+	fixture.Write("icon.bin", "\x00\x01\x02\x03")
+	fixture.Write("theme.wav", pointer)
+	fixture.Commit("Alice", "alice@example.com", "feat: assets", day)
+	fixture.Write("icon.bin", "\x00\x01\x02\x03\x04\x05")
+	fixture.Commit("Alice", "alice@example.com", "feat: bigger icon", day.AddDate(0, 0, 1))
+
+	repo, err := Open(fixture.Dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	stats, err := repo.DetailedStats()
+	if err != nil {
+		t.Fatalf("DetailedStats() error = %v", err)
+	}
+
+	if stats.BinaryFiles != 1 || stats.BinaryChanges != 2 || stats.BinaryBytes != 6 {
+		t.Errorf("binary stats = %d files, %d changes, %d bytes, want 1, 2, 6", stats.BinaryFiles, stats.BinaryChanges, stats.BinaryBytes)
+	}
+
+	if stats.LFSFiles != 1 || stats.LFSChanges != 1 || stats.LFSBytes != 4096 {
+		t.Errorf("LFS stats = %d files, %d changes, %d bytes, want 1, 1, 4096", stats.LFSFiles, stats.LFSChanges, stats.LFSBytes)
+	}
+
+	// Only .gitattributes has lines
+	if stats.LinesAdded != 1 {
+		t.Errorf("LinesAdded = %d, want 1", stats.LinesAdded)
+	}
+}
+
+func TestCoAuthors(t *testing.T) {
+	fixture := gittest.New(t)
+	day := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	fixture.Write("main.go", "package main\n")
+	fixture.Commit("Alice", "alice@example.com", "feat: pair\n\nCo-authored-by: Bob <bob@example.com>\nReviewed-by: Carol <carol@example.com>", day)
+
+	repo, err := Open(fixture.Dir, &Options{AttributeCoAuthors: true, CoAuthorSplit: CoAuthorSplitFull})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	for commit, err := range repo.Commits(context.Background()) {
+		if err != nil || len(commit.Trailers) != 2 || commit.Trailers[0].Key != TrailerCoAuthoredBy || commit.Trailers[1].Email != "carol@example.com" {
+			t.Errorf("Commits() = %+v, %v, want the co-author and reviewer trailers", commit, err)
+		}
+	}
+
+	stats, err := repo.DetailedStats()
+	if err != nil {
+		t.Fatalf("DetailedStats() error = %v", err)
+	}
+
+	// Both are credited with the line, which is still counted once
+	if stats.TotalAuthors != 2 || stats.Authors[1].LinesAdded != 1 || stats.LinesAdded != 1 {
+		t.Errorf("DetailedStats() = %+v, want Alice and Bob credited with the commit's line", stats)
+	}
+
+	reviewers, err := repo.TopReviewers(5)
+	if err != nil || len(reviewers) != 1 || reviewers[0].Name != "Carol" || reviewers[0].Commits != 1 {
+		t.Errorf("TopReviewers() = %+v, %v, want Carol", reviewers, err)
+	}
+
+	if reviewers, err := repo.TopReviewers(-1); err != nil || len(reviewers) != 0 {
+		t.Errorf("TopReviewers(-1) = %+v, %v, want none", reviewers, err)
+	}
+
+	if _, err := Open(fixture.Dir, &Options{CoAuthorSplit: "half"}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Open() with an unknown split error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestBranchTree(t *testing.T) {
+	fixture := gittest.New(t)
+	day := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	fixture.Commit("Alice", "alice@example.com", "feat: one", day)
+	fixture.Git(day, "checkout", "-q", "-b", "develop")
+	fixture.Commit("Alice", "alice@example.com", "feat: two", day)
+	fixture.Git(day, "checkout", "-q", "-b", "feature/login")
+	fixture.Commit("Bob", "bob@example.com", "feat: login", day)
+	fixture.Git(day, "checkout", "-q", "main")
+	fixture.Commit("Alice", "alice@example.com", "fix: urgent", day)
+
+	repo, err := Open(fixture.Dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	tree, err := repo.BranchTree()
+	if err != nil {
+		t.Fatalf("BranchTree() error = %v", err)
+	}
+
+	login, ok := tree.Node("feature/login")
+	if !ok || login.Parent != "develop" || login.Ahead != 1 || login.Commits != 3 {
+		t.Errorf("feature/login = %+v, want one commit ahead of develop", login)
+	}
+
+	want := "main (2 commits)\n└── develop +1 -1\n    └── feature/login +1\n"
+	if got := tree.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestCompareBranches(t *testing.T) {
+	fixture := gittest.New(t)
+	day := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	fixture.Write("app.go", "a\n")
+	fixture.Commit("Alice", "alice@example.com", "feat: app", day)
+	fixture.Git(day, "checkout", "-q", "-b", "release/1.0")
+	fixture.Write("app.go", "a\nb\nc\n")
+	fixture.Commit("Bob", "bob@example.com", "fix: backport", day.AddDate(0, 0, 1))
+	fixture.Git(day, "checkout", "-q", "main")
+	fixture.Write("new.go", "x\n")
+	fixture.Commit("Alice", "alice@example.com", "feat: next", day.AddDate(0, 0, 2))
+	fixture.Write("new.go", "x\ny\n")
+	fixture.Commit("Carol", "carol@example.com", "feat: more", day.AddDate(0, 0, 3))
+
+	for name, opts := range map[string]*Options{
+		"exec":   {Backend: BackendExec},
+		"go-git": {Backend: BackendGoGit},
+		"cached": {CommitCache: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo, err := Open(fixture.Dir, opts)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			stats, err := repo.StatsByBranch("release/1.0")
+			if err != nil {
+				t.Fatalf("StatsByBranch() error = %v", err)
+			}
+			if stats.TotalCommits != 2 || stats.TotalAuthors != 2 || stats.LinesAdded != 3 {
+				t.Errorf("StatsByBranch() = %d commits, %d authors, +%d, want 2, 2, +3",
+					stats.TotalCommits, stats.TotalAuthors, stats.LinesAdded)
+			}
+
+			cmp, err := repo.CompareBranches("release/1.0", "main")
+			if err != nil {
+				t.Fatalf("CompareBranches() error = %v", err)
+			}
+			if cmp.Ahead != 1 || cmp.Behind != 2 {
+				t.Errorf("CompareBranches() ahead/behind = %d/%d, want 1/2", cmp.Ahead, cmp.Behind)
+			}
+			if cmp.OnlyA.TotalCommits != 1 || len(cmp.OnlyA.Authors) != 1 || cmp.OnlyA.Authors[0].Name != "Bob" {
+				t.Errorf("OnlyA = %+v, want Bob's backport", cmp.OnlyA.Authors)
+			}
+			if cmp.OnlyB.TotalCommits != 2 || cmp.OnlyB.TotalAuthors != 2 || cmp.OnlyB.TotalFiles != 1 || cmp.OnlyB.LinesAdded != 2 {
+				t.Errorf("OnlyB = %d commits, %d authors, %d files, +%d, want 2, 2, 1, +2",
+					cmp.OnlyB.TotalCommits, cmp.OnlyB.TotalAuthors, cmp.OnlyB.TotalFiles, cmp.OnlyB.LinesAdded)
+			}
+
+			// Branches are listed once for both sides
+			refs := &refCountingBackend{Backend: repo.backend}
+			repo.backend = refs
+			cmp, err = repo.CompareBranches("release/1.0", "main")
+			if err != nil || len(cmp.OnlyA.Branches) != 2 || len(cmp.OnlyB.Branches) != 2 {
+				t.Errorf("CompareBranches() branches = %+v, %+v, %v, want both branches on each side", cmp.OnlyA.Branches, cmp.OnlyB.Branches, err)
+			}
+			if refs.heads != 1 {
+				t.Errorf("CompareBranches() listed branches %d times, want 1", refs.heads)
+			}
+
+			if _, err := repo.StatsByBranch("missing"); !errors.Is(err, ErrInvalidBranch) {
+				t.Errorf("StatsByBranch(missing) error = %v, want ErrInvalidBranch", err)
+			}
+			if _, err := repo.CompareBranches("main", "missing"); !errors.Is(err, ErrInvalidBranch) {
+				t.Errorf("CompareBranches(missing) error = %v, want ErrInvalidBranch", err)
+			}
+		})
+	}
+}
+
+// refCountingBackend counts the for-each-ref runs listing branches
+type refCountingBackend struct {
+	git2.Backend
+	heads int
+}
+
+func (b *refCountingBackend) ForEachRef(args ...string) (string, error) {
+	if slices.Contains(args, "refs/heads/") {
+		b.heads++
+	}
+	return b.Backend.ForEachRef(args...)
+}
+
+func TestCommitsCalendar(t *testing.T) {
+	fixture := gittest.New(t)
+
+	fixture.Commit("Alice", "alice@example.com", "one", time.Date(2023, 11, 5, 9, 0, 0, 0, time.UTC))
+	fixture.Commit("Bob", "bob@example.com", "two", time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC))
+	fixture.Commit("Alice", "alice@example.com", "three", time.Date(2024, 6, 10, 15, 0, 0, 0, time.UTC))
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			repo, err := Open(fixture.Dir, &Options{Backend: backend})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			year, err := repo.CommitsCalendarYear(2024, "alice@example.com")
+			if err != nil {
+				t.Fatalf("CommitsCalendarYear() error = %v", err)
+			}
+			if len(year.Months) != 12 || year.Total != 1 || year.Months[5].Days[10] != 1 || year.Level(1) != 1 {
+				t.Errorf("CommitsCalendarYear() = %+v, want Alice's June commit", year)
+			}
+
+			span, err := repo.CommitsCalendarRange(time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), "")
+			if err != nil {
+				t.Fatalf("CommitsCalendarRange() error = %v", err)
+			}
+			if len(span.Months) != 8 || span.Total != 3 || span.Max != 2 || span.Months[0].Days[5] != 1 {
+				t.Errorf("CommitsCalendarRange() = %+v, want 3 commits over 8 months", span)
+			}
+
+			scoped, err := Open(fixture.Dir, &Options{
+				Backend: backend,
+				Since:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				Until:   time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+			})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if calendar, err := scoped.CommitsCalendar(""); err != nil || calendar.Year != 2023 || calendar.Total != 1 {
+				t.Errorf("CommitsCalendar() = %+v, %v, want 2023 with one commit", calendar, err)
+			}
+		})
+	}
+}
+
+func TestCommitsCalendarYearZone(t *testing.T) {
+	fixture := gittest.New(t)
+
+	// New Year's Eve in Los Angeles, already January 1st in UTC
+	la := time.FixedZone("PST", -8*60*60)
+	fixture.Commit("Carol", "carol@example.com", "late", time.Date(2024, 12, 31, 23, 30, 0, 0, la))
+
+	tests := []struct {
+		timezone string
+		year     int
+		month    int
+		day      int
+	}{
+		{TimezoneAuthorLocal, 2024, 11, 31},
+		{TimezoneUTC, 2025, 0, 1},
+		{"America/Los_Angeles", 2024, 11, 31},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			repo, err := Open(fixture.Dir, &Options{Backend: BackendExec, Timezone: tt.timezone})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			calendar, err := repo.CommitsCalendarYear(tt.year, "")
+			if err != nil {
+				t.Fatalf("CommitsCalendarYear() error = %v", err)
+			}
+
+			if calendar.Total != 1 || calendar.Months[tt.month].Days[tt.day] != 1 {
+				t.Errorf("CommitsCalendarYear(%d) = %d commits, want the commit on month %d day %d", tt.year, calendar.Total, tt.month+1, tt.day)
+			}
+		})
+	}
+}
+
+func TestHeatmapAndPunchcard(t *testing.T) {
+	fixture := gittest.New(t)
+	today := time.Now().UTC().Truncate(time.Hour)
+
+	fixture.Write("docs/guide.md", "a\n")
+	fixture.Commit("Alice", "alice@example.com", "docs", today.AddDate(0, 0, -5))
+	fixture.Write("main.go", "a\nb\n")
+	fixture.Commit("Bob", "bob@example.com", "code", today.AddDate(0, 0, -2))
+
+	repo, err := Open(fixture.Dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	heatmap, err := repo.CommitsHeatmap(7)
+	if err != nil {
+		t.Fatalf("CommitsHeatmap() error = %v", err)
+	}
+
+	if len(heatmap.Days) < 8 || len(heatmap.Hours) != 24 {
+		t.Fatalf("CommitsHeatmap() has %d days and %d hours, want every day and hour", len(heatmap.Days), len(heatmap.Hours))
+	}
+
+	commits := 0
+	for i, day := range heatmap.Days {
+		if i > 0 && !day.Date.Equal(heatmap.Days[i-1].Date.AddDate(0, 0, 1)) {
+			t.Errorf("day %d is %s, want the day after %s", i, day.Date, heatmap.Days[i-1].Date)
+		}
+		commits += day.Commits
+	}
+	if hour := heatmap.Hours[today.Hour()]; commits != 2 || hour.Hour != today.Hour() || hour.Commits != 2 {
+		t.Errorf("CommitsHeatmap() = %d commits, %+v at %d:00, want 2 at that hour", commits, hour, today.Hour())
+	}
+
+	card, err := repo.Punchcard(PunchcardFilter{Paths: []string{"main.go"}, Lines: true})
+	if err != nil {
+		t.Fatalf("Punchcard() error = %v", err)
+	}
+
+	day := today.AddDate(0, 0, -2)
+	if card.Total != 1 || card.Commits[day.Weekday()][day.Hour()] != 1 || card.Lines[day.Weekday()][day.Hour()] != 2 {
+		t.Errorf("Punchcard(main.go) = %+v, want Bob's commit with 2 lines", card)
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	fixture := gittest.New(t)
+
+	// 21:30 UTC on Saturday, and 03:00 UTC on Monday
+	fixture.Commit("Alice", "alice@example.com", "late", time.Date(2024, 1, 6, 23, 30, 0, 0, time.FixedZone("", 2*60*60)))
+	fixture.Commit("Bob", "bob@example.com", "evening", time.Date(2024, 1, 7, 22, 0, 0, 0, time.FixedZone("", -5*60*60)))
+
+	tests := []struct {
+		timezone string
+		hours    map[int]int
+		weekdays map[string]int
+		days     map[string]int
+	}{
+		{"", map[int]int{23: 1, 22: 1}, map[string]int{"Saturday": 1, "Sunday": 1}, map[string]int{"2024-01-06": 1, "2024-01-07": 1}},
+		{TimezoneUTC, map[int]int{21: 1, 3: 1}, map[string]int{"Saturday": 1, "Monday": 1}, map[string]int{"2024-01-06": 1, "2024-01-08": 1}},
+		{"Asia/Tokyo", map[int]int{6: 1, 12: 1}, map[string]int{"Sunday": 1, "Monday": 1}, map[string]int{"2024-01-07": 1, "2024-01-08": 1}},
+	}
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.timezone, func(t *testing.T) {
+				repo, err := Open(fixture.Dir, &Options{Backend: backend, Timezone: tt.timezone})
+				if err != nil {
+					t.Fatalf("Open() error = %v", err)
+				}
+
+				hours, err := repo.CommitsByHour()
+				if err != nil || !reflect.DeepEqual(hours, tt.hours) {
+					t.Errorf("CommitsByHour() = %v, %v, want %v", hours, err, tt.hours)
+				}
+				weekdays, err := repo.CommitsByWeekday()
+				if err != nil || !reflect.DeepEqual(weekdays, tt.weekdays) {
+					t.Errorf("CommitsByWeekday() = %v, %v, want %v", weekdays, err, tt.weekdays)
+				}
+				days, err := repo.CommitsByDay()
+				if err != nil || !reflect.DeepEqual(days, tt.days) {
+					t.Errorf("CommitsByDay() = %v, %v, want %v", days, err, tt.days)
+				}
+
+				zones, err := repo.CommitsByTimezone()
+				if err != nil || !reflect.DeepEqual(zones, map[string]int{"+0200": 1, "-0500": 1}) {
+					t.Errorf("CommitsByTimezone() = %v, %v, want the offsets the commits were made at", zones, err)
+				}
+			})
+		}
+
+		t.Run(backend+"/precise range", func(t *testing.T) {
+			repo, err := Open(fixture.Dir, &Options{
+				Backend: backend,
+				Since:   time.Date(2024, 1, 6, 21, 0, 0, 0, time.UTC),
+				Until:   time.Date(2024, 1, 6, 22, 0, 0, 0, time.UTC),
+			})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			perAuthor, err := repo.CommitsPerAuthor()
+			if err != nil || !reflect.DeepEqual(perAuthor, map[string]int{"Alice": 1}) {
+				t.Errorf("CommitsPerAuthor() = %v, %v, want only Alice's commit", perAuthor, err)
+			}
+		})
+
+		t.Run(backend+"/until midnight", func(t *testing.T) {
+			repo, err := Open(fixture.Dir, &Options{Backend: backend, Until: time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			perAuthor, err := repo.CommitsPerAuthor()
+			if err != nil || len(perAuthor) != 0 {
+				t.Errorf("CommitsPerAuthor() = %v, %v, want none before the midnight Until", perAuthor, err)
+			}
+		})
+	}
+
+	if _, err := Open(fixture.Dir, &Options{Timezone: "Mars/Olympus"}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Open() with unknown timezone error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestDateSource(t *testing.T) {
+	fixture := gittest.New(t)
+
+	fixture.Commit("Alice", "alice@example.com", "feat: one", time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC))
+
+	// Written in January, rebased and landed in March
+	authored := time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+	landed := time.Date(2024, 3, 5, 15, 0, 0, 0, time.UTC)
+	fixture.Git(landed, "commit", "-q", "--allow-empty", "--author", "Bob <bob@example.com>",
+		"--date", authored.Format(time.RFC3339), "-m", "feat: two")
+
+	january := &Options{Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Until: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)}
+	march := &Options{Since: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Until: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		source  string
+		months  map[string]int
+		january map[string]int
+		march   map[string]int
+		tip     time.Time
+	}{
+		{"", map[string]int{"2024-01": 2}, map[string]int{"Alice": 1, "Bob": 1}, map[string]int{}, authored},
+		{DateSourceCommitter, map[string]int{"2024-01": 1, "2024-03": 1}, map[string]int{"Alice": 1}, map[string]int{"Bob": 1}, landed},
+	}
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.source, func(t *testing.T) {
+				open := func(base *Options) *Repository {
+					opts := *base
+					opts.Backend, opts.DateSource = backend, tt.source
+
+					repo, err := Open(fixture.Dir, &opts)
+					if err != nil {
+						t.Fatalf("Open() error = %v", err)
+					}
+					return repo
+				}
+
+				repo := open(&Options{})
+
+				months, err := repo.CommitsByMonth()
+				if err != nil || !reflect.DeepEqual(months, tt.months) {
+					t.Errorf("CommitsByMonth() = %v, %v, want %v", months, err, tt.months)
+				}
+
+				for name, want := range map[string]map[string]int{"january": tt.january, "march": tt.march} {
+					scope := january
+					if name == "march" {
+						scope = march
+					}
+
+					got, err := open(scope).CommitsPerAuthor()
+					if err != nil || !reflect.DeepEqual(got, want) {
+						t.Errorf("CommitsPerAuthor() in %s = %v, %v, want %v", name, got, err, want)
+					}
+				}
+
+				branches, err := repo.BranchesByDate()
+				if err != nil || len(branches) != 1 || !branches[0].UpdatedAt.Equal(tt.tip) {
+					t.Errorf("BranchesByDate() = %+v, %v, want main updated at %s", branches, err, tt.tip)
+				}
+
+				drifted, err := repo.DateDrift(24 * time.Hour)
+				if err != nil || len(drifted) != 1 {
+					t.Fatalf("DateDrift() = %+v, %v, want Bob's rebased commit", drifted, err)
+				}
+				if c := drifted[0]; !c.AuthorDate.Equal(authored) || !c.CommitDate.Equal(landed) || c.Message != "feat: two" {
+					t.Errorf("DateDrift() = %+v, want authored %s and committed %s", c, authored, landed)
+				}
+
+				if drifted, err := repo.DateDrift(365 * 24 * time.Hour); err != nil || len(drifted) != 0 {
+					t.Errorf("DateDrift(1 year) = %+v, %v, want none", drifted, err)
+				}
+			})
+		}
+	}
+
+	if _, err := Open(fixture.Dir, &Options{DateSource: "tagger"}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Open() with unknown date source error = %v, want ErrInvalidOptions", err)
+	}
+	if _, err := Open(fixture.Dir, &Options{MaxDateSkew: -time.Hour}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Open() with negative max date skew error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestDateSourceSkewedClock(t *testing.T) {
+	fixture := gittest.New(t)
+
+	fixture.Commit("Alice", "alice@example.com", "feat: one", time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC))
+
+	// Authored on a clock running two months ahead of the committer's
+	authored := time.Date(2024, 3, 20, 9, 0, 0, 0, time.UTC)
+	committed := time.Date(2024, 1, 20, 9, 0, 0, 0, time.UTC)
+	fixture.Git(committed, "commit", "-q", "--allow-empty", "--author", "Bob <bob@example.com>",
+		"--date", authored.Format(time.RFC3339), "-m", "feat: two")
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+			// git isn't asked for history committed more than a day before
+			// Since by default, which is where Bob's commit landed
+			bounded, err := Open(fixture.Dir, &Options{Backend: backend, Since: since})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			got, err := bounded.CommitsPerAuthor()
+			if err != nil || len(got) != 0 {
+				t.Errorf("CommitsPerAuthor() since March with the default skew = %v, %v, want none", got, err)
+			}
+
+			repo, err := Open(fixture.Dir, &Options{Backend: backend, Since: since, MaxDateSkew: 90 * 24 * time.Hour})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			got, err = repo.CommitsPerAuthor()
+			if want := map[string]int{"Bob": 1}; err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("CommitsPerAuthor() since March = %v, %v, want %v", got, err, want)
+			}
+
+			drifted, err := repo.DateDrift(24 * time.Hour)
+			if err != nil || len(drifted) != 1 || !drifted[0].AuthorDate.Equal(authored) {
+				t.Errorf("DateDrift() = %+v, %v, want Bob's commit", drifted, err)
+			}
+		})
+	}
+}
+
+func TestOwnership(t *testing.T) {
+	fixture := gittest.New(t)
+	day := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	fixture.Write("cmd/main.go", "package main\n\nfunc main() {}\n")
+	fixture.Commit("Alice", "alice@example.com", "feat: start", day)
+	fixture.Write("cmd/main.go", "package main\n\nfunc main() { run() }\n")
+	fixture.Commit("Bob", "bob@example.com", "feat: run", day.AddDate(0, 0, 1))
+
+	repo, err := Open(fixture.Dir, &Options{Backend: BackendExec})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	file, err := repo.Ownership("cmd/main.go")
+	if err != nil {
+		t.Fatalf("Ownership() error = %v", err)
+	}
+
+	if file.Lines != 3 || len(file.Owners) != 2 || file.Owners[0].Name != "Alice" || file.Owners[0].Lines != 2 {
+		t.Errorf("Ownership() = %+v, want alice owning 2 of 3 lines", file)
+	}
+
+	if _, err := repo.Ownership("cmd/missing.go"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Ownership() of a missing file error = %v, want ErrInvalidPath", err)
+	}
+
+	report, err := repo.OwnershipReport()
+	if err != nil {
+		t.Fatalf("OwnershipReport() error = %v", err)
+	}
+
+	if report.Total.Lines != 3 || len(report.Files) != 1 || len(report.Directories) != 1 || report.Directories[0].Path != "cmd" {
+		t.Errorf("OwnershipReport() = %+v", report)
+	}
+}
+
+func TestBusFactor(t *testing.T) {
+	fixture := gittest.New(t)
+	day := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	fixture.Write("api/server.go", "package api\n")
+	fixture.Write("api/client.go", "package api\n")
+	fixture.Commit("Alice", "alice@example.com", "feat: api", day)
+	fixture.Write("web/index.html", "<html></html>\n")
+	fixture.Commit("Bob", "bob@example.com", "feat: web", day.AddDate(0, 0, 1))
+
+	repo, err := Open(fixture.Dir, &Options{Backend: BackendExec})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	report, err := repo.BusFactor()
+	if err != nil {
+		t.Fatalf("BusFactor() error = %v", err)
+	}
+
+	if report.Total.Factor != 1 || report.Total.Files != 3 || report.Total.KeyAuthors[0] != "alice@example.com" {
+		t.Errorf("BusFactor() total = %+v, want alice alone holding most files", report.Total)
+	}
+
+	if want := []string{".", "api", "web"}; !reflect.DeepEqual(report.AtRisk, want) {
+		t.Errorf("BusFactor() AtRisk = %v, want %v", report.AtRisk, want)
+	}
+}
+
+func TestSubmodules(t *testing.T) {
+	day := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	lib := gittest.New(t)
+	lib.Write("lib.go", "package lib\n")
+	lib.Commit("Carol", "carol@example.com", "feat: lib", day)
+	lib.Write("lib.go", "package lib\n\nfunc Lib() {}\n")
+	lib.Commit("Alice", "alice@example.com", "feat: Lib", day.AddDate(0, 0, 1))
+
+	fixture := gittest.New(t)
+	fixture.Write("main.go", "package main\n")
+	fixture.Commit("Alice", "alice@example.com", "feat: main", day)
+	fixture.Git(day, "-c", "protocol.file.allow=always", "submodule", "add", "-q", lib.Dir, "vendor/lib")
+	fixture.Commit("Alice", "alice@example.com", "chore: add lib", day.AddDate(0, 0, 2))
+
+	repo, err := Open(fixture.Dir, &Options{Backend: BackendExec})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	submodules, err := repo.Submodules()
+	if err != nil {
+		t.Fatalf("Submodules() error = %v", err)
+	}
+
+	if len(submodules) != 1 || submodules[0].Path != "vendor/lib" || !submodules[0].Initialized || submodules[0].Bumps != 0 {
+		t.Errorf("Submodules() = %+v, want initialized vendor/lib", submodules)
+	}
+
+	stats, err := repo.DetailedStats()
+	if err != nil {
+		t.Fatalf("DetailedStats() error = %v", err)
+	}
+
+	if stats.TotalCommits != 2 || stats.TotalAuthors != 1 {
+		t.Errorf("DetailedStats() = %d commits, %d authors, want the parent only", stats.TotalCommits, stats.TotalAuthors)
+	}
+
+	repo, err = Open(fixture.Dir, &Options{Backend: BackendExec, RecurseSubmodules: true})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	stats, err = repo.DetailedStats()
+	if err != nil {
+		t.Fatalf("DetailedStats() error = %v", err)
+	}
+
+	if stats.TotalCommits != 4 || stats.TotalAuthors != 2 || stats.ActiveDays != 3 || stats.TotalFiles != 4 {
+		t.Errorf("DetailedStats() = %d commits, %d authors, %d days, %d files, want 4, 2, 3, 4",
+			stats.TotalCommits, stats.TotalAuthors, stats.ActiveDays, stats.TotalFiles)
+	}
+
+	rows := make(map[string]int)
+	for _, a := range stats.Authors {
+		rows[a.Email+"@"+a.Submodule] = a.Commits
+	}
+
+	want := map[string]int{
+		"alice@example.com@":           2,
+		"alice@example.com@vendor/lib": 1,
+		"carol@example.com@vendor/lib": 1,
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Authors = %v, want %v", rows, want)
+	}
+
+	found := false
+	for _, f := range stats.Files {
+		if f.Path == "vendor/lib/lib.go" && f.Submodule == "vendor/lib" && f.Changes == 2 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Files = %+v, want vendor/lib/lib.go from the submodule", stats.Files)
+	}
+
+	bare := filepath.Join(t.TempDir(), "mirror.git")
+	fixture.Git(day, "clone", "-q", "--bare", fixture.Dir, bare)
+
+	mirror, err := Open(bare, &Options{Backend: BackendGoGit, RecurseSubmodules: true})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	// A mirror cannot tell whether submodules are checked out
+	if _, err := mirror.Submodules(); !errors.Is(err, ErrBareRepository) {
+		t.Errorf("Submodules() of bare error = %v, want ErrBareRepository", err)
+	}
+
+	if _, err := mirror.DetailedStats(); !errors.Is(err, ErrBareRepository) {
+		t.Errorf("DetailedStats() of bare error = %v, want ErrBareRepository", err)
+	}
+
+	// Without submodules to look up, a mirror needs no working tree
+	plain := filepath.Join(t.TempDir(), "lib.git")
+	lib.Git(day, "clone", "-q", "--bare", lib.Dir, plain)
+
+	mirror, err = Open(plain, &Options{Backend: BackendGoGit, RecurseSubmodules: true})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if stats, err := mirror.DetailedStats(); err != nil || stats.TotalCommits != 2 {
+		t.Errorf("DetailedStats() of bare without submodules = %v, want its 2 commits", err)
+	}
+}
+
+func TestWorkspace(t *testing.T) {
+	root := t.TempDir()
+	day := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	repo := func(name string) *gittest.Repo {
+		fixture := gittest.New(t)
+		fixture.Dir = filepath.Join(root, name)
+		if err := os.MkdirAll(fixture.Dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		fixture.Git(day, "init", "-q", "-b", "main")
+		return fixture
+	}
+
+	api := repo("services/api")
+	api.Write("main.go", "package main\n")
+	api.Commit("Alice", "alice@example.com", "feat: api", day)
+	api.Write("main.go", "package main\n\nfunc main() {}\n")
+	api.Commit("Bob", "bob@example.com", "feat: main", day.AddDate(0, 0, 1))
+
+	web := repo("web")
+	web.Write("index.js", "export {}\n")
+	web.Commit("Alice", "ALICE@example.com", "feat: web", day.AddDate(0, 0, 1))
+
+	// Not a repository, and a bare mirror
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	api.Git(day, "clone", "-q", "--bare", api.Dir, filepath.Join(root, "mirrors", "api.git"))
+
+	workspace, err := DiscoverWorkspace(root, &Options{Backend: BackendExec})
+	if err != nil {
+		t.Fatalf("DiscoverWorkspace() error = %v", err)
+	}
+
+	if want := []string{"mirrors/api.git", "services/api", "web"}; !reflect.DeepEqual(workspace.Names(), want) {
+		t.Errorf("Names() = %v, want %v", workspace.Names(), want)
+	}
+	if skipped := workspace.Skipped(); len(skipped) != 0 {
+		t.Errorf("Skipped() = %v, want none", skipped)
+	}
+
+	workspace, err = OpenWorkspace([]string{api.Dir, web.Dir}, &Options{Backend: BackendExec})
+	if err != nil {
+		t.Fatalf("OpenWorkspace() error = %v", err)
+	}
+
+	stats, err := workspace.DetailedStats()
+	if err != nil {
+		t.Fatalf("DetailedStats() error = %v", err)
+	}
+
+	total := stats.Total
+	if total.TotalCommits != 3 || total.TotalAuthors != 2 || total.TotalFiles != 2 || total.ActiveDays != 2 {
+		t.Errorf("Total = %d commits, %d authors, %d files, %d days, want 3, 2, 2, 2",
+			total.TotalCommits, total.TotalAuthors, total.TotalFiles, total.ActiveDays)
+	}
+
+	if len(stats.Repositories) != 2 || stats.Repositories[0].Name != "api" || stats.Repositories[0].Stats.TotalCommits != 2 {
+		t.Errorf("Repositories = %+v, want api with 2 commits first", stats.Repositories)
+	}
+
+	alice := stats.Contributors[0]
+	if alice.Commits != 2 || !reflect.DeepEqual(alice.Repositories, []string{"api", "web"}) || total.Authors[0].ActiveDays != 2 {
+		t.Errorf("Contributors[0] = %+v, want alice with 2 commits in api and web", alice)
+	}
+
+	if total.Files[0].Path != "api/main.go" {
+		t.Errorf("Files[0] = %s, want api/main.go", total.Files[0].Path)
+	}
+
+	csv, err := workspace.ExportCSV()
+	if err != nil || !strings.Contains(csv, "web,Alice,ALICE@example.com,1,") {
+		t.Errorf("ExportCSV() = %q, %v", csv, err)
+	}
+
+	md, err := workspace.ExportMarkdown()
+	if err != nil || !strings.Contains(md, "| Alice | 2 | 2 | 0 | api, web |") {
+		t.Errorf("ExportMarkdown() = %q, %v", md, err)
+	}
+
+	if _, err := workspace.ExportJSON(); err != nil {
+		t.Errorf("ExportJSON() error = %v", err)
+	}
+
+	if _, err := DiscoverWorkspace(filepath.Join(root, "docs")); !errors.Is(err, ErrNotARepository) {
+		t.Errorf("DiscoverWorkspace() without repositories error = %v, want ErrNotARepository", err)
+	}
+}
+
+func TestDiscoverWorkspaceUnreadable(t *testing.T) {
+	root := t.TempDir()
+
+	fixture := gittest.New(t)
+	fixture.Dir = filepath.Join(root, "api")
+	if err := os.MkdirAll(fixture.Dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fixture.Git(time.Time{}, "init", "-q", "-b", "main")
+
+	locked := filepath.Join(root, "private", "locked")
+	if err := os.MkdirAll(locked, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Root reads any directory, so the walk is fed the error it would get
+	info, err := os.Stat(locked)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := &discovery{root: root}
+	if err := found.visit(locked, fs.FileInfoToDirEntry(info), fs.ErrPermission); err != filepath.SkipDir {
+		t.Errorf("visit() of an unreadable directory = %v, want SkipDir", err)
+	}
+	if err := found.visit(root, fs.FileInfoToDirEntry(info), fs.ErrPermission); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("visit() of an unreadable root = %v, want the error", err)
+	}
+	if want := []string{"private/locked"}; !reflect.DeepEqual(found.skipped, want) {
+		t.Errorf("skipped = %v, want %v", found.skipped, want)
+	}
+
+	if os.Geteuid() == 0 {
+		return
+	}
+
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(locked, 0o755)
+
+	workspace, err := DiscoverWorkspace(root)
+	if err != nil {
+		t.Fatalf("DiscoverWorkspace() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(workspace.Names(), []string{"api"}) || !reflect.DeepEqual(workspace.Skipped(), []string{"private/locked"}) {
+		t.Errorf("DiscoverWorkspace() = %v, skipping %v, want api, skipping private/locked", workspace.Names(), workspace.Skipped())
+	}
+}
+
+func TestOpenURL(t *testing.T) {
+	day := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			fixture := gittest.New(t)
+			fixture.Write("main.go", "package main\n")
+			fixture.Commit("Alice", "alice@example.com", "feat: main", day)
+			fixture.Git(day, "branch", "feature")
+
+			opts := &Options{Backend: backend, CacheDir: t.TempDir()}
+
+			for i, url := range []string{"file://" + fixture.Dir, fixture.Dir} {
+				repo, err := OpenURL(url, opts)
+				if err != nil {
+					t.Fatalf("OpenURL(%s) error = %v", url, err)
+				}
+
+				if !repo.IsBare() || filepath.Dir(repo.Path()) != opts.CacheDir {
+					t.Errorf("OpenURL() path = %s, want a bare clone in the cache", repo.Path())
+				}
+
+				branches, err := repo.BranchesByDate()
+				if err != nil || len(branches) != 2 {
+					t.Errorf("BranchesByDate() = %+v, %v, want main and feature", branches, err)
+				}
+
+				counts, err := repo.CommitsPerAuthor()
+				if err != nil || counts["Alice"] != 1+i {
+					t.Errorf("CommitsPerAuthor() = %v, %v, want %d commits by Alice", counts, err, 1+i)
+				}
+
+				// The next open must fetch the new commit
+				fixture.Write("main.go", "package main\n\nfunc main() {}\n")
+				fixture.Commit("Alice", "alice@example.com", "feat: func", day.AddDate(0, 0, 1+i))
+			}
+
+			// Reopening the same URL reuses the clone
+			repo, err := OpenURL(fixture.Dir, opts)
+			if err != nil {
+				t.Fatalf("OpenURL() error = %v", err)
+			}
+
+			counts, err := repo.CommitsPerAuthor()
+			if err != nil || counts["Alice"] != 3 {
+				t.Errorf("CommitsPerAuthor() after fetch = %v, %v, want 3", counts, err)
+			}
+
+			entries, _ := os.ReadDir(opts.CacheDir)
+			if len(entries) != 2 {
+				t.Errorf("cache holds %d entries, want one clone per URL", len(entries))
+			}
+		})
+	}
+}
+
+func TestOpenURLContext(t *testing.T) {
+	fixture := gittest.New(t)
+	fixture.Write("main.go", "package main\n")
+	fixture.Commit("Alice", "alice@example.com", "feat: main", time.Time{})
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			cache := t.TempDir()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			if _, err := OpenURLContext(ctx, "file://"+fixture.Dir, &Options{Backend: backend, CacheDir: cache}); !errors.Is(err, context.Canceled) {
+				t.Errorf("OpenURLContext() cancelled error = %v, want context.Canceled", err)
+			}
+
+			// The interrupted clone is not left behind
+			if entries, _ := os.ReadDir(cache); len(entries) != 0 {
+				t.Errorf("cache holds %d entries after a cancelled clone, want none", len(entries))
+			}
+		})
+	}
+}
+
+func TestOpenURLPartialClone(t *testing.T) {
+	day := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	fixture := gittest.New(t)
+	fixture.Git(day, "config", "uploadpack.allowFilter", "true")
+	fixture.Write("main.go", "package main\n")
+	fixture.Commit("Alice", "alice@example.com", "feat: main", day)
+	fixture.Write("main.go", "package main\n\nfunc main() {}\n")
+	fixture.Commit("Bob", "bob@example.com", "feat: func", day.AddDate(0, 0, 1))
+
+	url := "file://" + fixture.Dir
+	cache := t.TempDir()
+
+	repo, err := OpenURL(url, &Options{Backend: BackendExec, CacheDir: cache, CloneFilter: "blob:none"})
+	if err != nil {
+		t.Fatalf("OpenURL() error = %v", err)
+	}
+
+	out, err := exec.Command("git", "-C", repo.Path(), "config", "remote.origin.partialclonefilter").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "blob:none" {
+		t.Errorf("clone filter = %q, %v, want a blob:none partial clone", out, err)
+	}
+
+	// Line statistics fetch the missing blobs on demand
+	stats, err := repo.DetailedStats()
+	if err != nil || stats.TotalCommits != 2 || stats.LinesAdded != 3 {
+		t.Errorf("DetailedStats() of partial clone = %+v, %v, want 2 commits adding 3 lines", stats, err)
+	}
+
+	// A full clone of the same URL is kept apart
+	if _, err := OpenURL(url, &Options{Backend: BackendExec, CacheDir: cache}); err != nil {
+		t.Fatalf("OpenURL() error = %v", err)
+	}
+	if entries, _ := os.ReadDir(cache); len(entries) != 2 {
+		t.Errorf("cache holds %d entries, want a partial and a full clone", len(entries))
+	}
+
+	if _, err := OpenURL(url, &Options{Backend: BackendGoGit, CacheDir: cache, CloneFilter: "blob:none"}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("OpenURL() with go-git and a filter error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestPruneCache(t *testing.T) {
+	fixture := gittest.New(t)
+	fixture.Write("main.go", "package main\n")
+	fixture.Commit("Alice", "alice@example.com", "feat: main", time.Time{})
+
+	cache := t.TempDir()
+	repo, err := OpenURL(fixture.Dir, &Options{CacheDir: cache})
+	if err != nil {
+		t.Fatalf("OpenURL() error = %v", err)
+	}
+
+	// A stale clone, a clone left behind by an interrupted run and a bare
+	// repository OpenURL didn't create
+	old := time.Now().Add(-48 * time.Hour)
+	stale := filepath.Join(cache, "stale-0123456789abcdef.git")
+	interrupted := filepath.Join(cache, ".clone-123456")
+	foreign := filepath.Join(cache, "mine.git")
+	for _, dir := range []string{stale, interrupted, foreign} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(dir, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := OpenURL(fixture.Dir, &Options{CacheDir: cache, CacheMaxAge: 24 * time.Hour}); err != nil {
+		t.Fatalf("OpenURL() error = %v", err)
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale clone still present: %v", err)
+	}
+	if _, err := os.Stat(interrupted); !os.IsNotExist(err) {
+		t.Errorf("interrupted clone still present: %v", err)
+	}
+	if _, err := os.Stat(foreign); err != nil {
+		t.Errorf("repository not created by OpenURL was pruned: %v", err)
+	}
+	if _, err := os.Stat(repo.Path()); err != nil {
+		t.Errorf("clone in use was pruned: %v", err)
+	}
+
+	if err := PruneCache(cache, time.Nanosecond); err != nil {
+		t.Fatalf("PruneCache() error = %v", err)
+	}
+	if entries, _ := os.ReadDir(cache); len(entries) != 1 || entries[0].Name() != "mine.git" {
+		t.Errorf("PruneCache() left %v, want only mine.git", entries)
+	}
+
+	if _, err := OpenURL(fixture.Dir, &Options{CacheDir: cache, CacheMaxAge: -time.Hour}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("OpenURL() with negative max age error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestCommitCache(t *testing.T) {
+	day := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	fixture := gittest.New(t)
+	fixture.Write("main.go", "package main\n")
+	fixture.Commit("Alice", "alice@example.com", "feat: main", day)
+	fixture.Write("util.go", "package main\n")
+	fixture.Commit("Bob", "bob@example.com", "feat: util", day.AddDate(0, 0, 1))
+
+	plain, err := Open(fixture.Dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	want, err := plain.DetailedStats()
+	if err != nil {
+		t.Fatalf("DetailedStats() error = %v", err)
+	}
+
+	shared := t.TempDir()
+
+	for name, opts := range map[string]*Options{
+		"git directory": {CommitCache: true},
+		"shared":        {CommitCache: true, CommitCacheDir: shared},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo, err := Open(fixture.Dir, opts)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			for run := 0; run < 2; run++ {
+				got, err := repo.DetailedStats()
+				if err != nil {
+					t.Fatalf("DetailedStats() error = %v", err)
+				}
+
+				if got.TotalCommits != want.TotalCommits || got.LinesAdded != want.LinesAdded ||
+					!reflect.DeepEqual(got.Authors, want.Authors) {
+					t.Errorf("run %d: cached DetailedStats() = %+v, want %+v", run, got, want)
+				}
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(fixture.Dir, ".git", "nerds-cache")); err != nil {
+		t.Errorf("cache inside the git directory: %v", err)
+	}
+
+	if entries, _ := os.ReadDir(shared); len(entries) != 1 {
+		t.Errorf("shared cache holds %d entries, want one per repository", len(entries))
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	fixture := gittest.New(t)
+	fixture.Write("a.txt", "a\n")
+	fixture.Commit("Alice", "alice@example.com", "initial", time.Time{})
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			repo, err := Open(fixture.Dir, &Options{Backend: backend})
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			if _, err := repo.WithContext(ctx).DetailedStats(); !errors.Is(err, context.Canceled) {
+				t.Errorf("DetailedStats() error = %v, want context.Canceled", err)
+			}
+
+			expired, stop := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			defer stop()
+
+			var streamErr error
+			for _, err := range repo.Commits(expired) {
+				streamErr = err
+			}
+			if !errors.Is(streamErr, context.DeadlineExceeded) {
+				t.Errorf("Commits() error = %v, want context.DeadlineExceeded", streamErr)
+			}
+
+			if _, err := repo.DetailedStats(); err != nil {
+				t.Errorf("DetailedStats() without context error = %v", err)
+			}
+		})
+	}
+}
+
+func TestOpenLayouts(t *testing.T) {
+	fixture := gittest.New(t)
+	day := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	fixture.Write("pkg/lib.go", "package pkg\n")
+	fixture.Commit("Alice", "alice@example.com", "feat: lib", day)
+
+	linked := filepath.Join(t.TempDir(), "linked")
+	fixture.Git(day, "worktree", "add", "-q", "-b", "side", linked)
+
+	bare := filepath.Join(t.TempDir(), "mirror.git")
+	fixture.Git(day, "clone", "-q", "--bare", fixture.Dir, bare)
+
+	main, _ := filepath.EvalSymlinks(fixture.Dir)
+	linked, _ = filepath.EvalSymlinks(linked)
+	bare, _ = filepath.EvalSymlinks(bare)
+
+	tests := []struct {
+		name     string
+		path     string
+		wantPath string
+		bare     bool
+		worktree bool
+	}{
+		{"working tree", main, main, false, false},
+		{"subdirectory", filepath.Join(main, "pkg"), main, false, false},
+		{"git directory", filepath.Join(main, ".git"), main, false, false},
+		{"linked worktree", linked, linked, false, true},
+		{"bare repository", bare, bare, true, false},
+		{"inside bare repository", filepath.Join(bare, "refs"), bare, true, false},
+	}
+
+	for _, backend := range []string{BackendExec, BackendGoGit} {
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.name, func(t *testing.T) {
+				repo, err := Open(tt.path, &Options{Backend: backend})
+				if err != nil {
+					t.Fatalf("Open() error = %v", err)
+				}
+
+				if repo.Path() != tt.wantPath || repo.IsBare() != tt.bare || repo.IsWorktree() != tt.worktree {
+					t.Errorf("Open() = path %s, bare %v, worktree %v, want %s, %v, %v",
+						repo.Path(), repo.IsBare(), repo.IsWorktree(), tt.wantPath, tt.bare, tt.worktree)
+				}
+
+				if _, err := repo.WorkTree(); tt.bare != errors.Is(err, ErrBareRepository) {
+					t.Errorf("WorkTree() error = %v, want ErrBareRepository only when bare", err)
+				}
+
+				counts, err := repo.CommitsPerAuthor()
+				if err != nil || counts["Alice"] != 1 {
+					t.Errorf("CommitsPerAuthor() = %v, %v, want one commit by Alice", counts, err)
+				}
+
+				own, err := repo.Ownership("pkg")
+				if err != nil || own.Lines != 1 {
+					t.Errorf("Ownership() = %+v, %v, want one line", own, err)
+				}
+			})
+		}
+	}
+
+	if _, err := Open(t.TempDir()); !errors.Is(err, ErrNotARepository) {
+		t.Errorf("Open() outside a repository error = %v, want ErrNotARepository", err)
+	}
+}
+
+func TestOpenAutoBackendWithoutGit(t *testing.T) {
+	fixture := gittest.New(t)
+	fixture.Write("a.txt", "a\n")
+	fixture.Commit("Alice", "alice@example.com", "initial", time.Time{})
+
+	t.Setenv("PATH", "")
+
+	repo, err := Open(fixture.Dir)
+	if err != nil {
+		t.Fatalf("Open() without git binary error = %v", err)
+	}
+
+	counts, err := repo.CommitsByDay()
+	if err != nil {
+		t.Fatalf("CommitsByDay() error = %v", err)
+	}
+
+	if counts["2024-01-01"] != 1 {
+		t.Errorf("CommitsByDay() = %v, want one commit on 2024-01-01", counts)
+	}
+
+	if _, err := Open(fixture.Dir, &Options{Backend: BackendExec}); !errors.Is(err, ErrGitNotFound) {
+		t.Errorf("Open(exec) without git binary error = %v, want ErrGitNotFound", err)
+	}
+}
+
+func TestOpenAutoBackendBrokenGit(t *testing.T) {
+	fixture := gittest.New(t)
+	fixture.Commit("Alice", "alice@example.com", "initial", time.Time{})
+
+	// A git binary that is installed but fails is reported, not replaced
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	if _, err := Open(fixture.Dir); !errors.Is(err, ErrGitNotFound) {
+		t.Errorf("Open() with a broken git binary error = %v, want ErrGitNotFound", err)
+	}
+}
+
+func TestOpenInvalidIdentityAlias(t *testing.T) {
+	_, err := Open(".", &Options{IdentityAliases: map[string]string{"jane@personal.dev": ""}})
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Open() error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestOpenInvalidIgnoreAuthors(t *testing.T) {
+	_, err := Open(".", &Options{IgnoreAuthors: []string{"bot@.*", "[unclosed"}})
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Open() error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestOpenInvalidBackend(t *testing.T) {
+	_, err := Open(".", &Options{Backend: "svn"})
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Open() error = %v, want ErrInvalidOptions", err)
+	}
+}
+
 // Benchmark tests
 func BenchmarkOpen(b *testing.B) {
 	for i := 0; i < b.N; i++ {
@@ -0,0 +1,150 @@
+package git_nerds
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	analysis2 "github.com/inovacc/git-nerds/internal/analysis"
+)
+
+// Submodules lists the submodules pinned in the analyzed tree with the
+// commit they point to and how many times the pin was bumped. Whether a
+// submodule is initialized needs a working tree: a bare repository with
+// submodules returns ErrBareRepository.
+func (r *Repository) Submodules() ([]Submodule, error) {
+	analyzer := analysis2.NewSubmoduleAnalyzer(r.backend, r.toLogOptions())
+
+	submodules, err := analyzer.Submodules()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Submodule, len(submodules))
+	for i, s := range submodules {
+		dir, err := r.submoduleDir(s.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find submodule %s: %w", s.Path, err)
+		}
+
+		result[i] = Submodule{
+			Name:        s.Name,
+			Path:        s.Path,
+			URL:         s.URL,
+			Branch:      s.Branch,
+			Commit:      s.Commit,
+			Bumps:       s.Bumps,
+			UpdatedAt:   s.UpdatedAt,
+			Initialized: dir != "",
+		}
+	}
+
+	return result, nil
+}
+
+// submoduleDir returns the checkout of a submodule, or "" when it isn't
+// initialized
+func (r *Repository) submoduleDir(subPath string) (string, error) {
+	workTree, err := r.WorkTree()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(workTree, filepath.FromSlash(subPath))
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return "", nil
+	}
+
+	return dir, nil
+}
+
+// openSubmodule opens the checkout of a submodule as a child repository.
+// Filters that only make sense for the parent, the branch and path specs,
+// are dropped.
+func (r *Repository) openSubmodule(dir string) (*Repository, error) {
+	options := *r.options
+	options.Branch = ""
+	options.PathSpec = nil
+
+	child, err := Open(dir, &options)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.ctx != nil {
+		child = child.WithContext(r.ctx)
+	}
+
+	return child, nil
+}
+
+// mergeSubmoduleStats adds the statistics of every initialized submodule to
+// stats. Authors and files are tagged with the submodule path and file
+// paths are made relative to the parent. active is updated with the days
+// the submodules were active.
+func (r *Repository) mergeSubmoduleStats(stats *Stats, active *activity) error {
+	submodules, err := r.Submodules()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range submodules {
+		dir, err := r.submoduleDir(s.Path)
+		if err != nil {
+			return err
+		}
+		if dir == "" {
+			continue
+		}
+
+		child, err := r.openSubmodule(dir)
+		if err != nil {
+			return fmt.Errorf("failed to open submodule %s: %w", s.Path, err)
+		}
+
+		// The branches of submodules aren't merged, so they aren't listed
+		childStats, childActive, err := child.collectStats(child.toLogOptions(), nil)
+		if err != nil {
+			return fmt.Errorf("failed to collect stats of submodule %s: %w", s.Path, err)
+		}
+
+		for _, a := range childStats.Authors {
+			a.Submodule = path.Join(s.Path, a.Submodule)
+			stats.Authors = append(stats.Authors, a)
+		}
+
+		for _, f := range childStats.Files {
+			f.Path = path.Join(s.Path, f.Path)
+			f.Submodule = path.Join(s.Path, f.Submodule)
+			stats.Files = append(stats.Files, f)
+		}
+
+		stats.TotalCommits += childStats.TotalCommits
+		stats.TotalMerges += childStats.TotalMerges
+		stats.LinesAdded += childStats.LinesAdded
+		stats.LinesDeleted += childStats.LinesDeleted
+		stats.LinesChanged += childStats.LinesChanged
+
+		if !childStats.FirstCommitAt.IsZero() && (stats.FirstCommitAt.IsZero() || childStats.FirstCommitAt.Before(stats.FirstCommitAt)) {
+			stats.FirstCommitAt = childStats.FirstCommitAt
+		}
+		if childStats.LastCommitAt.After(stats.LastCommitAt) {
+			stats.LastCommitAt = childStats.LastCommitAt
+		}
+
+		active.add(childActive)
+	}
+
+	// The same person may contribute to several repositories
+	emails := make(map[string]bool, len(stats.Authors))
+	for _, a := range stats.Authors {
+		emails[a.Email] = true
+	}
+
+	stats.TotalAuthors = len(emails)
+	stats.TotalFiles = len(stats.Files)
+	stats.ActiveDays = len(active.days)
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package git_nerds
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Node returns the branch called name
+func (t *Tree) Node(name string) (TreeNode, bool) {
+	for _, node := range t.Branches {
+		if node.Name == name {
+			return node, true
+		}
+	}
+	return TreeNode{}, false
+}
+
+// Render draws the tree as indented ASCII art, one branch per line:
+//
+//	main (42 commits)
+//	├── develop +5 -2
+//	│   └── feature/login +3
+//	└── hotfix +1 -10
+//
+// Roots show their number of commits, other branches the commits they
+// are ahead (+) and behind (-) the branch they forked from.
+func (t *Tree) Render() string {
+	var sb strings.Builder
+
+	nodes := make(map[string]TreeNode, len(t.Branches))
+	for _, node := range t.Branches {
+		nodes[node.Name] = node
+	}
+
+	for _, node := range t.Branches {
+		if node.Parent == "" {
+			render(&sb, nodes, node, "", "")
+		}
+	}
+
+	return sb.String()
+}
+
+// render writes node and its descendants. prefix leads the line of node,
+// indent the lines of its children, looked up by name in nodes.
+func render(sb *strings.Builder, nodes map[string]TreeNode, node TreeNode, prefix, indent string) {
+	sb.WriteString(prefix + node.Name)
+
+	if node.Parent == "" {
+		unit := "commits"
+		if node.Commits == 1 {
+			unit = "commit"
+		}
+		fmt.Fprintf(sb, " (%d %s)", node.Commits, unit)
+	} else {
+		if node.Ahead > 0 {
+			fmt.Fprintf(sb, " +%d", node.Ahead)
+		}
+		if node.Behind > 0 {
+			fmt.Fprintf(sb, " -%d", node.Behind)
+		}
+	}
+	sb.WriteString("\n")
+
+	for i, name := range node.Children {
+		child, ok := nodes[name]
+		if !ok {
+			continue
+		}
+
+		if i == len(node.Children)-1 {
+			render(sb, nodes, child, indent+"└── ", indent+"    ")
+		} else {
+			render(sb, nodes, child, indent+"├── ", indent+"│   ")
+		}
+	}
+}
@@ -1,10 +1,15 @@
 package git_nerds
 
-import "time"
+import (
+	"time"
+
+	"github.com/inovacc/git-nerds/internal/parse"
+)
 
 // Stats represents comprehensive repository statistics
 type Stats struct {
 	TotalCommits  int
+	TotalMerges   int // merge commits, zero unless merges are included
 	TotalAuthors  int
 	TotalFiles    int
 	LinesAdded    int
@@ -16,6 +21,43 @@ type Stats struct {
 	Authors       []Author
 	Files         []File
 	Branches      []Branch
+
+	// Binary and Git LFS activity, which line counts leave out: the
+	// files, their changes and the bytes those added (negative when the
+	// files shrank). LFS files are counted by the size of their objects.
+	BinaryFiles   int
+	BinaryChanges int
+	BinaryBytes   int64
+	LFSFiles      int
+	LFSChanges    int
+	LFSBytes      int64
+}
+
+// WorkspaceStats holds the statistics of a workspace: the merged total, the
+// breakdown per repository and the contributors across repositories
+type WorkspaceStats struct {
+	Total        Stats
+	Repositories []RepositoryStats      // in workspace order
+	Contributors []WorkspaceContributor // most commits first
+}
+
+// RepositoryStats holds the statistics of one repository of a workspace
+type RepositoryStats struct {
+	Name  string
+	Path  string
+	Stats Stats
+}
+
+// WorkspaceContributor is an author identity unified across repositories
+type WorkspaceContributor struct {
+	Name         string
+	Email        string
+	Commits      int
+	LinesAdded   int
+	LinesDeleted int
+	FirstCommit  time.Time
+	LastCommit   time.Time
+	Repositories []string // names of the repositories contributed to, in workspace order
 }
 
 // Author represents a contributor's statistics
@@ -30,18 +72,50 @@ type Author struct {
 	FirstCommit  time.Time
 	LastCommit   time.Time
 	ActiveDays   int
+	Submodule    string // path of the submodule the row comes from, "" for the repository itself
 }
 
 // Commit represents a single commit
 type Commit struct {
-	Hash      string
-	Author    string
-	Email     string
-	Date      time.Time
-	Message   string
-	Files     []string
-	Additions int
-	Deletions int
+	Hash       string
+	Author     string
+	Email      string
+	Date       time.Time // AuthorDate or CommitDate, following Options.DateSource
+	AuthorDate time.Time // when the change was originally written
+	CommitDate time.Time // when it was last committed, e.g. rebased or cherry-picked
+	Message    string
+	Body       string    // message after the subject paragraph
+	Trailers   []Trailer // trailer block ending the message
+	Files      []string
+	Additions  int
+	Deletions  int
+}
+
+// Well-known trailer keys
+const (
+	TrailerCoAuthoredBy = parse.TrailerCoAuthoredBy
+	TrailerSignedOffBy  = parse.TrailerSignedOffBy
+	TrailerReviewedBy   = parse.TrailerReviewedBy
+	TrailerReportedBy   = parse.TrailerReportedBy
+)
+
+// Trailer is a "Key: value" line ending a commit message, such as
+// Co-authored-by. Name and Email are set when the value names someone,
+// after .mailmap and the identity options are applied.
+type Trailer struct {
+	Key   string
+	Value string
+	Name  string
+	Email string
+}
+
+// TrailerCount is the number of commits carrying a trailer with the same
+// identity, or the same value for trailers that don't name anyone
+type TrailerCount struct {
+	Name    string
+	Email   string
+	Value   string
+	Commits int
 }
 
 // Branch represents a git branch
@@ -54,14 +128,114 @@ type Branch struct {
 	IsActive  bool
 }
 
+// BranchComparison holds the statistics of the commits unique to each of
+// two branches
+type BranchComparison struct {
+	A, B   string
+	Ahead  int   // commits on A but not on B
+	Behind int   // commits on B but not on A
+	OnlyA  Stats // commits of B..A
+	OnlyB  Stats // commits of A..B
+}
+
 // File represents file statistics
 type File struct {
-	Path         string
-	Changes      int
-	Additions    int
-	Deletions    int
-	Authors      []string
-	LastModified time.Time
+	Path          string
+	PreviousPaths []string // paths the file was renamed from, most recent first
+	Changes       int
+	Additions     int
+	Deletions     int
+	Authors       []string
+	LastModified  time.Time
+	Submodule     string // path of the submodule the file belongs to, "" for the repository itself
+
+	// Binary files and files stored with Git LFS, as of their most recent
+	// change. Size is the file's size in bytes, or its LFS object's, and
+	// SizeDelta the bytes added by BinaryChanges and LFSChanges; both are
+	// only known for binary and LFS files.
+	Binary        bool
+	LFS           bool
+	Size          int64
+	SizeDelta     int64
+	BinaryChanges int
+	LFSChanges    int
+}
+
+// FileRevision is a commit that changed a file, as listed by FileHistory
+type FileRevision struct {
+	Hash      string
+	Author    string
+	Email     string
+	Date      time.Time
+	Message   string
+	Path      string // path of the file after the commit
+	OldPath   string // path before the commit when it renamed or copied the file
+	Additions int
+	Deletions int
+	Binary    bool
+}
+
+// Submodule describes a submodule registered in .gitmodules
+type Submodule struct {
+	Name        string
+	Path        string
+	URL         string
+	Branch      string    // branch to track, if configured
+	Commit      string    // commit pinned in the analyzed tree
+	Bumps       int       // commits that moved the pin after it was added
+	UpdatedAt   time.Time // when the pin last changed
+	Initialized bool      // checked out in the working tree
+}
+
+// Ownership describes who owns the lines surviving in a file or directory,
+// according to git blame
+type Ownership struct {
+	Path       string
+	Lines      int
+	Owners     []Owner     // most lines first
+	Age        []AgeBucket // youngest first
+	AverageAge time.Duration
+}
+
+// Owner represents an author's share of the surviving lines
+type Owner struct {
+	Name    string
+	Email   string
+	Lines   int
+	Percent float64
+}
+
+// AgeBucket counts surviving lines by time since they were last changed
+type AgeBucket struct {
+	Label   string        // e.g. "1-6 months"
+	MaxAge  time.Duration // zero for the open-ended oldest bucket
+	Lines   int
+	Percent float64
+}
+
+// OwnershipReport holds the ownership of the whole tree, of every
+// directory and of every file, sorted by path
+type OwnershipReport struct {
+	Total       Ownership
+	Directories []Ownership
+	Files       []Ownership
+}
+
+// BusFactor is the minimum number of authors whose departure would orphan
+// more than half of the files below Path
+type BusFactor struct {
+	Path       string
+	Factor     int
+	Files      int
+	KeyAuthors []string // emails of the authors removed, most critical first
+}
+
+// BusFactorReport holds the bus factor of the repository and of every
+// directory. AtRisk lists the paths that depend on a single author.
+type BusFactorReport struct {
+	Total       BusFactor
+	Directories []BusFactor
+	AtRisk      []string
 }
 
 // Changelog represents changelog entries
@@ -74,48 +248,63 @@ type Changelog struct {
 
 // ChangeEntry represents a single changelog entry
 type ChangeEntry struct {
-	Type    string // feat, fix, docs, refactor, etc.
-	Scope   string
-	Message string
-	Hash    string
+	Type     string // feat, fix, docs, refactor, etc.
+	Scope    string
+	Message  string
+	Hash     string
+	Breaking bool // marked with "!" or a BREAKING CHANGE footer
 }
 
 // Tree represents a branch tree visualization
 type Tree struct {
-	Root     string
-	Branches []TreeNode
+	Root     string     // name of the root branch
+	Branches []TreeNode // depth-first from the roots, children by name
 }
 
 // TreeNode represents a node in the branch tree
 type TreeNode struct {
-	Name     string
-	Parent   string
-	Children []string
-	Commits  int
+	Name      string
+	Hash      string
+	UpdatedAt time.Time
+	Parent    string   // branch this one forked from, empty for roots
+	Children  []string // branches forked from this one, by name
+	ForkPoint string   // merge base with Parent
+	Commits   int      // commits reachable from the tip
+	Ahead     int      // commits not on Parent
+	Behind    int      // commits on Parent since ForkPoint
 }
 
-// Calendar represents a commit calendar heatmap
+// Calendar represents a commit calendar heatmap covering whole months.
+// Only the commits made from From to To are counted.
 type Calendar struct {
-	Year   int
-	Months []MonthData
+	Year      int // year of From
+	From      time.Time
+	To        time.Time
+	Months    []MonthData
+	Total     int    // commits in the calendar
+	Max       int    // most commits on a single day
+	Quartiles [3]int // upper bounds of intensity levels 1 to 3, see Level
 }
 
 // MonthData represents commit data for a month
 type MonthData struct {
+	Year  int
 	Month int
-	Days  map[int]int // day of month -> commit count
+	Days  map[int]int // day of month -> commit count, days without commits left out
+	Weeks [][]int     // week -> day of week (Sunday first) -> commit count, -1 outside the month
 }
 
 // Heatmap represents commit activity heatmap
 type Heatmap struct {
-	Days  []HeatmapDay
-	Hours []HeatmapHour
+	Days  []HeatmapDay  // every day of the range, oldest first
+	Hours []HeatmapHour // commits per hour of the day over the range, 0 to 23
 }
 
 // HeatmapDay represents commits for a single day
 type HeatmapDay struct {
 	Date    time.Time
 	Commits int
+	Hours   [24]int // commits per hour of the day
 }
 
 // HeatmapHour represents commits for a specific hour
@@ -124,6 +313,21 @@ type HeatmapHour struct {
 	Commits int
 }
 
+// PunchcardFilter narrows the commits of a punchcard
+type PunchcardFilter struct {
+	Author string   // name, email or "Name <email>", as for CommitsCalendar
+	Paths  []string // pathspecs, on top of Options.PathSpec
+	Lines  bool     // also count changed lines, which needs every diff
+}
+
+// Punchcard counts commits by weekday and hour of the day, in
+// Options.Timezone
+type Punchcard struct {
+	Commits [7][24]int // weekday (Sunday first) -> hour -> commits
+	Lines   [7][24]int // lines added and deleted, when PunchcardFilter.Lines is set
+	Total   int
+}
+
 // Contributor represents a simplified contributor view
 type Contributor struct {
 	Name    string
@@ -0,0 +1,340 @@
+package git_nerds
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	git2 "github.com/inovacc/git-nerds/internal/git"
+)
+
+// Workspace analyzes several repositories as a whole, such as every
+// repository of a product or an organization
+type Workspace struct {
+	repos   []*Repository
+	names   []string
+	skipped []string
+}
+
+// OpenWorkspace opens every path as a repository with the same options.
+// Repositories are named after their directory, or their full path when
+// two directories share a name.
+func OpenWorkspace(paths []string, opts ...*Options) (*Workspace, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%w: empty workspace", ErrNotARepository)
+	}
+
+	w := &Workspace{}
+
+	for _, p := range paths {
+		repo, err := Open(p, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", p, err)
+		}
+		w.repos = append(w.repos, repo)
+	}
+
+	seen := make(map[string]int)
+	for _, repo := range w.repos {
+		seen[filepath.Base(repo.Path())]++
+	}
+
+	for _, repo := range w.repos {
+		name := filepath.Base(repo.Path())
+		if seen[name] > 1 {
+			name = filepath.ToSlash(repo.Path())
+		}
+		w.names = append(w.names, name)
+	}
+
+	return w, nil
+}
+
+// DiscoverWorkspace opens every repository found under dir, working trees
+// and bare repositories alike. Repositories nested in another one, such as
+// submodules, are not searched for. Repositories are named by their path
+// relative to dir. Directories that can't be read are skipped and listed
+// by Skipped.
+func DiscoverWorkspace(dir string, opts ...*Options) (*Workspace, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	found := &discovery{root: root}
+	if err := filepath.WalkDir(root, found.visit); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+
+	if len(found.paths) == 0 {
+		return nil, fmt.Errorf("%w: no repositories found under %s", ErrNotARepository, root)
+	}
+
+	w := &Workspace{skipped: found.skipped}
+
+	for _, p := range found.paths {
+		repo, err := Open(p, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", p, err)
+		}
+
+		name := filepath.Base(root)
+		if p != root {
+			name, _ = filepath.Rel(root, p)
+		}
+
+		w.repos = append(w.repos, repo)
+		w.names = append(w.names, filepath.ToSlash(name))
+	}
+
+	return w, nil
+}
+
+// discovery collects the repositories below root
+type discovery struct {
+	root    string
+	paths   []string
+	skipped []string // relative to root
+}
+
+// visit is the filepath.WalkDirFunc of DiscoverWorkspace
+func (d *discovery) visit(p string, entry fs.DirEntry, err error) error {
+	if err != nil {
+		// One unreadable directory doesn't hide the repositories of the
+		// others, only the root must be readable
+		if p == d.root || entry == nil || !entry.IsDir() {
+			return err
+		}
+
+		name, _ := filepath.Rel(d.root, p)
+		d.skipped = append(d.skipped, filepath.ToSlash(name))
+		return filepath.SkipDir
+	}
+	if !entry.IsDir() {
+		return nil
+	}
+
+	if _, err := os.Stat(filepath.Join(p, ".git")); err == nil || git2.IsGitDir(p) {
+		d.paths = append(d.paths, p)
+		return filepath.SkipDir
+	}
+
+	return nil
+}
+
+// Repositories returns the repositories of the workspace
+func (w *Workspace) Repositories() []*Repository {
+	return w.repos
+}
+
+// Skipped returns the directories DiscoverWorkspace couldn't read, relative
+// to the directory it searched, and so may hide repositories
+func (w *Workspace) Skipped() []string {
+	return w.skipped
+}
+
+// Names returns the names of the repositories, in the same order
+func (w *Workspace) Names() []string {
+	return w.names
+}
+
+// WithContext returns a copy of the workspace whose repositories are bound
+// to ctx, see Repository.WithContext
+func (w *Workspace) WithContext(ctx context.Context) *Workspace {
+	clone := &Workspace{
+		repos:   make([]*Repository, len(w.repos)),
+		names:   w.names,
+		skipped: w.skipped,
+	}
+
+	for i, repo := range w.repos {
+		clone.repos[i] = repo.WithContext(ctx)
+	}
+
+	return clone
+}
+
+// each runs fn on every repository concurrently and returns the error of
+// the first failing repository in workspace order
+func (w *Workspace) each(fn func(i int, repo *Repository) error) error {
+	errs := make([]error, len(w.repos))
+	limit := make(chan struct{}, runtime.GOMAXPROCS(0))
+
+	var wg sync.WaitGroup
+	for i, repo := range w.repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			errs[i] = fn(i, repo)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("%s: %w", w.names[i], err)
+		}
+	}
+
+	return nil
+}
+
+// DetailedStats collects the statistics of every repository concurrently
+// and merges them. In the total, authors are unified across repositories
+// by email, file paths are prefixed with the repository name and branches
+// are left out; they are kept in the per-repository breakdown.
+func (w *Workspace) DetailedStats() (*WorkspaceStats, error) {
+	stats := make([]*Stats, len(w.repos))
+	activities := make([]*activity, len(w.repos))
+
+	err := w.each(func(i int, repo *Repository) error {
+		logOpts := repo.toLogOptions()
+
+		branches, err := repo.branches(logOpts)
+		if err != nil {
+			return err
+		}
+
+		stats[i], activities[i], err = repo.collectStats(logOpts, branches)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WorkspaceStats{
+		Repositories: make([]RepositoryStats, len(w.repos)),
+	}
+
+	total := &result.Total
+	active := &activity{
+		days:    make(map[string]struct{}),
+		authors: make(map[string]map[string]struct{}),
+	}
+
+	contributors := make(map[string]*WorkspaceContributor)
+	authors := make(map[string]*Author)
+
+	for i, s := range stats {
+		result.Repositories[i] = RepositoryStats{
+			Name:  w.names[i],
+			Path:  w.repos[i].Path(),
+			Stats: *s,
+		}
+
+		total.TotalCommits += s.TotalCommits
+		total.TotalMerges += s.TotalMerges
+		total.LinesAdded += s.LinesAdded
+		total.LinesDeleted += s.LinesDeleted
+		total.LinesChanged += s.LinesChanged
+
+		if !s.FirstCommitAt.IsZero() && (total.FirstCommitAt.IsZero() || s.FirstCommitAt.Before(total.FirstCommitAt)) {
+			total.FirstCommitAt = s.FirstCommitAt
+		}
+		if s.LastCommitAt.After(total.LastCommitAt) {
+			total.LastCommitAt = s.LastCommitAt
+		}
+
+		for _, f := range s.Files {
+			f.Path = path.Join(w.names[i], f.Path)
+			total.Files = append(total.Files, f)
+		}
+
+		// Identities are keyed case-insensitively, each repository has
+		// already applied .mailmap and the aliases
+		repoDays := make(map[string]map[string]struct{})
+		for email, days := range activities[i].authors {
+			key := strings.ToLower(email)
+			if repoDays[key] == nil {
+				repoDays[key] = make(map[string]struct{})
+			}
+			for day := range days {
+				repoDays[key][day] = struct{}{}
+			}
+		}
+		active.add(&activity{days: activities[i].days, authors: repoDays})
+
+		for _, a := range s.Authors {
+			key := strings.ToLower(a.Email)
+
+			merged, ok := authors[key]
+			if !ok {
+				merged = &Author{Name: a.Name, Email: a.Email, FirstCommit: a.FirstCommit, LastCommit: a.LastCommit}
+				authors[key] = merged
+				contributors[key] = &WorkspaceContributor{}
+			}
+
+			merged.Commits += a.Commits
+			merged.LinesAdded += a.LinesAdded
+			merged.LinesDeleted += a.LinesDeleted
+			merged.LinesChanged += a.LinesChanged
+			merged.FilesChanged += a.FilesChanged
+
+			if a.FirstCommit.Before(merged.FirstCommit) {
+				merged.FirstCommit = a.FirstCommit
+			}
+			if a.LastCommit.After(merged.LastCommit) {
+				// The most recent name wins
+				merged.Name = a.Name
+				merged.Email = a.Email
+				merged.LastCommit = a.LastCommit
+			}
+
+			c := contributors[key]
+			if n := len(c.Repositories); n == 0 || c.Repositories[n-1] != w.names[i] {
+				c.Repositories = append(c.Repositories, w.names[i])
+			}
+		}
+	}
+
+	for key, a := range authors {
+		a.ActiveDays = len(active.authors[key])
+		total.Authors = append(total.Authors, *a)
+
+		c := contributors[key]
+		c.Name = a.Name
+		c.Email = a.Email
+		c.Commits = a.Commits
+		c.LinesAdded = a.LinesAdded
+		c.LinesDeleted = a.LinesDeleted
+		c.FirstCommit = a.FirstCommit
+		c.LastCommit = a.LastCommit
+		result.Contributors = append(result.Contributors, *c)
+	}
+
+	sort.Slice(total.Authors, func(i, j int) bool {
+		if total.Authors[i].Commits != total.Authors[j].Commits {
+			return total.Authors[i].Commits > total.Authors[j].Commits
+		}
+		return total.Authors[i].Email < total.Authors[j].Email
+	})
+
+	sort.Slice(result.Contributors, func(i, j int) bool {
+		if result.Contributors[i].Commits != result.Contributors[j].Commits {
+			return result.Contributors[i].Commits > result.Contributors[j].Commits
+		}
+		return result.Contributors[i].Email < result.Contributors[j].Email
+	})
+
+	sort.SliceStable(total.Files, func(i, j int) bool {
+		return total.Files[i].Changes > total.Files[j].Changes
+	})
+
+	total.TotalAuthors = len(total.Authors)
+	total.TotalFiles = len(total.Files)
+	total.ActiveDays = len(active.days)
+	countAssets(total)
+
+	return result, nil
+}